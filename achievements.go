@@ -0,0 +1,231 @@
+package main
+
+// Achievements: milestones unlocked once and kept for good, with the time
+// each was first reached, in a small JSON file next to the history:
+//
+//   $XDG_DATA_HOME/cli_typer/achievements.json
+//
+// Every finished test or game is checked against the ones still locked,
+// once it's in the history, so lifetime counts and streaks include it. A
+// new unlock is announced in place of the hint line on the results or game
+// over screen, and the stats screen's achievements page ("a") lists them
+// all, locked ones dimmed.
+//
+// Failed tests and runs on repeated text don't unlock anything. Results
+// from before achievements existed count toward the lifetime ones, which
+// unlock on the next finished test.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	achievementFastWPM    = 60
+	achievementFasterWPM  = 100
+	achievementFlawless   = 30 // seconds a 100% accurate test has to last
+	achievementDefender   = 50 // words destroyed in one falling game
+	achievementSurvivor   = 5 * time.Minute
+	achievementStreakDays = 7
+	achievementTests      = 100
+)
+
+// achievement is a milestone. id is how the file knows it, so it never
+// changes; earned reports whether the result e (the last in m.history)
+// reaches it.
+type achievement struct {
+	id, name, desc string
+	earned         func(m model, e historyEntry) bool
+}
+
+var achievements = []achievement{
+	{"first_test", "first steps", "finish a test or falling game", func(m model, e historyEntry) bool {
+		return true
+	}},
+	{"wpm_60", "quick fingers", fmt.Sprintf("reach %d wpm in a test", achievementFastWPM), func(m model, e historyEntry) bool {
+		return e.Mode == "classic" && e.WPM >= achievementFastWPM
+	}},
+	{"wpm_100", "triple digits", fmt.Sprintf("reach %d wpm in a test", achievementFasterWPM), func(m model, e historyEntry) bool {
+		return e.Mode == "classic" && e.WPM >= achievementFasterWPM
+	}},
+	{"flawless", "flawless", fmt.Sprintf("100%% accuracy, %ds or longer", achievementFlawless), func(m model, e historyEntry) bool {
+		return e.Mode == "classic" && e.Accuracy == 100 && e.Duration >= achievementFlawless
+	}},
+	{"falling_50", "defender", fmt.Sprintf("destroy %d words in one game", achievementDefender), func(m model, e historyEntry) bool {
+		return e.Mode == "falling" && m.fallingDestroyed >= achievementDefender
+	}},
+	{"survivor", "survivor", fmt.Sprintf("survive %d minutes falling", int(achievementSurvivor.Minutes())), func(m model, e historyEntry) bool {
+		return e.Mode == "falling" && e.Duration >= int(achievementSurvivor.Seconds())
+	}},
+	{"daily_7", "regular", fmt.Sprintf("daily challenge %d days running", achievementStreakDays), func(m model, e historyEntry) bool {
+		return e.Daily != "" && dailyStreak(m.history, e.Daily) >= achievementStreakDays
+	}},
+	{"lessons", "graduate", "pass every lesson", func(m model, e historyEntry) bool {
+		for _, l := range lessons {
+			if !m.lessonProgress[l.name] {
+				return false
+			}
+		}
+		return true
+	}},
+	{"tests_100", "centurion", fmt.Sprintf("finish %d classic tests", achievementTests), func(m model, e historyEntry) bool {
+		tests := 0
+		for _, h := range m.history {
+			if h.Mode == "classic" && !h.Failed {
+				tests++
+			}
+		}
+		return tests >= achievementTests
+	}},
+}
+
+// dailyStreak counts the days in a row, back from date, with a daily
+// challenge result in history.
+func dailyStreak(history []historyEntry, date string) int {
+	played := map[string]bool{}
+	for _, e := range history {
+		if e.Daily != "" {
+			played[e.Daily] = true
+		}
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0
+	}
+	streak := 0
+	for played[day.Format("2006-01-02")] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// achievementUnlocks maps an achievement's id to when it was unlocked.
+type achievementUnlocks map[string]time.Time
+
+func achievementsPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "achievements.json")
+}
+
+func loadAchievements() achievementUnlocks {
+	unlocks := achievementUnlocks{}
+	path := achievementsPath()
+	if path == "" {
+		return unlocks
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return unlocks
+	}
+	if err := json.Unmarshal(data, &unlocks); err != nil || unlocks == nil {
+		return achievementUnlocks{}
+	}
+	return unlocks
+}
+
+// saveAchievementsCmd writes the achievements file in the background.
+func saveAchievementsCmd(unlocks achievementUnlocks) tea.Cmd {
+	// Copy so later updates to the model's map can't race the write
+	snapshot := make(achievementUnlocks, len(unlocks))
+	for k, v := range unlocks {
+		snapshot[k] = v
+	}
+	return func() tea.Msg {
+		path := achievementsPath()
+		if path == "" {
+			return nil
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil
+		}
+		_ = os.WriteFile(path, data, 0o644)
+		return nil
+	}
+}
+
+// checkAchievements unlocks whatever the just-recorded result e earns,
+// announcing it on the hint line and returning the command that saves it.
+func checkAchievements(m model, e historyEntry) (model, tea.Cmd) {
+	if e.Failed || e.Repeat > 0 {
+		return m, nil
+	}
+	var names []string
+	for _, a := range achievements {
+		if _, ok := m.achievements[a.id]; ok || !a.earned(m, e) {
+			continue
+		}
+		if m.achievements == nil {
+			m.achievements = achievementUnlocks{}
+		}
+		m.achievements[a.id] = e.Time
+		names = append(names, a.name)
+	}
+	if len(names) == 0 {
+		return m, nil
+	}
+	text := "achievement unlocked: " + names[0]
+	if len(names) > 1 {
+		text = "achievements unlocked: " + strings.Join(names, ", ")
+	}
+	m, flashCmd := setFlash(m, glyphUnlocked+" "+text, false)
+	return m, tea.Batch(saveAchievementsCmd(m.achievements), flashCmd)
+}
+
+// viewAchievements is the stats screen's achievements page.
+func viewAchievements(m model) string {
+	title := styleTitle.Render("achievements") +
+		styleHint.Render(fmt.Sprintf("  %d/%d", unlockedAchievements(m), len(achievements)))
+
+	parts := []string{title}
+	if !m.inline {
+		parts = append(parts, "")
+	}
+	for _, a := range achievements {
+		name := fmt.Sprintf("%-14s", a.name)
+		when, ok := m.achievements[a.id]
+		if !ok {
+			parts = append(parts, styleUntyped.Render(glyphLocked+" "+name+a.desc))
+			continue
+		}
+		line := styleHighlight.Render(glyphUnlocked+" ") + styleStatValue.Render(name) + styleCorrect.Render(fmt.Sprintf("%-30s", a.desc))
+		if !m.inline {
+			line += styleHint.Render("  " + when.Local().Format("Jan 02 2006"))
+		}
+		parts = append(parts, line)
+	}
+
+	hint := styleHint.Render("a stats  " + m.keys[actionBack] + " menu")
+	if m.inline {
+		parts = append(parts, hint)
+	} else {
+		parts = append(parts, "", hint)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// unlockedAchievements counts the achievements unlocked so far, ignoring
+// ids in the file that no longer name one.
+func unlockedAchievements(m model) int {
+	unlocked := 0
+	for _, a := range achievements {
+		if _, ok := m.achievements[a.id]; ok {
+			unlocked++
+		}
+	}
+	return unlocked
+}
@@ -0,0 +1,92 @@
+package main
+
+// Armored aliens turn up now and then once aliens fall at armorMinSpeed
+// or faster. One wears a double outline and has to be typed twice: the
+// first pass knocks the armor off, with a small explosion and half its
+// points, and keeps it targeted so the second pass can start at once. It
+// counts as one word destroyed, but the characters of both passes count
+// toward WPM.
+//
+//      _=_
+//     [o o]
+//   [|word|]
+//     /|=|\
+//     /   \
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	armorChance   = 8   // 1 in N spawns is armored, once it can be
+	armorMinSpeed = 4.0 // rows per second
+)
+
+// buildArmoredArt builds an armored alien. The armor adds a column on each
+// side of the word, so the word starts a column further in than usual.
+func buildArmoredArt(word string) builtAlien {
+	bodyRow := " [|" + word + "|] "
+	totalWidth := lipgloss.Width(bodyRow)
+	center := func(s string) string {
+		pad := max(totalWidth-lipgloss.Width(s), 0)
+		return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+	}
+	return builtAlien{
+		lines: []string{
+			center("_=_"),
+			center("[o o]"),
+			bodyRow,
+			center(`/|=|\`),
+			center(`/   \`),
+		},
+		wordRow:   2,
+		wordCol:   3, // " [|"
+		wordLen:   utf8.RuneCountInString(word),
+		wordWidth: lipgloss.Width(word),
+		width:     totalWidth,
+	}
+}
+
+// spawnsArmored decides whether a new alien carrying word is armored.
+func spawnsArmored(m model, word string) bool {
+	return m.fallingSpeed >= armorMinSpeed &&
+		m.rng.Intn(armorChance) == 0 &&
+		buildArmoredArt(word).width <= m.width-2*edgePadding
+}
+
+// stripArmor handles the first completion of an armored target: the armor
+// comes off, the input clears, and the target stays locked.
+func stripArmor(m model) (model, tea.Cmd) {
+	fw := &m.fallingWords[m.fallingTarget]
+	fw.armored = false
+	fw.sprite = buildAlienArt(fw.word, powerNone)
+	fw.typed = 0
+	// The plain alien is two columns narrower; keep it centered
+	fw.x++
+	fw.fx = float64(fw.x)
+
+	points := destroyPoints(m, *fw) / 2
+	m.fallingScore += points
+	m.fallingCharsTyped += utf8.RuneCountInString(fw.word)
+	m.scorePopups = append(m.scorePopups, scorePopup{
+		x:     wordCenter(*fw),
+		y:     int(fw.y) - 3, // above the alien, which is still there
+		text:  fmt.Sprintf("+%d", points),
+		ticks: popupDuration,
+	})
+	m = addExplosion(m, explosion{
+		x:     wordCenter(*fw),
+		y:     int(fw.y),
+		ticks: explodeDuration,
+		small: true,
+	})
+
+	m.fallingInput = nil
+	m.turretStartX = m.turretX
+	return m, playSoundPitched(soundDestroy, 1.5)
+}
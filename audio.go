@@ -6,18 +6,23 @@ package main
 // compile time. This means the sounds ship inside the executable — no external
 // files needed at runtime.
 //
-// beep/speaker is initialized once at startup. To play a sound, we decode
-// the embedded OGG data into a buffer (in-memory), then play a copy of it
-// each time. Buffering avoids re-decoding on every play.
+// beep/speaker is initialized once, in the background at startup. To play a
+// sound, we decode the embedded OGG data into a buffer (in-memory), then
+// play a copy of it each time. Buffering avoids re-decoding on every play.
+// Until decoding finishes, sound commands are simply nil.
+//
+// Every sound goes through volumed, which scales it by the volume picked on
+// the settings screen (or skips it entirely at 0%).
 
 import (
 	"bytes"
 	"embed"
-	"fmt"
 	"math/rand"
+	"sync/atomic"
 	"time"
 
 	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/effects"
 	"github.com/gopxl/beep/speaker"
 	"github.com/gopxl/beep/vorbis"
 
@@ -27,18 +32,79 @@ import (
 //go:embed sounds/destroy1.ogg sounds/destroy2.ogg sounds/destroy3.ogg sounds/destroy4.ogg sounds/hit.ogg sounds/gameover.ogg sounds/click.ogg
 var soundFiles embed.FS
 
-// Pre-decoded sound buffers.
-var (
-	soundDestroy  [4]*beep.Buffer // 4 variations, picked randomly
-	soundHit      *beep.Buffer
-	soundGameOver *beep.Buffer
-	soundClick    *beep.Buffer
-	audioReady    bool
+// sound identifies one of the embedded sounds.
+type sound int
+
+const (
+	soundDestroy sound = iota // 4 variations (soundDestroy+0..3), picked randomly
+	_
+	_
+	_
+	soundHit
+	soundGameOver
+	soundClick
+	numSounds
 )
 
+// soundNames is indexed by sound. The embedded files are sounds/<name>.ogg;
+// a sound pack can replace any of them (see soundpack.go).
+var soundNames = [numSounds]string{
+	"destroy1", "destroy2", "destroy3", "destroy4",
+	"hit", "gameover", "click",
+}
+
+// soundBank holds everything decoded by initAudio. It's built off the UI
+// goroutine and published in one atomic store, so the UI either sees a
+// complete bank or none at all.
+type soundBank struct {
+	format  beep.Format
+	buffers [numSounds]*beep.Buffer // nil for a sound that failed to decode
+	music   *beep.Buffer
+}
+
+var audio atomic.Pointer[soundBank]
+
+// audioReady reports whether initAudio has finished and sounds can play.
+func audioReady() bool {
+	return audio.Load() != nil
+}
+
+// soundBuffer returns the buffer for a sound, or nil if audio isn't ready
+// or the sound couldn't be decoded.
+func soundBuffer(id sound) *beep.Buffer {
+	bank := audio.Load()
+	if bank == nil {
+		return nil
+	}
+	return bank.buffers[id]
+}
+
+// volumeLevels are the settings screen's volume choices, in percent.
+var volumeLevels = []int{0, 25, 50, 75, 100}
+
+// soundVolume is the playback volume in percent. Only the UI goroutine
+// writes it; sound commands read it when they're created.
+var soundVolume = 100
+
+// volumed scales a streamer to the current volume. It returns nil when the
+// sound is muted.
+func volumed(s beep.Streamer) beep.Streamer {
+	if soundVolume <= 0 {
+		return nil
+	}
+	if soundVolume >= 100 {
+		return s
+	}
+	return &effects.Gain{Streamer: s, Gain: float64(soundVolume)/100 - 1}
+}
+
+// initAudio decodes the sounds and starts the speaker. It's slow (seven
+// OGG files), so main runs it in a goroutine: the menu appears at once and
+// sounds start working when it's done. Failure is non-fatal — the game
+// just stays silent.
 func initAudio() {
 	// Decode the first destroy sound to get the sample rate for speaker init
-	firstData, err := soundFiles.ReadFile("sounds/destroy1.ogg")
+	firstData, err := soundFiles.ReadFile("sounds/" + soundNames[0] + ".ogg")
 	if err != nil {
 		return
 	}
@@ -53,72 +119,107 @@ func initAudio() {
 		return
 	}
 
-	// Buffer the first destroy sound
-	soundDestroy[0] = beep.NewBuffer(format)
-	soundDestroy[0].Append(streamer)
-
-	// Buffer the remaining 3 destroy sounds
-	for i := 1; i < 4; i++ {
-		data, err := soundFiles.ReadFile(fmt.Sprintf("sounds/destroy%d.ogg", i+1))
-		if err == nil {
-			if s, _, err := vorbis.Decode(nopCloser(data)); err == nil {
-				soundDestroy[i] = beep.NewBuffer(format)
-				soundDestroy[i].Append(s)
-			}
+	bank := &soundBank{format: format}
+	for id := sound(0); id < numSounds; id++ {
+		// A sound pack file wins over the embedded sound
+		if buf := loadPackSound(soundNames[id], format); buf != nil {
+			bank.buffers[id] = buf
+			continue
 		}
-	}
-
-	// Buffer the hit sound
-	hitData, err := soundFiles.ReadFile("sounds/hit.ogg")
-	if err == nil {
-		if s, _, err := vorbis.Decode(nopCloser(hitData)); err == nil {
-			soundHit = beep.NewBuffer(format)
-			soundHit.Append(s)
+		if id == 0 {
+			bank.buffers[0] = beep.NewBuffer(format)
+			bank.buffers[0].Append(streamer)
+			continue
 		}
-	}
-
-	// Buffer the game over sound
-	goData, err := soundFiles.ReadFile("sounds/gameover.ogg")
-	if err == nil {
-		if s, _, err := vorbis.Decode(nopCloser(goData)); err == nil {
-			soundGameOver = beep.NewBuffer(format)
-			soundGameOver.Append(s)
+		data, err := soundFiles.ReadFile("sounds/" + soundNames[id] + ".ogg")
+		if err != nil {
+			continue
+		}
+		if s, _, err := vorbis.Decode(nopCloser(data)); err == nil {
+			bank.buffers[id] = beep.NewBuffer(format)
+			bank.buffers[id].Append(s)
 		}
 	}
 
-	// Buffer the click sound
-	clickData, err := soundFiles.ReadFile("sounds/click.ogg")
-	if err == nil {
-		if s, _, err := vorbis.Decode(nopCloser(clickData)); err == nil {
-			soundClick = beep.NewBuffer(format)
-			soundClick.Append(s)
-		}
+	// The sound pack's music replaces the synthesized pad
+	if bank.music = loadPackSound(musicName, format); bank.music == nil {
+		bank.music = buildMusicLoop(format)
 	}
 
-	audioReady = true
+	audio.Store(bank)
 }
 
 // playSound returns a tea.Cmd that plays a buffered sound.
-func playSound(buf *beep.Buffer) tea.Cmd {
-	if !audioReady || buf == nil {
+func playSound(id sound) tea.Cmd {
+	buf := soundBuffer(id)
+	if buf == nil {
+		return nil
+	}
+	s := volumed(buf.Streamer(0, buf.Len()))
+	if s == nil {
 		return nil
 	}
 	return func() tea.Msg {
-		speaker.Play(buf.Streamer(0, buf.Len()))
+		duckMusic()
+		speaker.Play(s)
 		return nil
 	}
 }
 
-// playRandomDestroy returns a tea.Cmd that plays one of the 4 destroy sounds at random.
-func playRandomDestroy() tea.Cmd {
-	if !audioReady {
+// playSoundPitched plays a buffered sound sped up (ratio > 1) or slowed
+// down, which raises or lowers its pitch.
+func playSoundPitched(id sound, ratio float64) tea.Cmd {
+	buf := soundBuffer(id)
+	if buf == nil {
 		return nil
 	}
-	buf := soundDestroy[rand.Intn(4)]
+	s := volumed(beep.ResampleRatio(4, ratio, buf.Streamer(0, buf.Len())))
+	if s == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		duckMusic()
+		speaker.Play(s)
+		return nil
+	}
+}
+
+// maxKeySounds caps how many keypress sounds play at once. Fast typing can
+// start one every few milliseconds; past a handful of overlapping clicks
+// the extras aren't audible, and dropping them keeps the speaker's mixer
+// from stuttering.
+const maxKeySounds = 4
+
+// activeKeySounds counts keypress sounds still playing. It's decremented
+// from the speaker's goroutine when each one finishes.
+var activeKeySounds atomic.Int32
+
+// playKeySound plays a keypress sound at the given pitch ratio, unless
+// maxKeySounds are already playing.
+func playKeySound(id sound, ratio float64) tea.Cmd {
+	buf := soundBuffer(id)
 	if buf == nil {
 		return nil
 	}
-	return playSound(buf)
+	s := volumed(beep.ResampleRatio(4, ratio, buf.Streamer(0, buf.Len())))
+	if s == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if activeKeySounds.Add(1) > maxKeySounds {
+			activeKeySounds.Add(-1)
+			return nil
+		}
+		speaker.Play(beep.Seq(s, beep.Callback(func() {
+			activeKeySounds.Add(-1)
+		})))
+		return nil
+	}
+}
+
+// playRandomDestroy returns a tea.Cmd that plays one of the 4 destroy sounds at random.
+func playRandomDestroy() tea.Cmd {
+	return playSound(soundDestroy + sound(rand.Intn(4)))
 }
 
 type readCloser struct {
@@ -0,0 +1,185 @@
+package main
+
+// The bot opponent. With the menu's bot row set, a classic test is a race
+// against a bot typing the same words, either at a fixed speed or at your
+// average over your last botAverageTests results ("avg", or
+// botFallbackWPM until there are any).
+//
+// Unlike the pace caret, which glides along a character at a time, the
+// bot moves a word at a time: each word takes as long as its characters
+// and the space after it would at the bot's speed, give or take up to
+// botJitter, so it speeds up and slows down like a person. The timings
+// come from the test's seed, so a shared seed races the same bot. Its
+// caret sits on the first letter of the word it's typing, and a progress
+// bar under the status line counts its words.
+//
+// The bot starts with the clock and never makes mistakes. The results
+// screen declares a winner the same way a LAN race does (see race.go):
+// finishing the words first, or the higher WPM when time runs out. Zen
+// tests have no end to race to, and LAN races have an opponent already,
+// so neither gets a bot.
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	botMatchAverage = -1 // the bot row's "avg"
+	botAverageTests = 20
+	botFallbackWPM  = 40
+	botJitter       = 0.2
+	botInterval     = 100 * time.Millisecond
+)
+
+// botLevels are the menu's bot choices in WPM; 0 is off.
+var botLevels = []int{0, 40, 60, 80, 100, 120, botMatchAverage}
+
+type botTickMsg struct {
+	id int
+}
+
+// averageWPM is the average over the last botAverageTests classic
+// results, leaving out failed tests and repeats. ok is false with none.
+func averageWPM(history []historyEntry) (wpm float64, ok bool) {
+	n := 0
+	for i := len(history) - 1; i >= 0 && n < botAverageTests; i-- {
+		e := history[i]
+		if e.Mode != "classic" || e.Failed || e.Repeat > 0 {
+			continue
+		}
+		wpm += e.WPM
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return wpm / float64(n), true
+}
+
+// botSpeed is the WPM the bot types at, 0 for no bot.
+func botSpeed(m model) float64 {
+	if m.bot == botMatchAverage {
+		if wpm, ok := averageWPM(m.history); ok {
+			return wpm
+		}
+		return botFallbackWPM
+	}
+	return float64(m.bot)
+}
+
+// loadBot schedules the bot through a fresh test's words, if there's to
+// be one.
+func loadBot(m model) model {
+	m.botWPM = 0
+	m.botFinish = nil
+	m.botTickID++
+	if zenTest(m) || m.race != nil {
+		return m
+	}
+	wpm := botSpeed(m)
+	if wpm <= 0 {
+		return m
+	}
+	rng := rand.New(rand.NewSource(m.seed))
+	perChar := float64(time.Minute) / (wpm * 5)
+	var at time.Duration
+	m.botFinish = make([]time.Duration, len(m.words))
+	for i, w := range m.words {
+		chars := len([]rune(w))
+		if i < len(m.words)-1 {
+			chars++ // the space
+		}
+		jitter := 1 + botJitter*(2*rng.Float64()-1)
+		at += time.Duration(perChar * float64(chars) * jitter)
+		m.botFinish[i] = at
+	}
+	m.botWPM = wpm
+	return m
+}
+
+func botActive(m model) bool {
+	return m.botFinish != nil
+}
+
+func botTickCmd(m model) tea.Cmd {
+	if !botActive(m) {
+		return nil
+	}
+	id := m.botTickID
+	return tea.Tick(botInterval, func(time.Time) tea.Msg {
+		return botTickMsg{id: id}
+	})
+}
+
+// botWordsDone is how many words the bot has typed after elapsed.
+func botWordsDone(m model, elapsed time.Duration) int {
+	return sort.Search(len(m.botFinish), func(i int) bool { return m.botFinish[i] > elapsed })
+}
+
+// botCaretAt reports whether the bot's caret is on the given character of
+// the given word: the first letter of the word it's typing. The player's
+// own caret takes precedence.
+func botCaretAt(m model, wordIdx, charIdx int) bool {
+	if !botActive(m) || m.state != stateTyping || !m.timerStarted || charIdx != 0 {
+		return false
+	}
+	if wordIdx == m.wordIndex && charIdx == len(m.input[wordIdx]) {
+		return false
+	}
+	return wordIdx == botWordsDone(m, typingElapsed(m))
+}
+
+// botResult is how the bot's test went when the player's ended after
+// elapsed seconds.
+func botResult(m model, elapsed float64) raceResult {
+	done := botWordsDone(m, time.Duration(elapsed*float64(time.Second)))
+	r := raceResult{accuracy: 100, finished: done == len(m.words), elapsed: elapsed}
+	if r.finished {
+		r.elapsed = m.botFinish[done-1].Seconds()
+	}
+	chars := 0
+	for i := 0; i < done; i++ {
+		chars += len([]rune(m.words[i])) + 1
+	}
+	if r.finished {
+		chars-- // no space after the last word
+	}
+	if r.elapsed > 0 {
+		r.wpm = float64(chars) / 5 / (r.elapsed / 60)
+	}
+	return r
+}
+
+// renderBotBar is the bot's progress through the words, under the typing
+// test's status line.
+func renderBotBar(m model) string {
+	done := botWordsDone(m, 0)
+	if m.timerStarted {
+		done = botWordsDone(m, typingElapsed(m))
+	}
+	return renderOpponentBar("bot      ", styleShield, done, len(m.words), fmt.Sprintf("%.0f wpm", m.botWPM))
+}
+
+// renderBotResult is the results screen's note on the race with the bot.
+func renderBotResult(m model) string {
+	var verdict string
+	you := playerRaceResult(m)
+	switch raceWinner(you, m.botRace) {
+	case 1:
+		verdict = styleHighlight.Bold(true).Render("you beat the bot " + raceMargin(you, m.botRace))
+	case -1:
+		verdict = styleIncorrect.Bold(true).Render("the bot won " + raceMargin(m.botRace, you))
+	default:
+		verdict = styleHighlight.Bold(true).Render("a tie with the bot")
+	}
+	bot := fmt.Sprintf("bot %.0f wpm", m.botRace.wpm)
+	if m.botRace.finished {
+		bot += fmt.Sprintf(" in %.1fs", m.botRace.elapsed)
+	}
+	return verdict + styleHint.Render(" "+glyphDash+" "+bot)
+}
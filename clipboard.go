@@ -0,0 +1,60 @@
+package main
+
+// Copying a result summary with "c" on the results screen, like
+// "cli_typer: 92 wpm, 97.4% acc, 30s words", ready to paste into a chat.
+//
+// The copy uses OSC 52, an escape sequence asking the terminal itself to
+// set the clipboard, so it needs no external binaries and works over SSH.
+// Terminals that don't support it ignore the sequence, and nothing happens.
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resultSummary is the one-line summary of a result.
+func resultSummary(e historyEntry) string {
+	if e.Mode == "falling" {
+		game := "falling"
+		if e.Invaders {
+			game = "invaders"
+		}
+		return fmt.Sprintf("cli_typer: %d points, %.0f wpm, %s %s", e.Score, e.WPM, game, e.Content)
+	}
+	var setup string
+	switch {
+	case e.Daily != "":
+		setup = "daily " + e.Daily
+	case e.Lesson != "":
+		setup = e.Lesson + " lesson"
+	case e.Practice:
+		setup = "weak words practice"
+	case e.Length != "":
+		setup = e.Length + " quote"
+	case e.WordCount > 0:
+		setup = fmt.Sprintf("%d-word %s", e.WordCount, e.Content)
+	case e.Zen:
+		setup = "zen " + e.Content
+	default:
+		setup = fmt.Sprintf("%ds %s", e.Duration, e.Content)
+	}
+	s := fmt.Sprintf("cli_typer: %.0f wpm, %.1f%% acc, %s", e.WPM, e.Accuracy, setup)
+	if e.Failed {
+		s += ", failed"
+	}
+	return s
+}
+
+// copyCmd sets the terminal's clipboard to text with OSC 52. It writes to
+// the program's output, stdout, in a single write, so the sequence can't
+// end up split by a frame the renderer is drawing at the same time.
+func copyCmd(text string) tea.Cmd {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	return func() tea.Msg {
+		_, _ = os.Stdout.WriteString(seq)
+		return nil
+	}
+}
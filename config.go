@@ -0,0 +1,310 @@
+package main
+
+// Menu and settings choices are remembered between runs in a small JSON
+// file:
+//
+//   $XDG_CONFIG_HOME/cli_typer/config.json   (default ~/.config/cli_typer/)
+//
+// Loaded in initialModel and rewritten (via a tea.Cmd) whenever a choice
+// changes. A missing or malformed file means defaults; a single unknown
+// value keeps the default for just that option, and a rejected keybinding
+// keeps the default key (see keymap.go).
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// config holds choices by name rather than enum value, so the file stays
+// readable and survives reordering of the options.
+type config struct {
+	Game         string `json:"game"`
+	Content      string `json:"content"`
+	Test         string `json:"test"`
+	Duration     int    `json:"duration"` // seconds
+	WordCount    int    `json:"word_count"`
+	QuoteLength  string `json:"quote_length"`
+	WordSet      string `json:"word_set"`
+	OwnQuotes    bool   `json:"own_quotes"`
+	Punctuation  bool   `json:"punctuation"`
+	Freedom      bool   `json:"freedom"`
+	Level        string `json:"level"`
+	Caret        string `json:"caret"`
+	Cycle        bool   `json:"cycle"`
+	Waves        bool   `json:"waves"`
+	TimeLimit    int    `json:"time_limit"` // seconds; 0 for no time limit
+	Lives        int    `json:"lives"`
+	Drift        bool   `json:"drift"`
+	Strict       bool   `json:"strict"`
+	Ghost        bool   `json:"ghost"`
+	Blind        bool   `json:"blind"`
+	Sprints      bool   `json:"sprints"`
+	Bot          int    `json:"bot"`
+	Volume       *int   `json:"volume,omitempty"` // percent; nil means default (0 is mute)
+	KeySounds    bool   `json:"key_sounds"`
+	Music        bool   `json:"music"`
+	FetchQuotes  bool   `json:"fetch_quotes"`
+	Pace         int    `json:"pace"` // pace caret WPM, 0 for off
+	MinWPM       int    `json:"min_wpm"`
+	MinAccuracy  int    `json:"min_accuracy"`
+	IgnorePaste  bool   `json:"ignore_paste"`
+	Countdown    bool   `json:"countdown"`
+	Keyboard     bool   `json:"keyboard"`
+	Layout       string `json:"layout"`
+	ErrorStyle   string `json:"error_style"`
+	Variety      string `json:"variety"`
+	ReduceMotion bool   `json:"reduce_motion"`
+	PauseOnBlur  bool   `json:"pause_on_blur"`
+	Theme        string `json:"theme"`
+
+	// Keybindings by action name (see keymap.go); actions left out keep
+	// their default keys
+	Keys map[string]string `json:"keys,omitempty"`
+
+	// Where to POST every result (see webhook.go); set by hand
+	ResultsWebhookURL string `json:"results_webhook_url,omitempty"`
+}
+
+var gameModeNames = []string{"classic", "falling", "invaders"}
+
+var testModeNames = []string{"time", "words", "zen"}
+
+// configDir returns the directory cli_typer keeps its config in.
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "cli_typer")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "cli_typer")
+}
+
+func configPath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "config.json")
+}
+
+func loadConfig() config {
+	path := configPath()
+	if path == "" {
+		return config{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config{}
+	}
+	var c config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return config{}
+	}
+	return c
+}
+
+// currentConfig captures the model's menu and settings choices.
+func currentConfig(m model) config {
+	volume := m.volume
+	return config{
+		Game:         gameModeNames[m.gameMode],
+		Content:      contentModeNames[m.contentMode],
+		Test:         testModeNames[m.testMode],
+		Duration:     int(m.duration.Seconds()),
+		WordCount:    m.wordCount,
+		QuoteLength:  quoteLengthNames[m.quoteLength],
+		WordSet:      wordSetNames[m.wordSet],
+		OwnQuotes:    m.ownQuotes,
+		Punctuation:  m.punctuation,
+		Freedom:      m.freedom,
+		Level:        difficultyNames[m.difficulty],
+		Caret:        caretNames[m.caret],
+		Cycle:        m.dayCycle,
+		Waves:        m.waves,
+		TimeLimit:    m.timeLimit,
+		Lives:        m.lives,
+		Drift:        m.drift,
+		Strict:       m.strict,
+		Ghost:        m.ghost,
+		Bot:          m.bot,
+		Blind:        m.blind,
+		Sprints:      m.sprints,
+		Volume:       &volume,
+		KeySounds:    m.keySounds,
+		Music:        m.music,
+		FetchQuotes:  m.fetchQuotes,
+		Pace:         m.pace,
+		MinWPM:       m.minWPM,
+		MinAccuracy:  m.minAccuracy,
+		IgnorePaste:  m.ignorePaste,
+		Countdown:    m.countdown,
+		Keyboard:     m.keyboard,
+		Layout:       keyboardLayouts[m.layout].name,
+		ErrorStyle:   errorMarkingNames[m.errorMarking],
+		Variety:      wordVarietyNames[m.wordVariety],
+		ReduceMotion: m.reduceMotion,
+		PauseOnBlur:  m.pauseOnBlur,
+		Theme:        themes[m.theme].name,
+		Keys:         m.keyConfig,
+
+		ResultsWebhookURL: m.webhookURL,
+	}
+}
+
+// applyConfig sets the model's choices from a loaded config, skipping any
+// value that isn't one of the options. It also applies the theme, error
+// marking, and volume, which live outside the model. Custom themes must
+// already be loaded for a custom theme name to be found.
+func applyConfig(m model, c config) model {
+	if i := nameIndex(gameModeNames, c.Game); i >= 0 {
+		m.gameMode = gameMode(i)
+	}
+	for cm, name := range contentModeNames {
+		// custom and text only make sense once their words have been loaded
+		if name == c.Content && cm != modeCustom && cm != modeText {
+			m.contentMode = cm
+		}
+	}
+	if i := nameIndex(testModeNames, c.Test); i >= 0 {
+		m.testMode = testMode(i)
+	}
+	for _, d := range durations {
+		if int(d.Seconds()) == c.Duration {
+			m.duration = d
+		}
+	}
+	for _, n := range wordCounts {
+		if n == c.WordCount {
+			m.wordCount = n
+		}
+	}
+	if i := nameIndex(quoteLengthNames, c.QuoteLength); i >= 0 {
+		m.quoteLength = quoteLength(i)
+	}
+	if i := nameIndex(wordSetNames, c.WordSet); i >= 0 {
+		m.wordSet = i
+	}
+	if i := nameIndex(difficultyNames, c.Level); i >= 0 {
+		m.difficulty = difficulty(i)
+	}
+	if i := nameIndex(caretNames, c.Caret); i >= 0 {
+		m.caret = caretStyle(i)
+	}
+	if i := nameIndex(errorMarkingNames, c.ErrorStyle); i >= 0 {
+		m.errorMarking = errorMarking(i)
+	}
+	if i := nameIndex(wordVarietyNames, c.Variety); i >= 0 {
+		m.wordVariety = wordVariety(i)
+	}
+	m.punctuation = c.Punctuation
+	m.ownQuotes = c.OwnQuotes
+	m.freedom = c.Freedom
+	m.dayCycle = c.Cycle
+	m.waves = c.Waves
+	m.drift = c.Drift
+	m.strict = c.Strict
+	m.ghost = c.Ghost
+	m.blind = c.Blind
+	m.sprints = c.Sprints
+	m.keySounds = c.KeySounds
+	m.music = c.Music
+	m.fetchQuotes = c.FetchQuotes
+	m.ignorePaste = c.IgnorePaste
+	m.countdown = c.Countdown
+	m.keyboard = c.Keyboard
+	if i := nameIndex(layoutNames(), c.Layout); i >= 0 {
+		m.layout = i
+	}
+	m.pauseOnBlur = c.PauseOnBlur
+	m.reduceMotion = c.ReduceMotion
+	for _, n := range livesChoices {
+		if n == c.Lives {
+			m.lives = n
+		}
+	}
+	for _, n := range timeLimitChoices {
+		if n == c.TimeLimit && !m.waves {
+			m.timeLimit = n
+		}
+	}
+	for _, p := range paceLevels {
+		if p == c.Pace {
+			m.pace = p
+		}
+	}
+	for _, b := range botLevels {
+		if b == c.Bot {
+			m.bot = b
+		}
+	}
+	for _, w := range minWPMLevels {
+		if w == c.MinWPM {
+			m.minWPM = w
+		}
+	}
+	for _, a := range minAccuracyLevels {
+		if a == c.MinAccuracy {
+			m.minAccuracy = a
+		}
+	}
+	if c.Volume != nil {
+		for _, v := range volumeLevels {
+			if v == *c.Volume {
+				m.volume = v
+			}
+		}
+	}
+	if i := themeIndex(c.Theme); i >= 0 {
+		m.theme = i
+	}
+	var keyWarning string
+	m.keys, keyWarning = buildKeymap(c.Keys)
+	m.keyConfig = c.Keys
+	if keyWarning != "" && m.menuWarning == "" {
+		m.menuWarning = keyWarning
+	}
+	m.webhookURL = c.ResultsWebhookURL
+	if m.webhookURL != "" && !validWebhookURL(m.webhookURL) && m.menuWarning == "" {
+		m.menuWarning = "results_webhook_url isn't an http or https URL " + glyphDash + " results aren't being sent"
+	}
+
+	soundVolume = effectiveVolume(m)
+	mistakeMarking = m.errorMarking
+	return selectTheme(m, m.theme)
+}
+
+// nameIndex finds name in a list of option names, or returns -1.
+func nameIndex(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// saveConfigCmd writes the current choices to the config file in the
+// background. Failures are ignored — the choices just won't be remembered.
+func saveConfigCmd(m model) tea.Cmd {
+	c := currentConfig(m)
+	return func() tea.Msg {
+		path := configPath()
+		if path == "" {
+			return nil
+		}
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil
+		}
+		_ = os.WriteFile(path, data, 0o644)
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+// Confirming a quit. Quitting to the menu from the pause screen throws the
+// run away, so once a test or game has run for more than confirmQuitAfter
+// the quit key asks first: "quit to menu? y/n" takes the pause message's
+// place, y leaves for the menu, and any other key resumes play. Runs
+// shorter than that have too little to lose to be worth asking about.
+//
+// The run stays paused while asking, so its clock is stopped. Ctrl+C still
+// quits the program straight away.
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	confirmQuitAfter  = 5 * time.Second
+	confirmQuitPrompt = "quit to menu? y/n"
+)
+
+// quitNeedsConfirm reports whether quitting the paused run should ask
+// first.
+func quitNeedsConfirm(m model) bool {
+	if m.state == stateFalling {
+		return fallingElapsed(m) > confirmQuitAfter
+	}
+	return typingElapsed(m) > confirmQuitAfter
+}
+
+// answerQuit handles the key pressed at the prompt: y quits to the menu,
+// anything else resumes.
+func answerQuit(m model, msg tea.KeyMsg) (model, tea.Cmd) {
+	m.confirmingQuit = false
+	if msg.String() == "y" {
+		return quitToMenu(m)
+	}
+	if screenTooSmall(m) {
+		return m, nil // stay paused behind the guard screen
+	}
+	if m.state == stateFalling {
+		return resumeFalling(m)
+	}
+	return resumeTyping(m)
+}
+
+// quitToMenu abandons the paused run for the menu.
+func quitToMenu(m model) (model, tea.Cmd) {
+	if m.state == stateFalling {
+		m.fallingPaused = false
+		m.state = stateMenu
+		return m, stopMusicCmd()
+	}
+	m.paused = false
+	m.state = stateMenu
+	return m, nil
+}
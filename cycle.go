@@ -9,12 +9,24 @@ package main
 // Full cycle = 800 ticks (~2 minutes at 150ms/tick).
 // Ticks 0-399:   Day   — sun arcs left to right
 // Ticks 400-799: Night — moon arcs left to right
+//
+// Colors are worked out in truecolor and then fitted to the terminal (see
+// forProfile): 256-color terminals get the nearest palette color, and
+// 16-color ones keep their own background with only the foreground
+// changing. --colors overrides the detected profile.
+//
+// Weather comes and goes on its own, slower schedule (see weatherAt):
+//   rain — light drops drift down the empty sky, purely for looks
+//   fog  — untargeted aliens fade to the dim color, so they're harder to
+//          pick out; destroys in thick fog are worth fogBonus extra points
 
 import (
 	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 const (
@@ -186,6 +198,90 @@ func cycleColors(tick int) cyclePalette {
 	}
 }
 
+// minContrast is the smallest RGB distance allowed between a foreground
+// color and the cycle's background (see contrasting).
+const minContrast = 80
+
+func rgbDistance(a, b rgb) float64 {
+	return math.Sqrt((a.r-b.r)*(a.r-b.r) + (a.g-b.g)*(a.g-b.g) + (a.b-b.b)*(a.b-b.b))
+}
+
+// contrasting returns fg, or fg pushed toward black or white (whichever
+// is further from bg) just far enough to be at least minContrast from bg.
+// Colors that aren't hex are returned unchanged.
+func contrasting(fg, bg lipgloss.Color) lipgloss.Color {
+	f, ok1 := parseHex(string(fg))
+	b, ok2 := parseHex(string(bg))
+	if !ok1 || !ok2 || rgbDistance(f, b) >= minContrast {
+		return fg
+	}
+	target := rgb{255, 255, 255}
+	if rgbDistance(b, rgb{}) > rgbDistance(b, target) {
+		target = rgb{}
+	}
+	for t := 0.1; t < 1; t += 0.1 {
+		if c := lerpRGB(f, target, t); rgbDistance(c, b) >= minContrast {
+			return lipgloss.Color(c.toHex())
+		}
+	}
+	return lipgloss.Color(target.toHex())
+}
+
+// readable returns the palette with every foreground color kept clear of
+// the background.
+func (p cyclePalette) readable() cyclePalette {
+	p.dim = contrasting(p.dim, p.bg)
+	p.text = contrasting(p.text, p.bg)
+	p.alien = contrasting(p.alien, p.bg)
+	p.shield = contrasting(p.shield, p.bg)
+	p.accent = contrasting(p.accent, p.bg)
+	p.hint = contrasting(p.hint, p.bg)
+	return p
+}
+
+// calmCycleColors is the palette for reduced motion: the background stays
+// night black and the foreground drifts slowly through the readable
+// keyframes (dawn -> sunset over the day, sunset -> night -> dawn over the
+// night) with no sudden shifts.
+func calmCycleColors(tick int) cyclePalette {
+	pos := tick % fullCycleTicks
+
+	// Each leg lasts half or a quarter of the cycle
+	type leg struct {
+		from, to [6]rgb
+		start    int
+		length   int
+	}
+	dawn := [6]rgb{dawnDim, dawnText, dawnAlien, dawnShield, dawnAccent, dawnHint}
+	sunset := [6]rgb{sunsetDim, sunsetText, sunsetAlien, sunsetShield, sunsetAccent, sunsetHint}
+	night := [6]rgb{nightDim, nightText, nightAlien, nightShield, nightAccent, nightHint}
+	legs := []leg{
+		{dawn, sunset, 0, halfCycleTicks},
+		{sunset, night, halfCycleTicks, halfCycleTicks / 2},
+		{night, dawn, halfCycleTicks + halfCycleTicks/2, halfCycleTicks / 2},
+	}
+
+	var c [6]rgb
+	for _, l := range legs {
+		if pos >= l.start && pos < l.start+l.length {
+			t := float64(pos-l.start) / float64(l.length)
+			for i := range c {
+				c[i] = lerpRGB(l.from[i], l.to[i], t)
+			}
+		}
+	}
+
+	return cyclePalette{
+		dim:    lipgloss.Color(c[0].toHex()),
+		text:   lipgloss.Color(c[1].toHex()),
+		alien:  lipgloss.Color(c[2].toHex()),
+		shield: lipgloss.Color(c[3].toHex()),
+		accent: lipgloss.Color(c[4].toHex()),
+		hint:   lipgloss.Color(c[5].toHex()),
+		bg:     lipgloss.Color(nightBg.toHex()),
+	}
+}
+
 // --- Celestial Bodies (multi-character sprites) ---
 
 // celestialSprite is a character placed at an offset from the body's center.
@@ -221,6 +317,17 @@ var moonSprites = []celestialSprite{
 	{0, 1, "▀", false}, {1, 1, "█", true},
 }
 
+// Moon sprite in plain ASCII (see useASCIIGlyphs)
+//
+//	 /
+//	(
+//	 \
+var asciiMoonSprites = []celestialSprite{
+	{1, -1, "/", false},
+	{0, 0, "(", true},
+	{1, 1, "\\", false},
+}
+
 // celestialBody holds position info for rendering.
 type celestialBody struct {
 	x, y     int
@@ -285,24 +392,234 @@ func getCelestialBody(tick int, playWidth, playHeight int) celestialBody {
 }
 
 // renderCelestialOnGrid places the sun or moon sprite on the grid.
-func renderCelestialOnGrid(grid [][]string, body celestialBody, playWidth, playHeight int) {
+func renderCelestialOnGrid(grid *cellGrid, body celestialBody) {
 	sprites := moonSprites
 	if body.isDay {
 		sprites = sunSprites
 	}
 
-	coreStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(body.coreFg)).Bold(true)
-	glowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(body.glowFg))
+	coreStyle := grid.addStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(body.coreFg)).Bold(true))
+	glowStyle := grid.addStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(body.glowFg)))
 
 	for _, sp := range sprites {
-		px := body.x + sp.dx
-		py := body.y + sp.dy
-		if py >= 0 && py < playHeight && px >= 0 && px < playWidth {
-			if sp.bright {
-				grid[py][px] = coreStyle.Render(sp.ch)
-			} else {
-				grid[py][px] = glowStyle.Render(sp.ch)
+		if sp.bright {
+			grid.setString(body.y+sp.dy, body.x+sp.dx, sp.ch, coreStyle)
+		} else {
+			grid.setString(body.y+sp.dy, body.x+sp.dx, sp.ch, glowStyle)
+		}
+	}
+}
+
+// --- Weather ---
+
+type weather int
+
+const (
+	weatherClear weather = iota
+	weatherRain
+	weatherFog
+)
+
+const (
+	weatherSlotTicks = 1200 // each slot has at most one spell of weather
+	weatherTicks     = 480  // how long a spell lasts, fades included
+	weatherFadeTicks = 60
+	rainDensity      = 25 // one drop per this many cells of sky at full strength
+	fogBonus         = 20
+)
+
+// weatherAt returns the weather at tick and how strong it is, 0 to 1,
+// ramping up and down over weatherFadeTicks at each end. Slots are picked
+// by a hash of their number, so a third each are clear, rainy, and foggy,
+// and the first slot is always clear.
+func weatherAt(tick int) (weather, float64) {
+	slot := tick / weatherSlotTicks
+	if slot == 0 {
+		return weatherClear, 0
+	}
+	h := starHash(uint32(slot) ^ 0x5eed)
+	w := weather(h % 3)
+	if w == weatherClear {
+		return weatherClear, 0
+	}
+	start := int((h >> 8) % uint32(weatherSlotTicks-weatherTicks))
+	age := tick%weatherSlotTicks - start
+	if age < 0 || age >= weatherTicks {
+		return weatherClear, 0
+	}
+	in := clamp(float64(age)/weatherFadeTicks, 0, 1)
+	out := clamp(float64(weatherTicks-age)/weatherFadeTicks, 0, 1)
+	return w, in * out
+}
+
+// inThickFog reports whether a falling game is in fog dense enough for
+// fogBonus.
+func inThickFog(m model) bool {
+	if !m.dayCycle {
+		return false
+	}
+	w, strength := weatherAt(m.fallingTicks)
+	return w == weatherFog && strength >= 0.5
+}
+
+// foggedColor fades an alien color toward the dim color as fog thickens.
+func foggedColor(alien, dim lipgloss.Color, strength float64) lipgloss.Color {
+	a, ok1 := parseHex(string(alien))
+	d, ok2 := parseHex(string(dim))
+	if !ok1 || !ok2 {
+		return alien
+	}
+	return lipgloss.Color(lerpRGB(a, d, strength).toHex())
+}
+
+// parseHex reads a #rrggbb or #rgb color.
+func parseHex(s string) (rgb, bool) {
+	var r, g, b int
+	switch len(s) {
+	case 7:
+		if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+			return rgb{}, false
+		}
+	case 4:
+		if _, err := fmt.Sscanf(s, "#%1x%1x%1x", &r, &g, &b); err != nil {
+			return rgb{}, false
+		}
+		r, g, b = r*17, g*17, b*17
+	default:
+		return rgb{}, false
+	}
+	return rgb{float64(r), float64(g), float64(b)}, true
+}
+
+// renderRainOnGrid draws rain over the finished playfield, only on blank
+// cells outside every alien, so it never covers a sprite or word. The
+// number of drops follows the rain's strength.
+func renderRainOnGrid(grid *cellGrid, tick int, strength float64, words []fallingWord, style int) {
+	sky := grid.height - 3 // stays off the bottom rows, like the stars
+	if sky < 1 || grid.width < 1 {
+		return
+	}
+	count := int(float64(grid.width*sky/rainDensity) * strength)
+	for i := 0; i < count; i++ {
+		h := starHash(uint32(i) ^ 0x7a1)
+		row := (int(h>>8) + tick) % sky
+		// Drops slant down and to the left, one column every two rows
+		col := (int(h%uint32(grid.width)) - row/2 + grid.width) % grid.width
+		if !grid.blank(row, col) || underAlien(words, row, col) {
+			continue
+		}
+		ch := '/'
+		if h>>28 < 5 {
+			ch = '\''
+		}
+		grid.set(row, col, ch, style)
+	}
+}
+
+// underAlien reports whether (row, col) is inside an alien's sprite box.
+func underAlien(words []fallingWord, row, col int) bool {
+	for _, fw := range words {
+		art := fw.art()
+		top := int(fw.y) - art.wordRow
+		if row < top || row >= top+len(art.lines) || col < fw.x {
+			continue
+		}
+		if col < fw.x+lipgloss.Width(art.lines[row-top]) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Color profiles ---
+
+// colorProfiles are the --colors values besides "auto", which keeps the
+// profile lipgloss detected.
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"256":       termenv.ANSI256,
+	"16":        termenv.ANSI,
+}
+
+// setColorProfile applies a --colors value.
+func setColorProfile(name string) error {
+	if name == "auto" {
+		return nil
+	}
+	p, ok := colorProfiles[name]
+	if !ok {
+		return fmt.Errorf("unknown --colors %q (want auto, truecolor, 256, or 16)", name)
+	}
+	lipgloss.SetColorProfile(p)
+	return nil
+}
+
+// cycleShiftsBackground reports whether the terminal has enough colors
+// for the cycle's background; with 16 it would jump between a few
+// unrelated colors.
+func cycleShiftsBackground() bool {
+	return lipgloss.ColorProfile() <= termenv.ANSI256
+}
+
+// forProfile fits the palette to the terminal's color profile.
+func (p cyclePalette) forProfile() cyclePalette {
+	p.dim = profileColor(p.dim)
+	p.text = profileColor(p.text)
+	p.alien = profileColor(p.alien)
+	p.shield = profileColor(p.shield)
+	p.accent = profileColor(p.accent)
+	p.hint = profileColor(p.hint)
+	p.bg = profileColor(p.bg)
+	if !cycleShiftsBackground() {
+		p.bg = ""
+	}
+	return p
+}
+
+// profileColor turns a hex color into the nearest ANSI-256 color on a
+// 256-color terminal. Otherwise it's returned as is: truecolor shows it
+// exactly, and lipgloss maps it for 16 colors.
+func profileColor(c lipgloss.Color) lipgloss.Color {
+	if lipgloss.ColorProfile() != termenv.ANSI256 {
+		return c
+	}
+	v, ok := parseHex(string(c))
+	if !ok {
+		return c
+	}
+	return lipgloss.Color(strconv.Itoa(nearestANSI256(v)))
+}
+
+// ansiCubeLevels are the channel values of the 6x6x6 color cube (colors
+// 16-231).
+var ansiCubeLevels = [6]float64{0, 95, 135, 175, 215, 255}
+
+// nearestANSI256 returns the closest color in the cube or the gray ramp
+// (232-255). The first 16 colors are skipped, since terminals are free to
+// redefine them.
+func nearestANSI256(c rgb) int {
+	nearestLevel := func(v float64) int {
+		best := 0
+		for i, l := range ansiCubeLevels {
+			if math.Abs(v-l) < math.Abs(v-ansiCubeLevels[best]) {
+				best = i
 			}
 		}
+		return best
+	}
+	r, g, b := nearestLevel(c.r), nearestLevel(c.g), nearestLevel(c.b)
+	cube := rgb{ansiCubeLevels[r], ansiCubeLevels[g], ansiCubeLevels[b]}
+
+	gray := int(math.Round(((c.r+c.g+c.b)/3 - 8) / 10))
+	if gray < 0 {
+		gray = 0
+	}
+	if gray > 23 {
+		gray = 23
+	}
+	level := float64(8 + gray*10)
+	if rgbDistance(c, rgb{level, level, level}) < rgbDistance(c, cube) {
+		return 232 + gray
 	}
+	return 16 + r*36 + g*6 + b
 }
@@ -0,0 +1,134 @@
+package main
+
+// The daily challenge, started from the menu with "d": a 60-second test of
+// plain words generated from today's date (UTC), so everyone gets the same
+// words each day.
+//
+// The words are generated once when the challenge starts and reused for
+// every re-attempt, so they can't change under you (say, past midnight).
+// Results are stored with the date; the first one of the day is the score
+// the menu shows, and later ones are flagged as re-attempts.
+//
+// The challenge's fixed setup temporarily replaces the menu's choices.
+// They're put back as soon as the game returns to the menu (lessons do the
+// same, see lessons.go).
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const dailyDuration = 60 * time.Second
+
+// menuChoices are the menu options the daily challenge and lessons
+// override.
+type menuChoices struct {
+	contentMode contentMode
+	testMode    testMode
+	duration    time.Duration
+	wordCount   int
+	punctuation bool
+	difficulty  difficulty
+	wordSet     int
+}
+
+// saveMenuChoices snapshots the menu's choices before a fixed setup
+// replaces them. A snapshot already taken is kept, so switching from one
+// fixed setup to another still restores the menu's own.
+func saveMenuChoices(m model) model {
+	if m.savedChoices == nil {
+		m.savedChoices = &menuChoices{
+			contentMode: m.contentMode,
+			testMode:    m.testMode,
+			duration:    m.duration,
+			wordCount:   m.wordCount,
+			punctuation: m.punctuation,
+			difficulty:  m.difficulty,
+			wordSet:     m.wordSet,
+		}
+	}
+	return m
+}
+
+// restoreMenuChoices puts back the choices saveMenuChoices snapshotted.
+func restoreMenuChoices(m model) model {
+	if c := m.savedChoices; c != nil {
+		m.contentMode = c.contentMode
+		m.testMode = c.testMode
+		m.duration = c.duration
+		m.wordCount = c.wordCount
+		m.punctuation = c.punctuation
+		m.difficulty = c.difficulty
+		m.wordSet = c.wordSet
+	}
+	m.savedChoices = nil
+	return m
+}
+
+// dailyDate is today's challenge date, like "2026-10-16".
+func dailyDate(now time.Time) string {
+	return now.UTC().Format("2006-01-02")
+}
+
+// dailySeed turns a challenge date into its seed, e.g. 20261016.
+func dailySeed(date string) int64 {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0
+	}
+	return int64(t.Year()*10000 + int(t.Month())*100 + t.Day())
+}
+
+// startDaily sets up today's challenge and starts its test.
+func startDaily(m model) model {
+	m = saveMenuChoices(m)
+	m.contentMode = modeWords
+	m.testMode = testModeTime
+	m.duration = dailyDuration
+	m.punctuation = false
+	m.difficulty = difficultyNormal
+	m.wordSet = 0
+
+	m.daily = dailyDate(time.Now())
+	m.seed = dailySeed(m.daily)
+	m.rng = rand.New(rand.NewSource(m.seed))
+	m.dailyWords = generateWords(m.rng, commonWords, testLength(m))
+	return startDailyTest(m)
+}
+
+// startDailyTest (re)starts the challenge on its snapshotted words.
+func startDailyTest(m model) model {
+	m = startTypingTest(m, append([]string(nil), m.dailyWords...))
+	m.seed = dailySeed(m.daily)
+	m.seedValid = true
+	return m
+}
+
+// endDaily leaves the challenge. The caller restores the menu choices.
+func endDaily(m model) model {
+	m.daily = ""
+	m.dailyWords = nil
+	return m
+}
+
+// dailyResult returns the first result recorded for a challenge date.
+func dailyResult(history []historyEntry, date string) (historyEntry, bool) {
+	for _, e := range history {
+		if e.Daily == date {
+			return e, true
+		}
+	}
+	return historyEntry{}, false
+}
+
+// renderDailyStatus is the menu line saying whether today's challenge is
+// done and how it went.
+func renderDailyStatus(m model) string {
+	label := styleStatLabel.Render("daily     ")
+	if e, ok := dailyResult(m.history, dailyDate(time.Now())); ok {
+		return label + styleCorrect.Render("done today") +
+			styleHint.Render(" "+glyphDash+" ") + styleStatValue.Render(fmt.Sprintf("%.0f wpm", e.WPM))
+	}
+	return label + styleHint.Render("not played today "+glyphDash+" press d")
+}
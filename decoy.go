@@ -0,0 +1,96 @@
+package main
+
+// Decoys: now and then a friendly astronaut floats down among the aliens,
+// its word in the success color. It can be targeted like anything else,
+// which is the trap for typing at whatever starts with the right letter:
+// shooting one costs decoyPenalty points and the combo. Left alone it
+// drifts through the shield without doing any harm.
+//
+//      _
+//     [o]
+//   (word)
+//     /|\
+//
+// Decoys only come in falling mode's own spawns, never in an invaders
+// formation, and a nuke leaves them be.
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	decoyChance   = 12  // 1 in N spawns is a decoy
+	decoyPenalty  = 100 // points for shooting one
+	decoyAttempts = 5   // picks to find a word no nearby alien starts like
+)
+
+// buildDecoyArt builds the astronaut, laid out like an alien (word on row
+// 2, two columns in) so everything that places aliens places it too.
+func buildDecoyArt(word string) builtAlien {
+	bodyRow := " (" + word + ") "
+	totalWidth := lipgloss.Width(bodyRow)
+	center := func(s string) string {
+		pad := max(totalWidth-lipgloss.Width(s), 0)
+		return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+	}
+	return builtAlien{
+		lines: []string{
+			center("_"),
+			center("[o]"),
+			bodyRow,
+			center(`/|\`),
+		},
+		wordRow:   2,
+		wordCol:   2,
+		wordLen:   utf8.RuneCountInString(word),
+		wordWidth: lipgloss.Width(word),
+		width:     totalWidth,
+	}
+}
+
+// pickDecoyWord chooses a decoy's word. It tries a few words for one that
+// doesn't start like an alien in the top half of the playfield, where the
+// two would be easy to mix up; false means it found none, and the spawn
+// should be an ordinary alien.
+func pickDecoyWord(m model) (string, bool) {
+	for attempt := 0; attempt < decoyAttempts; attempt++ {
+		word, ok := pickFallingWord(m)
+		if ok && !startsLikeNearbyAlien(m, word) {
+			return word, true
+		}
+	}
+	return "", false
+}
+
+func startsLikeNearbyAlien(m model, word string) bool {
+	first, _ := utf8.DecodeRuneInString(word)
+	half := float64(fallingPlayHeight(m)) / 2
+	for _, fw := range m.fallingWords {
+		if fw.decoy || fw.y > half {
+			continue
+		}
+		if r, _ := utf8.DecodeRuneInString(fw.word); r == first {
+			return true
+		}
+	}
+	return false
+}
+
+// shootDecoy charges for a decoy that was shot down.
+func shootDecoy(m model, fw fallingWord) model {
+	lost := min(decoyPenalty, m.fallingScore)
+	m.fallingScore -= lost
+	m.fallingCombo = 0
+	m.fallingDecoysShot++
+	m.scorePopups = append(m.scorePopups, scorePopup{
+		x:     wordCenter(fw),
+		y:     int(fw.y) - 2,
+		text:  fmt.Sprintf("-%d", lost),
+		ticks: popupDuration,
+	})
+	return m
+}
@@ -0,0 +1,86 @@
+package main
+
+// Horizontal drift for falling mode (menu option). Each alien spawns with a
+// small sideways speed, some to the left and some to the right, and
+// bounces off the playfield edges. An alien about to run into another one
+// turns around instead, so sprites never merge; aliens that spawned
+// overlapping are left free to move apart.
+//
+// Drift moves aliens once per frame along with their fall, in fractional
+// columns (fallingWord.fx) so slow speeds still move smoothly. The turret
+// follows a drifting target between keystrokes.
+
+const (
+	driftMinSpeed = 0.5 // columns per second
+	driftMaxSpeed = 1.5
+)
+
+// driftVelocity picks a new alien's sideways speed, or 0 with drift off.
+func driftVelocity(m model) float64 {
+	if !m.drift {
+		return 0
+	}
+	v := driftMinSpeed + m.rng.Float64()*(driftMaxSpeed-driftMinSpeed)
+	if m.rng.Intn(2) == 0 {
+		v = -v
+	}
+	return v
+}
+
+// driftAliens moves every alien sideways by one frame's worth of drift.
+func driftAliens(m model) model {
+	if !m.drift {
+		return m
+	}
+	step := frameInterval.Seconds()
+	playWidth := fallingPlayWidth(m)
+	for i := range m.fallingWords {
+		fw := &m.fallingWords[i]
+		minX := float64(edgePadding)
+		maxX := float64(playWidth - fw.art().width - edgePadding)
+		if maxX < minX {
+			continue // no room to move
+		}
+
+		fx := fw.fx + fw.vx*step
+		switch {
+		case fx < minX:
+			fx, fw.vx = minX, -fw.vx
+		case fx > maxX:
+			fx, fw.vx = maxX, -fw.vx
+		}
+		x := int(fx + 0.5)
+		if x != fw.x && collidesAt(m, i, x) && !collidesAt(m, i, fw.x) {
+			fw.vx = -fw.vx
+			continue
+		}
+		fw.fx = fx
+		fw.x = x
+	}
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		m = aimTurret(m)
+	}
+	return m
+}
+
+// collidesAt reports whether alien i, moved to x, would touch another
+// alien: overlapping rows and less than a column apart.
+func collidesAt(m model, i, x int) bool {
+	a := m.fallingWords[i]
+	aArt := a.art()
+	aTop := int(a.y) - aArt.wordRow
+	for j, b := range m.fallingWords {
+		if j == i {
+			continue
+		}
+		bArt := b.art()
+		bTop := int(b.y) - bArt.wordRow
+		if aTop >= bTop+len(bArt.lines) || bTop >= aTop+len(aArt.lines) {
+			continue
+		}
+		if x < b.x+bArt.width+1 && x+aArt.width > b.x-1 {
+			return true
+		}
+	}
+	return false
+}
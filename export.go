@@ -0,0 +1,126 @@
+package main
+
+// Result export. "e" on the classic results screen or the falling game over
+// screen writes the full result as indented JSON to a timestamped file,
+// cli_typer-20261016-153000.json, in the current directory or --export-dir.
+// The hint line flashes where it went, or why it couldn't be written.
+//
+// Each export is the --json result (see history.go) plus what only the
+// screen showed: the menu and settings choices, and the per-second WPM
+// samples and missed words of a classic test, or the combo, leaks, and
+// waves of a falling game.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// classicExport is the exported form of a classic test result.
+type classicExport struct {
+	printedResult
+	Config       config    `json:"config"`
+	Consistency  *float64  `json:"consistency,omitempty"` // nil with too few samples
+	CorrectWords int       `json:"correct_words"`
+	TotalWords   int       `json:"total_words"`
+	WPMSamples   []float64 `json:"wpm_samples"` // raw WPM of each full second
+	MissedWords  []string  `json:"missed_words"`
+}
+
+// fallingExport is the exported form of a falling game result.
+type fallingExport struct {
+	printedResult
+	Config    config       `json:"config"`
+	Survived  float64      `json:"survived"` // seconds, excluding pauses
+	Destroyed int          `json:"destroyed"`
+	Leaked    int          `json:"leaked"`
+	BestCombo int          `json:"best_combo"`
+	Waves     []waveExport `json:"waves,omitempty"` // waves format only: each cleared wave
+}
+
+type waveExport struct {
+	Destroyed int `json:"destroyed"`
+	Leaked    int `json:"leaked"`
+	Bonus     int `json:"bonus"`
+}
+
+// exportedMsg reports how writing an export went.
+type exportedMsg struct {
+	path string
+	err  error
+}
+
+// exportResult builds the export for the result on screen.
+func exportResult(m model) any {
+	r := *m.lastResult
+	// The webhook URL may hold a token, and the webhook sends this too
+	cfg := currentConfig(m)
+	cfg.ResultsWebhookURL = ""
+	if r.Mode == "falling" {
+		e := fallingExport{
+			printedResult: r,
+			Config:        cfg,
+			Survived:      fallingElapsed(m).Seconds(),
+			Destroyed:     m.fallingDestroyed,
+			Leaked:        m.fallingLeaked,
+			BestCombo:     m.fallingBestCombo,
+		}
+		if hasWaves(m) {
+			for _, w := range m.fallingWaveStats {
+				e.Waves = append(e.Waves, waveExport{Destroyed: w.destroyed, Leaked: w.leaked, Bonus: w.bonus})
+			}
+		}
+		return e
+	}
+
+	e := classicExport{
+		printedResult: r,
+		Config:        cfg,
+		CorrectWords:  m.correctWords,
+		TotalWords:    m.totalWords,
+		WPMSamples:    m.wpmSamples,
+		MissedWords:   m.missedWords,
+	}
+	if m.hasConsistency {
+		c := m.finalConsistency
+		e.Consistency = &c
+	}
+	if e.WPMSamples == nil {
+		e.WPMSamples = []float64{}
+	}
+	if e.MissedWords == nil {
+		e.MissedWords = []string{}
+	}
+	return e
+}
+
+// exportCmd writes the result on screen to its file in the background.
+func exportCmd(m model) tea.Cmd {
+	if m.lastResult == nil {
+		return nil
+	}
+	dir := m.exportDir
+	if dir == "" {
+		dir = "."
+	}
+	name := fmt.Sprintf("cli_typer-%s.json", m.lastResult.Time.Local().Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	data, err := json.MarshalIndent(exportResult(m), "", "  ")
+	return func() tea.Msg {
+		if err == nil {
+			err = os.WriteFile(path, append(data, '\n'), 0o644)
+		}
+		return exportedMsg{path: path, err: err}
+	}
+}
+
+// handleExported flashes the outcome of an export.
+func handleExported(m model, msg exportedMsg) (model, tea.Cmd) {
+	if msg.err != nil {
+		return setFlash(m, fmt.Sprintf("export failed: %v", msg.err), true)
+	}
+	return setFlash(m, "exported to "+msg.path, false)
+}
@@ -10,14 +10,51 @@ package main
 //                 /   \      /  |  \              /   \
 //
 // - Turret on the shield slides to track the targeted word
-// - Laser beam + explosion on word destroy
+// - A laser shot flies from the turret when a word is completed, straight
+//   or slanting toward the alien's word, which explodes (and scores) when
+//   it arrives. With reduced motion there's no shot and the alien goes at
+//   once
 // - Overlap-aware spawning prevents aliens from stacking
+// - Power-up aliens (odd brackets, own color) trigger an effect when destroyed:
+//     {freeze}  stops all falling, spawning, and difficulty ramp for ~3s
+//     [nuke]    destroys every alien on screen in a chain of explosions
+//     <heart>   restores a life, up to the maximum
+// - Scoring: a destroy is worth pointsPerChar per character of the word,
+//   half again if the alien is still in the top third of its fall; a leak
+//   costs leakPointsPerChar per character. A "+N" floats up where it blew
+// - Armored aliens (armor.go) have to be typed twice, and big aliens
+//   sometimes split in two when destroyed (split.go)
+// - Decoys (decoy.go): friendly astronauts that cost points when shot and
+//   pass through the shield harmlessly
+// - An alien within dangerRows of the shield flashes in the error color,
+//   with a warning sound as it crosses the line
+// - Combo: every comboStep destroys in a row without a mistyped key or a
+//   leaked alien raises the points multiplier (x1 → x2 → x3)
+// - A game opens with a "get ready" countdown: the playfield is up, but
+//   nothing spawns or falls, typing is ignored, and the clock hasn't started
+// - Esc pauses: ticks stop being scheduled, so everything freezes in place
+// - Waves format (menu option): each wave spawns a fixed budget of aliens;
+//   once they're all destroyed or leaked a short "wave cleared" break
+//   follows (with a bonus if none leaked) and the next wave starts faster.
+//   The endless format ramps difficulty with time instead, and the timed
+//   format (timed.go) against a clock.
+//
+// The loop runs in frames (frameInterval) so aliens descend smoothly at a
+// speed in rows per second. Everything else — spawning, shield hits,
+// effects, difficulty — happens on a game tick every framesPerTick frames,
+// so all durations counted in ticks keep the same wall-clock length.
+//
+// Each tick message carries the ID of the loop that scheduled it. Pausing,
+// resuming, or restarting bumps fallingTickID, so a tick already in flight
+// from an older loop is dropped instead of running a second loop alongside.
 
 import (
 	"fmt"
-	"math/rand"
+	"math"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -26,36 +63,115 @@ import (
 const (
 	edgePadding     = 3
 	turretSpeed     = 3
-	laserDuration   = 3
+	shotSpeed       = 3 // rows per frame
 	explodeDuration = 4
+
+	frameInterval = 50 * time.Millisecond
+	framesPerTick = 3 // one game tick = 150ms
+
+	readyFrames = int(3 * time.Second / frameInterval) // the "get ready" countdown
+
+	defaultFallingLives = 3
+	freezeDuration      = 20 // ticks (~3s at 150ms/tick)
+	mistypeFlashTicks   = 2  // the input prompt flashes after a mistype
+	dangerRows          = 4  // rows above the shield where aliens flash
+	retargetKeystrokes  = 2  // keys after which the target is settled
+	powerUpChance       = 15 // 1 in N spawns is a power-up
+
+	pointsPerChar     = 10
+	earlyBonusPercent = 50 // extra for a destroy in the top third of the fall
+	leakPointsPerChar = 5
+	popupDuration     = 6 // ticks a "+N" floats above a destroy
+
+	comboStep     = 3 // consecutive destroys per multiplier step
+	maxMultiplier = 3
+
+	waveBaseSize      = 8   // aliens in wave 1
+	waveGrowth        = 2   // extra aliens in each later wave
+	waveBreakDuration = 14  // ticks (~2s) of "wave cleared" between waves
+	waveBonus         = 50  // no-leak bonus points per wave number
+	waveRampTicks     = 134 // difficulty each wave adds, in endless-mode ticks
+)
+
+type powerUp int
+
+const (
+	powerNone powerUp = iota
+	powerFreeze
+	powerNuke
+	powerHeart
 )
 
 type fallingWord struct {
-	word   string
-	x      int     // left edge of the alien art
-	y      float64 // row of the WORD LINE (always row index 2 of the alien)
-	typed  int
-	active bool
+	id      int // unique within a game, assigned at spawn
+	word    string
+	x       int     // left edge of the alien art
+	fx      float64 // exact left edge while drifting (see drift.go)
+	vx      float64 // drift in columns per second, 0 without drift
+	y       float64 // row of the WORD LINE (always row index 2 of the alien)
+	typed   int
+	active  bool
+	doomed  bool // completed, with a shot on its way
+	decoy   bool // a friendly astronaut (see decoy.go)
+	armored bool // still wearing armor (see armor.go)
+	warned  bool // has come within dangerRows of the shield
+	power   powerUp
+	sprite  builtAlien // built once at spawn; word and power never change
+}
+
+// art returns the alien sprite for this word.
+func (fw fallingWord) art() builtAlien {
+	return fw.sprite
+}
+
+// waveStat is the outcome of one cleared wave.
+type waveStat struct {
+	destroyed int
+	leaked    int
+	bonus     int
 }
 
 type explosion struct {
 	x     int
 	y     int
 	ticks int
+	delay int  // ticks to wait before appearing (for chained nukes)
+	small bool // over after the first two phases (armor coming off)
 }
 
-type laserBeam struct {
-	x     int
-	fromY int
-	toY   int
+// scorePopup is the "+N" floating up from a destroyed alien.
+type scorePopup struct {
+	x     int // center column
+	y     int
+	text  string
 	ticks int
+	delay int // ticks to wait before appearing (matches its explosion)
 }
 
-type fallingTickMsg time.Time
+// shot is a laser shot on its way up to a completed alien. It flies along
+// the line from where the turret stood when it fired to the alien's word,
+// re-aimed each frame in case the alien has moved.
+type shot struct {
+	fromX, fromY int     // the turret when it fired, on the shield row
+	prevX, prevY int     // the head a frame ago, where the streak starts
+	x            int     // column of the shot's head
+	y            float64 // row of the shot's head
+	targetID     int
+}
 
-func fallingTickCmd() tea.Cmd {
-	return tea.Tick(150*time.Millisecond, func(t time.Time) tea.Msg {
-		return fallingTickMsg(t)
+type fallingTickMsg struct {
+	id int
+}
+
+// startFallingCmd starts a freshly initialised game: its frame loop, and
+// the music if it's turned on (and not already playing from the last game).
+func startFallingCmd(m model) tea.Cmd {
+	return tea.Batch(fallingTickCmd(m.fallingTickID), startMusicCmd(m))
+}
+
+func fallingTickCmd(id int) tea.Cmd {
+	return tea.Tick(frameInterval, func(time.Time) tea.Msg {
+		return fallingTickMsg{id: id}
 	})
 }
 
@@ -72,22 +188,36 @@ func fallingTickCmd() tea.Cmd {
 //   /| |\          /| |\            / | \
 //                  /   \           /  |  \
 
+//
+// All widths are display columns, so accented and double-width (CJK)
+// words line up with their art.
+
 type builtAlien struct {
-	lines   []string
-	wordRow int
-	wordCol int
-	wordLen int
-	width   int
+	lines     []string
+	wordRow   int
+	wordCol   int // first column (and rune) of the word in the body row
+	wordLen   int // runes in the word
+	wordWidth int // display columns the word takes up
+	width     int
 }
 
-func buildAlienArt(word string) builtAlien {
-	n := len(word)
-	bodyRow := " |" + word + "| "
-	totalWidth := len(bodyRow)
+func buildAlienArt(word string, power powerUp) builtAlien {
+	n := utf8.RuneCountInString(word)
+	open, closed := "|", "|"
+	switch power {
+	case powerFreeze:
+		open, closed = "{", "}"
+	case powerNuke:
+		open, closed = "[", "]"
+	case powerHeart:
+		open, closed = "<", ">"
+	}
+	bodyRow := " " + open + word + closed + " "
+	totalWidth := lipgloss.Width(bodyRow)
 
 	// center pads a string to totalWidth
 	center := func(s string) string {
-		pad := totalWidth - len(s)
+		pad := totalWidth - lipgloss.Width(s)
 		if pad <= 0 {
 			return s
 		}
@@ -122,113 +252,455 @@ func buildAlienArt(word string) builtAlien {
 		}
 	}
 
+	// Power-ups wear a marker in place of their hat
+	switch power {
+	case powerFreeze:
+		lines[0] = center("~*~")
+	case powerNuke:
+		lines[0] = center("/!\\")
+	case powerHeart:
+		lines[0] = center("<3")
+	}
+
 	return builtAlien{
-		lines:   lines,
-		wordRow: 2,
-		wordCol: 2, // " |" = 2 chars before word starts
-		wordLen: n,
-		width:   totalWidth,
+		lines:     lines,
+		wordRow:   2,
+		wordCol:   2, // " |" = 2 chars before word starts
+		wordLen:   n,
+		wordWidth: lipgloss.Width(word),
+		width:     totalWidth,
 	}
 }
 
 // --- Game state management ---
 
 func initFallingState(m model) model {
+	m = reseed(m)
 	m.state = stateFalling
+	m.flash = ""
 	m.fallingWords = nil
 	m.fallingInput = nil
 	m.fallingTarget = -1
-	m.fallingLives = 3
+	m.fallingLives = m.lives
+	m.fallingFreezeTicks = 0
+	m.fallingFrozenTicks = 0
 	m.fallingScore = 0
-	m.fallingSpeed = 0.3
+	m.fallingLeakPoints = 0
+	m.fallingSpeed = fallingSpeedForTick(0)
+	m.fallingFrames = 0
 	m.fallingSpawnCD = 0
 	m.fallingTicks = 0
 	m.fallingGameOver = false
+	m.fallingWon = false
+	m.fallingReadyFrames = readyFrames
 	m.fallingStartTime = time.Now()
+	m.fallingPaused = false
+	m.fallingTickID++
 	m.fallingCharsTyped = 0
+	m.fallingDestroyed = 0
+	m.fallingCombo = 0
+	m.fallingBestCombo = 0
+	m.fallingKeystrokes = 0
+	m.fallingMistypes = 0
+	m.fallingTypoFlash = 0
+	m.fallingLeaked = 0
+	m.fallingWarnings = 0
+	m.fallingStreak = 0
+	m.fallingBestStreak = 0
+	m.fallingLongestWord = ""
+	m.fallingPeakAliens = 0
+	m.fallingDecoysShot = 0
+	m.fallingWaveStats = nil
+	m.fallingWaveBreak = 0
+	m.fallingMinWidth = fallingMinWidth(m)
+	m = startWave(m, 1)
 	m.turretX = m.width / 2
 	m.explosions = nil
-	m.laser = nil
+	m.scorePopups = nil
+	m.shots = nil
+	if m.fallingGrid == nil {
+		m.fallingGrid = &cellGrid{}
+	}
+	return m
+}
+
+// livesChoices are the starting lives offered on the menu.
+var livesChoices = []int{1, defaultFallingLives, 5}
+
+// waveSize is the number of aliens wave n spawns.
+func waveSize(n int) int {
+	return waveBaseSize + (n-1)*waveGrowth
+}
+
+// startWave resets the per-wave counters for wave n.
+func startWave(m model, n int) model {
+	m.fallingWave = n
+	m.fallingWaveSpawned = 0
+	m.fallingWaveDestroyed = 0
+	m.fallingWaveLeaked = 0
+	m.fallingSpawnCD = 0
+	if invadersGame(m) {
+		m = spawnFormation(m)
+	}
+	return m
+}
+
+// hasWaves reports whether a game is played in waves: the waves format,
+// or invaders, where each formation is a wave.
+func hasWaves(m model) bool {
+	return m.waves || invadersGame(m)
+}
+
+// waveCleared reports whether every alien of the current wave has been
+// spawned and is now gone, destroyed or leaked.
+func waveCleared(m model) bool {
+	return m.fallingWaveSpawned >= waveSize(m.fallingWave) && len(m.fallingWords) == 0
+}
+
+// finishWave records the wave just cleared, awards the no-leak bonus, and
+// starts the break before the next wave.
+func finishWave(m model) model {
+	stat := waveStat{destroyed: m.fallingWaveDestroyed, leaked: m.fallingWaveLeaked}
+	if m.fallingWaveLeaked == 0 {
+		stat.bonus = waveBonus * m.fallingWave
+		m.fallingScore += stat.bonus
+	}
+	m.fallingWaveStats = append(m.fallingWaveStats, stat)
+	m.fallingWaveBreak = waveBreakDuration
+	m.fallingInput = nil
+	m.fallingTarget = -1
 	return m
 }
 
 func updateFalling(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case fallingTickMsg:
-		if m.fallingGameOver {
+		if m.fallingGameOver || m.fallingPaused || msg.id != m.fallingTickID {
 			return m, nil
 		}
+		if m.fallingReadyFrames > 0 {
+			m = countDownReady(m)
+			return m, fallingTickCmd(m.fallingTickID)
+		}
 		livesBefore := m.fallingLives
-		m = fallingTick(m)
+		wavesBefore := len(m.fallingWaveStats)
+		warningsBefore := m.fallingWarnings
+		m = fallingFrame(m)
 		var cmds []tea.Cmd
+		m, cmds = advanceShots(m)
+		if m.fallingFrames%framesPerTick == 0 {
+			m = fallingTick(m)
+		}
 		if m.fallingLives < livesBefore {
 			cmds = append(cmds, playSound(soundHit))
 		}
+		if m.fallingWarnings > warningsBefore {
+			cmds = append(cmds, playSoundPitched(soundClick, 0.6))
+		}
+		if len(m.fallingWaveStats) > wavesBefore {
+			cmds = append(cmds, playSoundPitched(soundClick, 1.5))
+		}
 		if m.fallingGameOver {
-			cmds = append(cmds, playSound(soundGameOver))
+			// The records file, not the history, decides falling high scores,
+			// so let it overwrite the personal-best fields recordResult set.
+			var saveCmd, recordsCmd, achievementsCmd, webhookCmd tea.Cmd
+			e := fallingHistoryEntry(m)
+			m, saveCmd = recordResult(m, e)
+			m, recordsCmd = updateFallingRecords(m)
+			m, achievementsCmd = checkAchievements(m, e)
+			m, webhookCmd = postResult(m)
+			endSound := playSound(soundGameOver)
+			if m.fallingWon {
+				endSound = playSoundPitched(soundClick, 2)
+			}
+			cmds = append(cmds, endSound, stopMusicCmd(), saveCmd, recordsCmd, achievementsCmd, webhookCmd)
 			return m, tea.Batch(cmds...)
 		}
-		cmds = append(cmds, fallingTickCmd())
+		cmds = append(cmds, fallingTickCmd(m.fallingTickID))
 		return m, tea.Batch(cmds...)
 
+	case exportedMsg:
+		return handleExported(m, msg)
+
+	case flashClearMsg:
+		return clearFlash(m, msg), nil
+
 	case tea.KeyMsg:
 		if m.fallingGameOver {
 			return handleGameOverKey(m, msg)
 		}
-		return handleFallingKey(m, msg)
+		if m.confirmingQuit {
+			return answerQuit(m, msg)
+		}
+		if m.fallingPaused {
+			switch {
+			case m.keys.is(msg, actionPause):
+				return resumeFalling(m)
+			case m.keys.is(msg, actionQuit):
+				if quitNeedsConfirm(m) {
+					m.confirmingQuit = true
+					return m, nil
+				}
+				return quitToMenu(m)
+			}
+			return m, nil
+		}
+		return handleFallingKey(m, emulateLayout(m, msg))
 	}
 
 	return m, nil
 }
 
+// Smallest terminal falling mode can be played in.
+const (
+	minFallingWidth  = 40
+	minFallingHeight = 16
+)
+
+// fallingPlayHeight is the number of grid rows above the shield.
+func fallingPlayHeight(m model) int {
+	playHeight := m.height - 6
+	if playHeight < 5 {
+		playHeight = 5
+	}
+	return playHeight
+}
+
+func fallingPlayWidth(m model) int {
+	playWidth := m.width
+	if playWidth < 20 {
+		playWidth = 20
+	}
+	return playWidth
+}
+
+// resizeFalling refits a game in progress to a new terminal size. Aliens
+// are moved proportionally and clamped so their art stays on screen, the
+// turret is kept on the shield, and effects that no longer fit are dropped.
+// Shrinking below the playable minimum pauses the game.
+func resizeFalling(m model, oldWidth, oldHeight int) (model, tea.Cmd) {
+	if oldWidth == 0 || oldHeight == 0 {
+		// First size of a game started before the terminal size was known
+		m.turretX = fallingPlayWidth(m) / 2
+		if invadersGame(m) {
+			m = spawnFormation(m)
+		}
+		return m, nil
+	}
+	oldPlayWidth := oldWidth
+	if oldPlayWidth < 20 {
+		oldPlayWidth = 20
+	}
+	oldPlayHeight := oldHeight - 6
+	if oldPlayHeight < 5 {
+		oldPlayHeight = 5
+	}
+	playWidth := fallingPlayWidth(m)
+	playHeight := fallingPlayHeight(m)
+	scaleX := float64(playWidth) / float64(oldPlayWidth)
+	scaleY := float64(playHeight) / float64(oldPlayHeight)
+
+	for i := range m.fallingWords {
+		fw := &m.fallingWords[i]
+		art := fw.art()
+		x := int(float64(fw.x) * scaleX)
+		if maxX := playWidth - art.width - edgePadding; x > maxX {
+			x = maxX
+		}
+		if x < edgePadding {
+			x = edgePadding
+		}
+		fw.x = x
+		fw.fx = float64(x)
+		fw.y *= scaleY
+	}
+
+	m.turretX = int(float64(m.turretX) * scaleX)
+	m.turretStartX = int(float64(m.turretStartX) * scaleX)
+	if m.turretX > playWidth-2 {
+		m.turretX = playWidth - 2
+	}
+	if m.turretX < 1 {
+		m.turretX = 1
+	}
+
+	var kept []explosion
+	for _, e := range m.explosions {
+		if e.x >= 0 && e.x < playWidth && e.y >= 0 && e.y < playHeight {
+			kept = append(kept, e)
+		}
+	}
+	m.explosions = kept
+	m.scorePopups = nil
+	for i := range m.shots {
+		s := &m.shots[i]
+		s.fromX = int(float64(s.fromX) * scaleX)
+		s.prevX = int(float64(s.prevX) * scaleX)
+		s.x = int(float64(s.x) * scaleX)
+		s.fromY = playHeight
+		s.prevY = int(float64(s.prevY) * scaleY)
+		s.y *= scaleY
+	}
+
+	if screenTooSmall(m) && !m.fallingPaused && !m.fallingGameOver {
+		return pauseFalling(m)
+	}
+	return m, nil
+}
+
+func pauseFalling(m model) (model, tea.Cmd) {
+	m.fallingPaused = true
+	m.fallingPausedAt = time.Now()
+	m.fallingTickID++
+	return m, pauseMusicCmd()
+}
+
+func resumeFalling(m model) (model, tea.Cmd) {
+	m.fallingPaused = false
+	m.blurPaused = false
+	m.confirmingQuit = false
+	m.fallingStartTime = m.fallingStartTime.Add(time.Since(m.fallingPausedAt))
+	m.fallingTickID++
+	// Starting rather than unpausing, in case muting stopped the music
+	return m, tea.Batch(fallingTickCmd(m.fallingTickID), startMusicCmd(m))
+}
+
+// countDownReady runs one frame of the "get ready" countdown, starting
+// the clock when it runs out.
+func countDownReady(m model) model {
+	m.fallingReadyFrames--
+	if m.fallingReadyFrames == 0 {
+		m.fallingStartTime = time.Now()
+	}
+	return m
+}
+
+// readySecondsLeft is the number the countdown shows.
+func readySecondsLeft(m model) int {
+	left := time.Duration(m.fallingReadyFrames) * frameInterval
+	return int((left + time.Second - 1) / time.Second)
+}
+
+// fallingElapsed is the time played so far, excluding pauses and the
+// countdown. It stops counting once the game is over.
+func fallingElapsed(m model) time.Duration {
+	switch {
+	case m.fallingReadyFrames > 0:
+		return 0
+	case m.fallingGameOver:
+		return m.fallingEndTime.Sub(m.fallingStartTime)
+	case m.fallingPaused:
+		return m.fallingPausedAt.Sub(m.fallingStartTime)
+	}
+	return time.Since(m.fallingStartTime)
+}
+
+// fallingFrame advances the aliens by one frame's worth of falling.
+func fallingFrame(m model) model {
+	m.fallingFrames++
+	if m.fallingFreezeTicks > 0 || invadersGame(m) {
+		return m // formations only move on ticks
+	}
+	step := m.fallingSpeed * frameInterval.Seconds()
+	for i := range m.fallingWords {
+		m.fallingWords[i].y += step
+	}
+	return driftAliens(m)
+}
+
+// fallingTick runs one game tick: everything except the aliens' movement.
 func fallingTick(m model) model {
 	m.fallingTicks++
+	if m.fallingTypoFlash > 0 {
+		m.fallingTypoFlash--
+	}
 
-	for i := range m.fallingWords {
-		m.fallingWords[i].y += m.fallingSpeed
+	frozen := m.fallingFreezeTicks > 0
+	if frozen {
+		m.fallingFreezeTicks--
+		m.fallingFrozenTicks++
 	}
 
 	// Tick down explosions
 	var activeExplosions []explosion
 	for _, e := range m.explosions {
-		e.ticks--
+		if e.delay > 0 {
+			e.delay--
+		} else {
+			e.ticks--
+		}
+		if e.small && explodeDuration-e.ticks > 1 {
+			continue
+		}
 		if e.ticks > 0 {
 			activeExplosions = append(activeExplosions, e)
 		}
 	}
 	m.explosions = activeExplosions
 
-	if m.laser != nil {
-		m.laser.ticks--
-		if m.laser.ticks <= 0 {
-			m.laser = nil
+	var popups []scorePopup
+	for _, p := range m.scorePopups {
+		if p.delay > 0 {
+			p.delay--
+		} else {
+			p.ticks--
+		}
+		if p.ticks > 0 {
+			popups = append(popups, p)
 		}
 	}
+	m.scorePopups = popups
 
-	// Check for words hitting the shield
-	playHeight := m.height - 6
-	if playHeight < 5 {
-		playHeight = 5
+	// Nothing spawns during the break between waves
+	if m.fallingWaveBreak > 0 {
+		m.fallingWaveBreak--
+		if m.fallingWaveBreak == 0 {
+			m = startWave(m, m.fallingWave+1)
+		}
+		return m
+	}
+
+	m = warnNearShield(m)
+
+	if invadersGame(m) {
+		return formationTick(m)
 	}
 
+	// Check for words hitting the shield
+	playHeight := fallingPlayHeight(m)
+
 	var survived []fallingWord
-	targetWord := ""
+	// Remember the target by ID, not by word: two aliens can carry the same
+	// word, and the index shifts as leaked aliens are removed.
+	targetID := -1
 	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
-		targetWord = m.fallingWords[m.fallingTarget].word
+		targetID = m.fallingWords[m.fallingTarget].id
 	}
 
 	for _, fw := range m.fallingWords {
-		if int(fw.y) >= playHeight {
+		// A doomed alien waits at the shield for its shot
+		if int(fw.y) >= playHeight && !fw.doomed {
+			if fw.decoy {
+				// Floats through the shield, harmlessly
+				if fw.active {
+					m.fallingInput = nil
+					targetID = -1
+				}
+				continue
+			}
+			m = chargeLeak(m, fw)
 			m.fallingLives--
+			m.fallingLeaked++
+			m.fallingStreak = 0
+			m.fallingWaveLeaked++
+			m.fallingCombo = 0
 			if fw.active {
 				m.fallingInput = nil
-				targetWord = ""
+				targetID = -1
 			}
 			if m.fallingLives <= 0 {
 				m.fallingLives = 0
-				m.fallingGameOver = true
-				m = calculateFallingResults(m)
-				return m
+				return endFallingGame(m)
 			}
 		} else {
 			survived = append(survived, fw)
@@ -237,9 +709,9 @@ func fallingTick(m model) model {
 	m.fallingWords = survived
 
 	m.fallingTarget = -1
-	if targetWord != "" {
+	if targetID != -1 {
 		for i, fw := range m.fallingWords {
-			if fw.active && fw.word == targetWord {
+			if fw.id == targetID {
 				m.fallingTarget = i
 				break
 			}
@@ -249,21 +721,64 @@ func fallingTick(m model) model {
 		}
 	}
 
+	if timeUp(m) {
+		m.fallingWon = true
+		return endFallingGame(m)
+	}
+
+	if m.waves && waveCleared(m) {
+		return finishWave(m)
+	}
+
+	// A freeze also halts spawning and the difficulty ramp, which runs on
+	// unfrozen ticks only. In the waves format difficulty steps up per wave.
+	if frozen {
+		return m
+	}
+	activeTicks := m.fallingTicks - m.fallingFrozenTicks
+	if m.waves {
+		activeTicks = (m.fallingWave - 1) * waveRampTicks
+	}
+	if timedGame(m) {
+		activeTicks = timedRampTicks(m, activeTicks)
+	}
+
 	m.fallingSpawnCD--
-	if m.fallingSpawnCD <= 0 {
+	if m.fallingSpawnCD <= 0 && (!m.waves || m.fallingWaveSpawned < waveSize(m.fallingWave)) {
 		m = spawnFallingWord(m)
-		m.fallingSpawnCD = fallingSpawnInterval(m.fallingTicks)
+		m.fallingSpawnCD = fallingSpawnInterval(activeTicks)
 	}
 
-	m.fallingSpeed = fallingSpeedForTick(m.fallingTicks)
+	m.fallingSpeed = fallingSpeedForTick(activeTicks)
 
 	return m
 }
 
+// warnNearShield flags each alien the first time its word row comes
+// within dangerRows of the shield.
+func warnNearShield(m model) model {
+	danger := fallingPlayHeight(m) - dangerRows
+	for i := range m.fallingWords {
+		fw := &m.fallingWords[i]
+		if !fw.warned && !fw.decoy && int(fw.y) >= danger {
+			fw.warned = true
+			m.fallingWarnings++
+		}
+	}
+	return m
+}
+
+// endFallingGame ends the game once the last life is lost.
+func endFallingGame(m model) model {
+	m.fallingGameOver = true
+	m.fallingEndTime = time.Now()
+	return calculateFallingResults(m)
+}
+
 // wordCenter returns the screen column of the word's center for turret targeting.
 func wordCenter(fw fallingWord) int {
-	art := buildAlienArt(fw.word)
-	return fw.x + art.wordCol + art.wordLen/2
+	art := fw.art()
+	return fw.x + art.wordCol + art.wordWidth/2
 }
 
 func overlapsExisting(m model, art builtAlien, x int) bool {
@@ -274,7 +789,7 @@ func overlapsExisting(m model, art builtAlien, x int) bool {
 		if fw.y > 5 {
 			continue
 		}
-		existArt := buildAlienArt(fw.word)
+		existArt := fw.art()
 		existLeft := fw.x
 		existRight := fw.x + existArt.width
 
@@ -286,15 +801,33 @@ func overlapsExisting(m model, art builtAlien, x int) bool {
 }
 
 func spawnFallingWord(m model) model {
-	var word string
-	if m.contentMode == modeQuotes {
-		allWords := getQuoteWords(50)
-		word = allWords[rand.Intn(len(allWords))]
-	} else {
-		word = commonWords[rand.Intn(len(commonWords))]
+	word, ok := pickFallingWord(m)
+	if !ok {
+		m.fallingSpawnCD = 3
+		return m
 	}
 
-	art := buildAlienArt(word)
+	decoy := false
+	if m.rng.Intn(decoyChance) == 0 {
+		if w, ok := pickDecoyWord(m); ok {
+			word, decoy = w, true
+		}
+	}
+
+	power := powerNone
+	if !decoy && m.rng.Intn(powerUpChance) == 0 {
+		power = powerUp(1 + m.rng.Intn(3))
+	}
+
+	armored := !decoy && power == powerNone && spawnsArmored(m, word)
+
+	art := buildAlienArt(word, power)
+	switch {
+	case decoy:
+		art = buildDecoyArt(word)
+	case armored:
+		art = buildArmoredArt(word)
+	}
 	minX := edgePadding
 	maxX := m.width - art.width - edgePadding
 	if maxX <= minX {
@@ -304,7 +837,7 @@ func spawnFallingWord(m model) model {
 	var x int
 	placed := false
 	for attempt := 0; attempt < 10; attempt++ {
-		x = rand.Intn(maxX-minX) + minX
+		x = m.rng.Intn(maxX-minX) + minX
 		if !overlapsExisting(m, art, x) {
 			placed = true
 			break
@@ -316,34 +849,148 @@ func spawnFallingWord(m model) model {
 		return m
 	}
 
+	m.fallingNextID++
+	m.fallingWaveSpawned++
 	m.fallingWords = append(m.fallingWords, fallingWord{
-		word: word,
-		x:    x,
-		y:    0,
+		id:      m.fallingNextID,
+		word:    word,
+		x:       x,
+		fx:      float64(x),
+		vx:      driftVelocity(m),
+		y:       0,
+		power:   power,
+		decoy:   decoy,
+		armored: armored,
+		sprite:  art,
 	})
+	m.fallingPeakAliens = max(m.fallingPeakAliens, len(m.fallingWords))
 	return m
 }
 
-func handleFallingKey(m model, msg tea.KeyMsg) (model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEsc:
-		m.state = stateMenu
-		return m, nil
+// pickFallingWord chooses the next alien's word. A word whose alien
+// wouldn't fit between the edges is passed over for another pick, and
+// after a few misses for the shortest word on offer; false means none fit.
+func pickFallingWord(m model) (string, bool) {
+	var pool []string
+	pick := func() string { return pool[m.rng.Intn(len(pool))] }
+	switch m.contentMode {
+	case modeQuotes:
+		pool = getQuoteWords(m.rng, allQuotes(m), 50)
+	case modeNumbers:
+		pick = func() string { return randomNumber(m.rng) }
+	case modeCode:
+		// The alien's body adds 4 columns around the token
+		pool = codeTokenPool(max(m.width-2*edgePadding-4, 1))
+	case modeWords:
+		// Big word sets have words whose aliens won't fit; the same 4 columns
+		pool = fittingWords(wordPool(m), max(m.width-2*edgePadding-4, 1))
+		if len(pool) == 0 {
+			pool = wordPool(m)
+		}
+	default:
+		pool = wordPool(m)
+	}
 
-	case tea.KeyTab:
+	for attempt := 0; attempt < 10; attempt++ {
+		if word := pick(); alienFits(m, word) {
+			return word, true
+		}
+	}
+	if word := shortestWord(pool); word != "" && alienFits(m, word) {
+		return word, true
+	}
+	return "", false
+}
+
+// alienFits reports whether word's alien fits between the edge paddings.
+// Power-ups only swap the brackets, so they're the same width.
+func alienFits(m model, word string) bool {
+	return buildAlienArt(word, powerNone).width <= m.width-2*edgePadding
+}
+
+// shortestWord returns the word in pool with the fewest columns.
+func shortestWord(pool []string) string {
+	shortest := ""
+	for _, w := range pool {
+		if shortest == "" || lipgloss.Width(w) < lipgloss.Width(shortest) {
+			shortest = w
+		}
+	}
+	return shortest
+}
+
+// fallingMinWidth is the width falling mode needs: minFallingWidth, or
+// more if even the shortest word in a custom list makes a wider alien.
+func fallingMinWidth(m model) int {
+	if m.contentMode != modeCustom && m.contentMode != modeText {
+		return minFallingWidth
+	}
+	word := shortestWord(wordPool(m))
+	return max(minFallingWidth, buildAlienArt(word, powerNone).width+2*edgePadding)
+}
+
+// aimTurret moves the turret proportionally toward the target's center, by
+// how much of the word has been typed, or straight there with reduced
+// motion. The target must be valid.
+func aimTurret(m model) model {
+	fw := m.fallingWords[m.fallingTarget]
+	targetX := wordCenter(fw)
+	wordLen := len([]rune(fw.word))
+	if m.reduceMotion {
+		m.turretX = targetX
+	} else if wordLen > 0 {
+		progress := float64(len(m.fallingInput)) / float64(wordLen)
+		m.turretX = m.turretStartX + int(progress*float64(targetX-m.turretStartX))
+	}
+	return m
+}
+
+// clearFallingInput empties the input (Ctrl+W, Ctrl+U, Alt+Backspace, or
+// backspacing the last rune) and lets go of the target.
+func clearFallingInput(m model) model {
+	m.fallingInput = m.fallingInput[:0]
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		m.fallingWords[m.fallingTarget].active = false
+		m.fallingWords[m.fallingTarget].typed = 0
+	}
+	m.fallingTarget = -1
+	return m
+}
+
+// abandonFallingTarget clears the input on purpose and sends the turret
+// back to the middle of the shield, ready for whatever comes next.
+func abandonFallingTarget(m model) model {
+	if m.fallingTarget >= 0 {
+		m.turretX = fallingPlayWidth(m) / 2
+	}
+	return clearFallingInput(m)
+}
+
+func handleFallingKey(m model, msg tea.KeyMsg) (model, tea.Cmd) {
+	switch {
+	case m.keys.is(msg, actionPause):
+		return pauseFalling(m)
+	case m.keys.is(msg, actionRestart):
 		m = initFallingState(m)
-		return m, fallingTickCmd()
+		return m, startFallingCmd(m)
+	case m.fallingReadyFrames > 0:
+		return m, nil // no typing before the countdown is over
+	}
+	switch msg.Type {
+	case tea.KeyCtrlW, tea.KeyCtrlU:
+		return abandonFallingTarget(m), nil
 
 	case tea.KeyBackspace:
+		if msg.Alt {
+			return abandonFallingTarget(m), nil
+		}
 		if len(m.fallingInput) > 0 {
 			m.fallingInput = m.fallingInput[:len(m.fallingInput)-1]
 			if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
 				m.fallingWords[m.fallingTarget].typed = len(m.fallingInput)
 			}
-			if len(m.fallingInput) == 0 && m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
-				m.fallingWords[m.fallingTarget].active = false
-				m.fallingWords[m.fallingTarget].typed = 0
-				m.fallingTarget = -1
+			if len(m.fallingInput) == 0 {
+				m = clearFallingInput(m)
 			}
 		}
 		return m, nil
@@ -352,80 +999,318 @@ func handleFallingKey(m model, msg tea.KeyMsg) (model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyRunes:
-		char := msg.Runes[0]
-		m.fallingInput = append(m.fallingInput, char)
-
-		if m.fallingTarget == -1 {
-			m.fallingTarget = findTarget(m, char)
-			if m.fallingTarget >= 0 {
-				m.fallingWords[m.fallingTarget].active = true
-				m.fallingWords[m.fallingTarget].typed = 1
-				m.turretStartX = m.turretX
-			}
-		} else if m.fallingTarget < len(m.fallingWords) {
-			m.fallingWords[m.fallingTarget].typed = len(m.fallingInput)
-		}
-
-		// Move turret proportionally toward target center
-		if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
-			fw := m.fallingWords[m.fallingTarget]
-			targetX := wordCenter(fw)
-			wordLen := len([]rune(fw.word))
-			if wordLen > 0 {
-				progress := float64(len(m.fallingInput)) / float64(wordLen)
-				m.turretX = m.turretStartX + int(progress*float64(targetX-m.turretStartX))
+		// Several characters can come at once (see paste.go); spaces
+		// don't type anything here
+		var cmds []tea.Cmd
+		for _, char := range msg.Runes {
+			if unicode.IsSpace(char) {
+				continue
 			}
+			var cmd tea.Cmd
+			m, cmd = typeFallingChar(m, char)
+			cmds = append(cmds, cmd)
 		}
+		return m, tea.Batch(cmds...)
+	}
 
-		if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
-			fw := m.fallingWords[m.fallingTarget]
-			if string(m.fallingInput) == fw.word {
-				centerX := wordCenter(fw)
-				wordRowY := int(fw.y)
+	return m, nil
+}
 
-				playHeight := m.height - 6
-				if playHeight < 5 {
-					playHeight = 5
-				}
+// typeFallingChar types a character at the targeted word, picking a
+// target first if there isn't one.
+func typeFallingChar(m model, char rune) (model, tea.Cmd) {
+	m.fallingInput = append(m.fallingInput, char)
+	m.fallingKeystrokes++
+
+	if m.fallingTarget == -1 {
+		m = lockFallingTarget(m, findTarget(m, string(char)))
+	} else if m.fallingTarget < len(m.fallingWords) {
+		m.fallingWords[m.fallingTarget].typed = len(m.fallingInput)
+		if len(m.fallingInput) <= retargetKeystrokes && !fallingInputMatches(m) {
+			m = retargetFalling(m)
+		}
+	}
 
-				m.laser = &laserBeam{
-					x:     centerX,
-					fromY: playHeight,
-					toY:   wordRowY - 2, // laser reaches the top of the alien
-					ticks: laserDuration,
-				}
-				m.explosions = append(m.explosions, explosion{
-					x:     centerX,
-					y:     wordRowY,
-					ticks: explodeDuration,
-				})
-
-				m.turretX = centerX
-				m.fallingScore++
-				m.fallingCharsTyped += len(fw.word)
-				m.fallingWords = append(m.fallingWords[:m.fallingTarget], m.fallingWords[m.fallingTarget+1:]...)
-				m.fallingTarget = -1
-				m.fallingInput = nil
-				return m, playRandomDestroy()
+	var mistypeCmd tea.Cmd
+	if !fallingInputMatches(m) {
+		m.fallingMistypes++
+		m.fallingCombo = 0
+		m.fallingTypoFlash = mistypeFlashTicks
+		mistypeCmd = keySoundCmd(m, false)
+		if m.strict {
+			// The key never lands, so the input stays a prefix
+			m = rejectFallingKey(m)
+			return m, mistypeCmd
+		}
+	}
+
+	// A completed word's shot leaves from where the turret had got to
+	fromX := m.turretX
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		m = aimTurret(m)
+	}
+
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		fw := &m.fallingWords[m.fallingTarget]
+		if string(m.fallingInput) == fw.word && fw.armored {
+			return stripArmor(m)
+		}
+		if string(m.fallingInput) == fw.word {
+			// The turret snaps on to the target behind the shot
+			m.turretX = wordCenter(*fw)
+			m.fallingTarget = -1
+			m.fallingInput = nil
+			fw.active = false
+			if m.reduceMotion {
+				// No shot to wait for: the alien goes at once
+				m, cmds := destroyAlien(m, fw.id)
+				return m, tea.Batch(cmds...)
 			}
+			fw.doomed = true
+			playHeight := fallingPlayHeight(m)
+			m.shots = append(m.shots, shot{
+				fromX:    fromX,
+				fromY:    playHeight,
+				prevX:    fromX,
+				prevY:    playHeight,
+				x:        fromX,
+				y:        float64(playHeight),
+				targetID: fw.id,
+			})
+			return m, nil
+		}
+	}
+
+	return m, mistypeCmd
+}
+
+// advanceShots moves every shot one frame up. A shot reaching its alien's
+// word row, wherever the alien is now, destroys it; one whose alien is
+// already gone (to a nuke, say) just disappears.
+func advanceShots(m model) (model, []tea.Cmd) {
+	var cmds []tea.Cmd
+	var flying []shot
+	for _, s := range m.shots {
+		i := alienIndex(m, s.targetID)
+		if i < 0 {
+			continue
+		}
+		target := m.fallingWords[i]
+		s.prevX, s.prevY = s.x, int(s.y)
+		s.y -= shotSpeed
+		if s.y > target.y {
+			s.x = shotColumn(s, wordCenter(target), target.y)
+			flying = append(flying, s)
+			continue
+		}
+		var hitCmds []tea.Cmd
+		m, hitCmds = destroyAlien(m, s.targetID)
+		cmds = append(cmds, hitCmds...)
+	}
+	m.shots = flying
+	return m, cmds
+}
+
+// shotColumn is the column where the line from s's turret to (toX, toY)
+// crosses the shot's row.
+func shotColumn(s shot, toX int, toY float64) int {
+	span := float64(s.fromY) - toY
+	if span <= 0 {
+		return toX
+	}
+	t := (float64(s.fromY) - s.y) / span
+	return s.fromX + int(math.Round(t*float64(toX-s.fromX)))
+}
+
+// laserGlyph picks the character for a shot heading dx columns across
+// for every dy rows up.
+func laserGlyph(dx, dy int) rune {
+	switch {
+	case 2*abs(dx) <= dy:
+		return glyphLaser
+	case dx > 0:
+		return glyphLaserRight
+	default:
+		return glyphLaserLeft
+	}
+}
+
+// alienIndex returns the index of the alien with the given ID, or -1.
+func alienIndex(m model, id int) int {
+	for i, fw := range m.fallingWords {
+		if fw.id == id {
+			return i
 		}
+	}
+	return -1
+}
 
+// destroyAlien blows up the alien with the given ID where it is now: the
+// explosion, the combo and points, its power-up, and the sounds to play.
+func destroyAlien(m model, id int) (model, []tea.Cmd) {
+	i := alienIndex(m, id)
+	if i < 0 {
 		return m, nil
 	}
+	fw := m.fallingWords[i]
+	m = addExplosion(m, explosion{
+		x:     wordCenter(fw),
+		y:     int(fw.y),
+		ticks: explodeDuration,
+	})
 
-	return m, nil
+	m.fallingWords = append(m.fallingWords[:i], m.fallingWords[i+1:]...)
+	if m.fallingTarget > i {
+		m.fallingTarget--
+	}
+	if fw.decoy {
+		return shootDecoy(m, fw), []tea.Cmd{playSound(soundHit)}
+	}
+
+	multiplierBefore := comboMultiplier(m.fallingCombo)
+	m.fallingCombo++
+	if m.fallingCombo > m.fallingBestCombo {
+		m.fallingBestCombo = m.fallingCombo
+	}
+	m = awardDestroy(m, fw, 0)
+	m = splitAlien(m, fw)
+	m = applyPowerUp(m, fw.power)
+
+	cmds := []tea.Cmd{playRandomDestroy()}
+	if mult := comboMultiplier(m.fallingCombo); mult > multiplierBefore {
+		// Each step up the combo plays a little higher
+		cmds = append(cmds, playSoundPitched(soundClick, 1+0.25*float64(mult)))
+	}
+	return m, cmds
 }
 
-func findTarget(m model, firstChar rune) int {
+// rejectFallingKey takes back the rune just typed, letting go of the
+// target if that rune was what picked it.
+func rejectFallingKey(m model) model {
+	m.fallingInput = m.fallingInput[:len(m.fallingInput)-1]
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		if len(m.fallingInput) == 0 {
+			return clearFallingInput(m)
+		}
+		m.fallingWords[m.fallingTarget].typed = len(m.fallingInput)
+	}
+	return m
+}
+
+// comboMultiplier is the points multiplier for a streak of destroys.
+func comboMultiplier(combo int) int {
+	mult := 1 + combo/comboStep
+	if mult > maxMultiplier {
+		mult = maxMultiplier
+	}
+	return mult
+}
+
+// destroyPoints is what destroying fw is worth before the combo
+// multiplier: pointsPerChar for each character of its word, and
+// earlyBonusPercent more if it's still in the top third of the playfield.
+func destroyPoints(m model, fw fallingWord) int {
+	points := utf8.RuneCountInString(fw.word) * pointsPerChar
+	if fw.y < float64(fallingPlayHeight(m))/3 {
+		points += points * earlyBonusPercent / 100
+	}
+	return points
+}
+
+// awardDestroy credits a destroyed alien: its points at the current combo
+// multiplier (and a bonus under a shooting star or in thick fog), shown in
+// a popup after delay ticks, plus the word and its characters for the
+// stats.
+func awardDestroy(m model, fw fallingWord, delay int) model {
+	points := destroyPoints(m, fw) * comboMultiplier(m.fallingCombo)
+	if shootingStarVisible(m) {
+		points += shootingStarBonus
+	}
+	if inThickFog(m) {
+		points += fogBonus
+	}
+	m.fallingScore += points
+	m.scorePopups = append(m.scorePopups, scorePopup{
+		x:     wordCenter(fw),
+		y:     int(fw.y) - 2,
+		text:  fmt.Sprintf("+%d", points),
+		ticks: popupDuration,
+		delay: delay,
+	})
+	m.fallingDestroyed++
+	m.fallingWaveDestroyed++
+	m.fallingStreak++
+	m.fallingBestStreak = max(m.fallingBestStreak, m.fallingStreak)
+	if utf8.RuneCountInString(fw.word) > utf8.RuneCountInString(m.fallingLongestWord) {
+		m.fallingLongestWord = fw.word
+	}
+	m.fallingCharsTyped += utf8.RuneCountInString(fw.word)
+	return m
+}
+
+// chargeLeak takes the points for a leaked alien off the score, which
+// never goes below zero.
+func chargeLeak(m model, fw fallingWord) model {
+	lost := min(utf8.RuneCountInString(fw.word)*leakPointsPerChar, m.fallingScore)
+	m.fallingScore -= lost
+	m.fallingLeakPoints += lost
+	return m
+}
+
+// addExplosion starts an explosion animation. With reduced motion there
+// is none: the alien just disappears.
+func addExplosion(m model, e explosion) model {
+	if !m.reduceMotion {
+		m.explosions = append(m.explosions, e)
+	}
+	return m
+}
+
+// applyPowerUp triggers the effect of a destroyed power-up alien.
+func applyPowerUp(m model, power powerUp) model {
+	switch power {
+	case powerFreeze:
+		m.fallingFreezeTicks = freezeDuration
+	case powerNuke:
+		// Every alien left on screen goes up, one explosion per tick;
+		// decoys are spared
+		m = clearFallingInput(m)
+		var spared []fallingWord
+		delay := 0
+		for _, fw := range m.fallingWords {
+			if fw.decoy {
+				spared = append(spared, fw)
+				continue
+			}
+			delay++
+			m = addExplosion(m, explosion{
+				x:     wordCenter(fw),
+				y:     int(fw.y),
+				ticks: explodeDuration,
+				delay: delay,
+			})
+			m = awardDestroy(m, fw, delay)
+		}
+		m.fallingWords = spared
+	case powerHeart:
+		if m.fallingLives < m.lives {
+			m.fallingLives++
+		}
+	}
+	return m
+}
+
+// findTarget returns the lowest alien not already targeted whose word
+// starts with prefix, or -1.
+func findTarget(m model, prefix string) int {
 	bestIdx := -1
 	bestY := -1.0
 
 	for i, fw := range m.fallingWords {
-		if fw.active {
+		if fw.active || fw.doomed {
 			continue
 		}
-		runes := []rune(fw.word)
-		if len(runes) > 0 && runes[0] == firstChar && fw.y > bestY {
+		if fw.word != "" && strings.HasPrefix(fw.word, prefix) && fw.y > bestY {
 			bestY = fw.y
 			bestIdx = i
 		}
@@ -433,42 +1318,99 @@ func findTarget(m model, firstChar rune) int {
 	return bestIdx
 }
 
+// lockFallingTarget makes alien i the target of what's been typed so far.
+// The turret glides to it from wherever it is now.
+func lockFallingTarget(m model, i int) model {
+	m.fallingTarget = i
+	if i >= 0 {
+		m.fallingWords[i].active = true
+		m.fallingWords[i].typed = len(m.fallingInput)
+		m.turretStartX = m.turretX
+	}
+	return m
+}
+
+// retargetFalling switches to another alien when the keys typed so far
+// match it but not the current target: the first key picks the lowest
+// alien with that letter, and the next one can still change the choice
+// when two words share a first letter. Without a better match the target
+// stays, and the key counts as a mistype.
+func retargetFalling(m model) model {
+	i := findTarget(m, string(m.fallingInput))
+	if i < 0 {
+		return m
+	}
+	m.fallingWords[m.fallingTarget].active = false
+	m.fallingWords[m.fallingTarget].typed = 0
+	return lockFallingTarget(m, i)
+}
+
 func handleGameOverKey(m model, msg tea.KeyMsg) (model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyTab, tea.KeyEnter:
+	switch {
+	case m.keys.is(msg, actionRestart) || msg.Type == tea.KeyEnter:
 		m = initFallingState(m)
-		return m, fallingTickCmd()
-	case tea.KeyEsc:
+		return m, startFallingCmd(m)
+	case m.keys.is(msg, actionBack):
 		m.state = stateMenu
 		return m, nil
 	}
+	if msg.String() == "e" {
+		return m, exportCmd(m)
+	}
 	return m, nil
 }
 
+// fallingInputMatches reports whether the input so far is a prefix of the
+// targeted word. With no target, any input is a miss.
+func fallingInputMatches(m model) bool {
+	if m.fallingTarget < 0 || m.fallingTarget >= len(m.fallingWords) {
+		return false
+	}
+	return strings.HasPrefix(m.fallingWords[m.fallingTarget].word, string(m.fallingInput))
+}
+
+// minFallingWPMSeconds keeps very short runs from reporting absurd WPM.
+const minFallingWPMSeconds = 10
+
 func calculateFallingResults(m model) model {
-	elapsed := time.Since(m.fallingStartTime).Seconds()
-	if elapsed < 1 {
-		elapsed = 1
+	elapsed := fallingElapsed(m).Seconds()
+	if elapsed < minFallingWPMSeconds {
+		elapsed = minFallingWPMSeconds
+	}
+	m.correctWords = m.fallingDestroyed
+	m.finalWPM = (float64(m.fallingCharsTyped) / 5.0) / (elapsed / 60.0)
+	m.finalAccuracy = 0
+	if m.fallingKeystrokes > 0 {
+		m.finalAccuracy = float64(m.fallingKeystrokes-m.fallingMistypes) / float64(m.fallingKeystrokes) * 100
 	}
-	m.correctWords = m.fallingScore
 	return m
 }
 
 // --- Difficulty scaling ---
 
+// Falling speeds, in rows per second
+const (
+	fallingBaseSpeed = 2.0
+	fallingSpeedStep = 1.0 / 3 // added every rampStepTicks
+	fallingMaxSpeed  = 10.0
+
+	rampStepTicks = 67 // ~10s between difficulty steps
+)
+
+// fallingSpeedForTick is the falling speed after the given number of game
+// ticks.
 func fallingSpeedForTick(ticks int) float64 {
-	base := 0.3
-	increments := float64(ticks / 67)
-	speed := base + increments*0.05
-	if speed > 1.5 {
-		speed = 1.5
+	increments := float64(ticks / rampStepTicks)
+	speed := fallingBaseSpeed + increments*fallingSpeedStep
+	if speed > fallingMaxSpeed {
+		speed = fallingMaxSpeed
 	}
 	return speed
 }
 
 func fallingSpawnInterval(ticks int) int {
 	base := 20
-	reduction := ticks / 67
+	reduction := ticks / rampStepTicks
 	interval := base - reduction*2
 	if interval < 7 {
 		interval = 7
@@ -478,38 +1420,54 @@ func fallingSpawnInterval(ticks int) int {
 
 // --- Rendering ---
 
-func renderShieldWithStyle(width int, lives int, turretX int, sShield, sShieldDmg, sHint lipgloss.Style) string {
+// shieldDamage is how battered the shield looks with lives of maxLives
+// left: 0 intact, 1 cracked, 2 crumbling, 3 gone. The first half of the
+// lives lost crack it and the rest crumble it, whatever the starting lives.
+func shieldDamage(lives, maxLives int) int {
+	switch {
+	case lives <= 0:
+		return 3
+	case lives >= maxLives:
+		return 0
+	case lives*2 > maxLives:
+		return 1
+	}
+	return 2
+}
+
+func renderShieldWithStyle(width int, lives, maxLives int, turretX int, sShield, sShieldDmg, sHint lipgloss.Style) string {
 	if width < 4 {
 		width = 4
 	}
+	damage := shieldDamage(lives, maxLives)
 
 	var shield []rune
-	switch lives {
-	case 3:
-		shield = []rune(strings.Repeat("█", width))
-	case 2:
-		shield = []rune(strings.Repeat("█", width))
+	switch damage {
+	case 0:
+		shield = []rune(strings.Repeat(string(glyphShield), width))
+	case 1:
+		shield = []rune(strings.Repeat(string(glyphShield), width))
 		for _, pos := range []int{width / 4, width / 2, width * 3 / 4} {
 			if pos < len(shield) {
-				shield[pos] = '░'
+				shield[pos] = glyphShieldBroken
 			}
 		}
-	case 1:
+	case 2:
 		shield = make([]rune, width)
 		for i := range shield {
 			if i%3 == 0 {
-				shield[i] = '░'
+				shield[i] = glyphShieldBroken
 			} else if i%5 == 0 {
 				shield[i] = ' '
 			} else {
-				shield[i] = '▒'
+				shield[i] = glyphShieldWorn
 			}
 		}
 	default:
 		shield = make([]rune, width)
 		for i := range shield {
 			if i%2 == 0 {
-				shield[i] = '░'
+				shield[i] = glyphShieldBroken
 			} else {
 				shield[i] = ' '
 			}
@@ -527,7 +1485,7 @@ func renderShieldWithStyle(width int, lives int, turretX int, sShield, sShieldDm
 		shield[turretPos-1] = '/'
 	}
 	if turretPos < len(shield) {
-		shield[turretPos] = '▲'
+		shield[turretPos] = glyphTurret
 	}
 	if turretPos+1 < len(shield) {
 		shield[turretPos+1] = '\\'
@@ -538,9 +1496,9 @@ func renderShieldWithStyle(width int, lives int, turretX int, sShield, sShieldDm
 		s := string(ch)
 		if i >= turretPos-1 && i <= turretPos+1 {
 			result.WriteString(sShield.Render(s))
-		} else if lives >= 2 {
+		} else if damage <= 1 {
 			result.WriteString(sShield.Render(s))
-		} else if lives == 1 {
+		} else if damage == 2 {
 			result.WriteString(sShieldDmg.Render(s))
 		} else {
 			result.WriteString(sHint.Render(s))
@@ -549,15 +1507,23 @@ func renderShieldWithStyle(width int, lives int, turretX int, sShield, sShieldDm
 	return result.String()
 }
 
-func viewFalling(m model) string {
-	playHeight := m.height - 6
-	if playHeight < 5 {
-		playHeight = 5
-	}
-	playWidth := m.width
-	if playWidth < 20 {
-		playWidth = 20
+// renderHearts shows the lives left as full hearts and the lives lost as
+// hollow ones (♥ ♥ ♡), so they can be counted without telling colors apart.
+func renderHearts(lives, maxLives int, sLost lipgloss.Style) string {
+	var b strings.Builder
+	for i := 0; i < maxLives; i++ {
+		if i < lives {
+			b.WriteString(styleLife.Render(glyphHeart + " "))
+		} else {
+			b.WriteString(sLost.Render(glyphHeartLost + " "))
+		}
 	}
+	return b.String()
+}
+
+func viewFalling(m model) string {
+	playHeight := fallingPlayHeight(m)
+	playWidth := fallingPlayWidth(m)
 
 	// Compute styles — either dynamic (cycle) or static (default)
 	sUntyped := styleUntyped
@@ -569,14 +1535,40 @@ func viewFalling(m model) string {
 	sStatLabel := styleStatLabel
 	sStatValue := styleStatValue
 	sHighlight := styleHighlight
+	sCorrect := styleCorrect
+	sCursor := styleCursor
+	sCaretUnderline := styleCaretUnderline
+	sLaser := styleLaser
+	sExplosion := styleExplosion
+	sDanger := styleIncorrect
+	sFriend := styleFriend
 	hasCycle := m.dayCycle
 	var cycleBg lipgloss.Color
+	var sRain lipgloss.Style
+	weatherNow, weatherStrength := weatherAt(m.fallingTicks)
 
 	if hasCycle {
 		pal := cycleColors(m.fallingTicks)
+		if m.reduceMotion {
+			pal = calmCycleColors(m.fallingTicks)
+		}
+		if !cycleShiftsBackground() {
+			// The terminal's own background stays; assume it's dark
+			pal.bg = lipgloss.Color(nightBg.toHex())
+		}
+		pal = pal.readable()
+		laser := contrastingFg(styleLaser, pal.bg)
+		explosion := contrastingFg(styleExplosion, pal.bg)
+		danger := contrastingFg(styleIncorrect, pal.bg)
+		friend := contrastingFg(styleFriend, pal.bg)
+		if weatherNow == weatherFog {
+			pal.alien = foggedColor(pal.alien, pal.dim, weatherStrength)
+		}
+		pal = pal.forProfile()
 		cycleBg = pal.bg
 		sUntyped = lipgloss.NewStyle().Foreground(pal.dim)
 		sAlien = lipgloss.NewStyle().Foreground(pal.alien)
+		sRain = lipgloss.NewStyle().Foreground(pal.dim)
 		sAlienActive = lipgloss.NewStyle().Foreground(pal.accent).Bold(true)
 		sShield = lipgloss.NewStyle().Foreground(pal.shield).Bold(true)
 		sShieldDmg = lipgloss.NewStyle().Foreground(pal.dim)
@@ -584,114 +1576,208 @@ func viewFalling(m model) string {
 		sStatLabel = lipgloss.NewStyle().Foreground(pal.hint)
 		sStatValue = lipgloss.NewStyle().Foreground(pal.accent).Bold(true)
 		sHighlight = lipgloss.NewStyle().Foreground(pal.accent)
+		sCorrect = lipgloss.NewStyle().Foreground(pal.text)
+		sCursor = lipgloss.NewStyle().Foreground(pal.bg).Background(pal.accent)
+		sCaretUnderline = lipgloss.NewStyle().Foreground(pal.accent).Underline(true)
+		sLaser = styleLaser.Foreground(profileColor(laser))
+		sExplosion = styleExplosion.Foreground(profileColor(explosion))
+		sDanger = styleIncorrect.Foreground(profileColor(danger))
+		sFriend = styleFriend.Foreground(profileColor(friend))
 	}
 
-	// Build 2D grid
-	grid := make([][]string, playHeight)
-	for row := range grid {
-		grid[row] = make([]string, playWidth)
-		for col := range grid[row] {
-			grid[row][col] = " "
-		}
+	// Build 2D grid, reusing the game's buffers. Every style drawn with is
+	// registered up front.
+	grid := m.fallingGrid
+	if grid == nil {
+		grid = &cellGrid{}
+	}
+	grid.reset(playWidth, playHeight)
+	idLaser := grid.addStyle(sLaser)
+	idExplosion := grid.addStyle(sExplosion)
+	idCorrect := grid.addStyle(sCorrect)
+	idUntyped := grid.addStyle(sUntyped)
+	idAlien := grid.addStyle(sAlien)
+	idAlienActive := grid.addStyle(sAlienActive)
+	idFrozen := grid.addStyle(styleFrozen)
+	idFriend := grid.addStyle(sFriend)
+	idDanger := grid.addStyle(sDanger.Bold(true))
+	idCaretNext := grid.addStyle(fallingCaretStyle(m.caret, true, sCursor, sCaretUnderline, sAlienActive))
+	idCaretRest := grid.addStyle(fallingCaretStyle(m.caret, false, sCursor, sCaretUnderline, sAlienActive))
+	idPower := map[powerUp]int{}
+	for p, s := range powerUpStyles {
+		idPower[p] = grid.addStyle(s)
 	}
 
-	// Draw celestial body (sun or moon)
+	// Draw the night sky and the celestial body (sun or moon)
 	if m.dayCycle {
+		renderStarsOnGrid(grid, m.fallingTicks)
 		body := getCelestialBody(m.fallingTicks, playWidth, playHeight)
-		renderCelestialOnGrid(grid, body, playWidth, playHeight)
+		renderCelestialOnGrid(grid, body)
 	}
 
-	// Draw laser beam
-	if m.laser != nil {
-		col := m.laser.x
-		if col >= 0 && col < playWidth {
-			for row := m.laser.toY; row < m.laser.fromY && row < playHeight; row++ {
-				if row >= 0 {
-					grid[row][col] = styleLaser.Render("│")
-				}
-			}
-		}
+	// Draw laser shots: the streak each covered in its last frame
+	for _, s := range m.shots {
+		ch := laserGlyph(s.x-s.prevX, s.prevY-int(s.y))
+		grid.drawLine(s.prevY, s.prevX, int(s.y), s.x, ch, idLaser)
 	}
 
 	// Draw explosions
 	for _, e := range m.explosions {
+		if e.delay > 0 {
+			continue
+		}
 		phase := explodeDuration - e.ticks
 		particles := explosionParticles(phase)
 		for _, p := range particles {
-			px := e.x + p.dx
-			py := e.y + p.dy
-			if py >= 0 && py < playHeight && px >= 0 && px < playWidth {
-				grid[py][px] = styleExplosion.Render(p.ch)
-			}
+			grid.setString(e.y+p.dy, e.x+p.dx, p.ch, idExplosion)
 		}
 	}
 
-	// Place multi-row alien sprites
-	for _, fw := range m.fallingWords {
-		art := buildAlienArt(fw.word)
-		wordRowY := int(fw.y) // the word row on the grid
-
-		aStyle := sAlien
-		if fw.active {
-			aStyle = sAlienActive
-		}
-
-		for rowIdx, line := range art.lines {
-			gridRow := wordRowY - art.wordRow + rowIdx
-			if gridRow < 0 || gridRow >= playHeight {
+	// Place multi-row alien sprites, the target last so it's never drawn
+	// over by a drifting neighbor
+	for pass := 0; pass < 2; pass++ {
+		for _, fw := range m.fallingWords {
+			if fw.active != (pass == 1) {
 				continue
 			}
+			art := fw.art()
+			wordRowY := int(fw.y) // the word row on the grid
+
+			aStyle := idAlien
+			switch {
+			case fw.active, fw.doomed:
+				aStyle = idAlienActive
+			case fw.warned && (m.reduceMotion || m.fallingTicks%2 == 0):
+				// Flashes on alternate ticks; reduced motion holds it steady
+				aStyle = idDanger
+			case fw.decoy:
+				aStyle = idFriend
+			case m.fallingFreezeTicks > 0:
+				aStyle = idFrozen
+			case fw.power != powerNone:
+				aStyle = idPower[fw.power]
+			}
 
-			for colIdx, ch := range []rune(line) {
-				if ch == ' ' {
-					continue // don't overwrite grid background with spaces
-				}
-				gridCol := fw.x + colIdx
-				if gridCol < 0 || gridCol >= playWidth {
+			for rowIdx, line := range art.lines {
+				gridRow := wordRowY - art.wordRow + rowIdx
+				if gridRow < 0 || gridRow >= playHeight {
 					continue
 				}
 
-				// Is this character part of the word text?
-				if rowIdx == art.wordRow && colIdx >= art.wordCol && colIdx < art.wordCol+art.wordLen {
-					charIdx := colIdx - art.wordCol
-					if fw.active && charIdx < fw.typed {
-						grid[gridRow][gridCol] = styleCorrect.Render(string(ch))
-					} else if fw.active {
-						grid[gridRow][gridCol] = styleCursor.Render(string(ch))
+				// runeIdx picks out the word's characters; colIdx is where
+				// they land, which differs once a wide character is drawn
+				colIdx := 0
+				for runeIdx, ch := range []rune(line) {
+					gridCol := fw.x + colIdx
+					colIdx += runeWidth(ch)
+					if ch == ' ' {
+						continue // don't overwrite grid background with spaces
+					}
+					if gridCol < 0 || gridCol >= playWidth {
+						continue
+					}
+
+					// Is this character part of the word text?
+					if rowIdx == art.wordRow && runeIdx >= art.wordCol && runeIdx < art.wordCol+art.wordLen {
+						charIdx := runeIdx - art.wordCol
+						switch {
+						case fw.doomed, fw.active && charIdx < fw.typed:
+							grid.set(gridRow, gridCol, ch, idCorrect)
+						case fw.active && charIdx == fw.typed:
+							grid.set(gridRow, gridCol, ch, idCaretNext)
+						case fw.active:
+							grid.set(gridRow, gridCol, ch, idCaretRest)
+						case fw.decoy:
+							grid.set(gridRow, gridCol, ch, idFriend)
+						default:
+							grid.set(gridRow, gridCol, ch, idUntyped)
+						}
 					} else {
-						grid[gridRow][gridCol] = sUntyped.Render(string(ch))
+						// Alien decoration character
+						grid.set(gridRow, gridCol, ch, aStyle)
 					}
-				} else {
-					// Alien decoration character
-					grid[gridRow][gridCol] = aStyle.Render(string(ch))
 				}
 			}
 		}
 	}
 
-	// Render grid
-	var lines []string
-	for _, row := range grid {
-		lines = append(lines, strings.Join(row, ""))
+	// Rain goes on last, into whatever sky is left
+	if hasCycle && weatherNow == weatherRain && !m.reduceMotion {
+		renderRainOnGrid(grid, m.fallingTicks, weatherStrength, m.fallingWords, grid.addStyle(sRain))
 	}
-	playField := strings.Join(lines, "\n")
 
-	// Shield with dynamic colors
-	shield := renderShieldWithStyle(playWidth, m.fallingLives, m.turretX, sShield, sShieldDmg, sHint)
+	// Points rise a row every other tick (or hold still with reduced motion)
+	idPopup := grid.addStyle(sHighlight.Bold(true))
+	for _, p := range m.scorePopups {
+		if p.delay > 0 {
+			continue
+		}
+		row := p.y
+		if !m.reduceMotion {
+			row -= (popupDuration - p.ticks) / 2
+		}
+		grid.text(row, p.x-utf8.RuneCountInString(p.text)/2, p.text, idPopup)
+	}
+
+	if m.fallingWaveBreak > 0 {
+		last := m.fallingWaveStats[len(m.fallingWaveStats)-1]
+		grid.overlay(fmt.Sprintf("  wave %d cleared  ", m.fallingWave), playHeight/2-1, grid.addStyle(sHighlight.Bold(true)))
+		if last.bonus > 0 {
+			grid.overlay(fmt.Sprintf("  no leaks  +%d  ", last.bonus), playHeight/2+1, grid.addStyle(sHighlight))
+		} else {
+			grid.overlay("  next wave incoming  ", playHeight/2+1, grid.addStyle(sHint))
+		}
+	}
+
+	if m.fallingReadyFrames > 0 {
+		grid.overlay("  get ready  ", playHeight/2-1, grid.addStyle(sHighlight.Bold(true)))
+		grid.overlay(fmt.Sprintf("  %d  ", readySecondsLeft(m)), playHeight/2+1, grid.addStyle(sHighlight))
+	}
 
-	hearts := styleLife.Render(strings.Repeat("♥ ", m.fallingLives))
-	if m.fallingLives == 0 {
-		hearts = sHint.Render("♥ ♥ ♥")
+	if m.fallingPaused {
+		pauseHint := "paused " + glyphDash + " " + m.keys[actionPause] + " to resume, " + m.keys[actionQuit] + " to quit to menu"
+		if m.confirmingQuit {
+			pauseHint = confirmQuitPrompt
+		}
+		grid.overlay("  "+pauseHint+"  ", playHeight/2, grid.addStyle(sHighlight))
+		grid.overlay("  "+m.keys[actionHelp]+" keys  ", playHeight/2+1, grid.addStyle(sHint))
 	}
+
+	playField := grid.render()
+
+	// Shield with dynamic colors
+	shield := renderShieldWithStyle(playWidth, m.fallingLives, m.lives, m.turretX, sShield, sShieldDmg, sHint)
+
+	hearts := renderHearts(m.fallingLives, m.lives, sHint)
 	scoreText := sStatLabel.Render("score ") + sStatValue.Render(fmt.Sprintf("%d", m.fallingScore))
-	elapsed := time.Since(m.fallingStartTime).Seconds()
+	if best := m.fallingRecords[fallingRecordKey(m)].Score; best > 0 {
+		scoreText += sStatLabel.Render("  best ") + sStatValue.Render(fmt.Sprintf("%d", best))
+	}
+	elapsed := fallingElapsed(m).Seconds()
 	timeText := sStatLabel.Render("time ") + sStatValue.Render(fmt.Sprintf("%.0fs", elapsed))
+	if timedGame(m) {
+		timeText = sStatLabel.Render("left ") + sStatValue.Render(fmt.Sprintf("%ds", timedSecondsLeft(m)))
+	}
 	statusBar := hearts + "  " + scoreText + "  " + timeText
+	if hasWaves(m) {
+		statusBar += "  " + sStatLabel.Render("wave ") + sStatValue.Render(fmt.Sprintf("%d", m.fallingWave))
+	}
+	if mult := comboMultiplier(m.fallingCombo); mult > 1 {
+		statusBar += "  " + sHighlight.Bold(true).Render(fmt.Sprintf("combo x%d", mult))
+	}
+	if m.fallingFreezeTicks > 0 {
+		statusBar += "  " + styleFrozen.Render("frozen")
+	}
+	if emulatingLayout(m) {
+		statusBar += "  " + sHighlight.Render(keyboardLayouts[m.layout].name)
+	}
+	if m.muted {
+		statusBar += "  " + sHint.Render("muted")
+	}
 
-	inputStr := string(m.fallingInput)
-	inputDisplay := sHighlight.Render("> ") + styleCorrect.Render(inputStr) + styleCursor.Render("_")
+	inputDisplay := renderFallingInput(m, sHighlight, sCorrect, sCursor)
 
-	hint := sHint.Render("tab restart  esc menu")
+	hint := sHint.Render(m.keys[actionRestart] + " restart  ctrl+u release  " + m.keys[actionPause] + " pause")
 
 	if m.fallingGameOver {
 		return viewFallingGameOver(m)
@@ -716,6 +1802,57 @@ func viewFalling(m model) string {
 	return content
 }
 
+// renderFallingInput draws the input line: what's typed, with anything
+// past the last character that still matches the target in the error
+// style. The prompt flashes in the error style for a moment after a
+// mistype.
+func renderFallingInput(m model, sPrompt, sCorrect, sCursor lipgloss.Style) string {
+	if m.fallingTypoFlash > 0 {
+		sPrompt = styleIncorrect.Bold(true)
+	}
+	good := 0
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		word := []rune(m.fallingWords[m.fallingTarget].word)
+		for good < len(m.fallingInput) && good < len(word) && m.fallingInput[good] == word[good] {
+			good++
+		}
+	}
+	return sPrompt.Render("> ") +
+		sCorrect.Render(string(m.fallingInput[:good])) +
+		styleMistake.Render(string(m.fallingInput[good:])) +
+		sCursor.Render("_")
+}
+
+// fallingCaretStyle is the style for an untyped character of the targeted
+// word: the next one to type when next is set, otherwise the rest. The
+// block caret keeps the whole remainder highlighted; other carets only
+// mark the next character. There's no spare grid column for a bar, so the
+// bar caret is drawn as an underline here.
+func fallingCaretStyle(caret caretStyle, next bool, cursor, underline, rest lipgloss.Style) lipgloss.Style {
+	switch {
+	case caret == caretBlock:
+		return cursor
+	case next && (caret == caretUnderline || caret == caretBar):
+		return underline
+	}
+	return rest
+}
+
+// contrastingFg is a theme style's foreground kept clear of the cycle's
+// background (see contrasting).
+func contrastingFg(s lipgloss.Style, bg lipgloss.Color) lipgloss.Color {
+	fg, _ := s.GetForeground().(lipgloss.Color)
+	return contrasting(fg, bg)
+}
+
+// runeWidth is the number of terminal columns ch takes up.
+func runeWidth(ch rune) int {
+	if ch >= 0x20 && ch < 0x7f {
+		return 1 // printable ASCII, the common case
+	}
+	return lipgloss.Width(string(ch))
+}
+
 type particle struct {
 	dx, dy int
 	ch     string
@@ -725,18 +1862,18 @@ func explosionParticles(phase int) []particle {
 	switch phase {
 	case 0:
 		return []particle{
-			{0, 0, "✦"},
+			{0, 0, glyphSparkBig},
 		}
 	case 1:
 		return []particle{
-			{0, 0, "◇"},
-			{-1, 0, "✧"}, {1, 0, "✧"},
-			{0, -1, "·"},
+			{0, 0, glyphSparkRing},
+			{-1, 0, glyphSpark}, {1, 0, glyphSpark},
+			{0, -1, glyphDust},
 		}
 	case 2:
 		return []particle{
-			{-2, 0, "·"}, {2, 0, "·"},
-			{-1, -1, "✧"}, {1, -1, "✧"},
+			{-2, 0, glyphDust}, {2, 0, glyphDust},
+			{-1, -1, glyphSpark}, {1, -1, glyphSpark},
 			{-1, 1, "*"}, {1, 1, "*"},
 			{0, 0, " "},
 		}
@@ -750,25 +1887,99 @@ func explosionParticles(phase int) []particle {
 }
 
 func viewFallingGameOver(m model) string {
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, fallingGameOverContent(m))
+}
+
+// fallingGameOverContent is the game over screen's text, unplaced. Its
+// height depends on the game (decoys, waves), so the screen's minimum
+// height comes from it too.
+func fallingGameOverContent(m model) string {
 	gameOver := styleLife.Render("GAME OVER")
+	if m.lives == 1 {
+		gameOver += styleLife.Render(" " + glyphDash + " sudden death")
+	}
+	if m.fallingWon {
+		gameOver = styleHighlight.Bold(true).Render(fmt.Sprintf("SURVIVED %ds", m.timeLimit))
+	}
+	if tag := wordSetTag(m); tag != "" {
+		gameOver += styleHint.Render(" " + glyphDash + " " + wordSetLabel(tag))
+	}
 
 	scoreNum := styleBigWPM.Render(fmt.Sprintf("%d", m.fallingScore))
-	scoreLabel := styleHint.Render(" words destroyed")
+	scoreLabel := styleHint.Render(" points")
+	destroyedStat := styleStatLabel.Render("destroyed    ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingDestroyed))
+	comboStat := styleStatLabel.Render("best combo   ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingBestCombo))
+	streakStat := styleStatLabel.Render("no-leak run  ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingBestStreak))
+	longestStat := styleStatLabel.Render("longest word ") + styleStatValue.Render(m.fallingLongestWord)
+	if m.fallingLongestWord == "" {
+		longestStat = styleStatLabel.Render("longest word ") + styleStatLabel.Render(glyphDash)
+	}
+	peakStat := styleStatLabel.Render("most at once ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingPeakAliens))
 
-	elapsed := time.Since(m.fallingStartTime).Seconds()
+	elapsed := fallingElapsed(m).Seconds()
 	timeStat := styleStatLabel.Render("survived     ") + styleStatValue.Render(fmt.Sprintf("%.0fs", elapsed))
+	if m.newSurvivalRecord {
+		timeStat += "  " + styleHighlight.Bold(true).Render("new record!")
+	} else if m.prevBestSurvived > 0 {
+		timeStat += styleStatLabel.Render("  best ") + styleStatValue.Render(fmt.Sprintf("%ds", m.prevBestSurvived))
+	}
+	wpmStat := styleStatLabel.Render("wpm          ") + styleStatValue.Render(fmt.Sprintf("%.0f", m.finalWPM))
+	accStat := styleStatLabel.Render("accuracy     ") + styleStatValue.Render(fmt.Sprintf("%.1f%%", m.finalAccuracy)) +
+		styleStatLabel.Render(fmt.Sprintf("  %d/%d keys", m.fallingKeystrokes-m.fallingMistypes, m.fallingKeystrokes))
+	leakStat := styleStatLabel.Render("words leaked ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingLeaked))
+	if m.fallingLeakPoints > 0 {
+		leakStat += styleStatLabel.Render(fmt.Sprintf("  -%d points", m.fallingLeakPoints))
+	}
+	livesStat := styleStatLabel.Render("lives        ") + styleStatValue.Render(fmt.Sprintf("%d", m.lives))
+	mistypeStat := styleStatLabel.Render("mistypes     ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingMistypes))
 
-	hint := styleHint.Render("tab/enter restart  esc menu")
+	hint := styleHint.Render(m.keys[actionRestart] + "/enter restart  e export  " + m.keys[actionBack] + " menu")
+	if sync := renderWebhookStatus(m); sync != "" {
+		hint += "  " + sync
+	}
+	if m.flash != "" {
+		hint = renderFlash(m)
+	}
 
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		gameOver,
-		"",
-		scoreNum+scoreLabel,
-		"",
-		timeStat,
-		"",
-		hint,
-	)
+	parts := []string{gameOver, "", scoreNum + scoreLabel}
+	if m.newPersonalBest {
+		parts = append(parts, styleHighlight.Bold(true).Render("new high score!"))
+	}
+	if m.hasPrevBest {
+		parts = append(parts, styleStatLabel.Render("best ")+styleStatValue.Render(fmt.Sprintf("%.0f", m.prevBest)))
+	}
+	parts = append(parts, "", destroyedStat, timeStat, wpmStat, accStat, mistypeStat, leakStat)
+	if m.fallingDecoysShot > 0 {
+		parts = append(parts, styleStatLabel.Render("decoys shot  ")+styleStatValue.Render(fmt.Sprintf("%d", m.fallingDecoysShot)))
+	}
+	parts = append(parts, comboStat, streakStat, longestStat, peakStat, livesStat, renderSeed(m))
+	if hasWaves(m) {
+		parts = append(parts, "")
+		parts = append(parts, renderWaveStats(m)...)
+	}
+	parts = append(parts, "", hint)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// maxWaveStatLines caps the per-wave breakdown on the game over screen.
+const maxWaveStatLines = 8
 
-	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+// renderWaveStats lists how each cleared wave went, most recent last.
+func renderWaveStats(m model) []string {
+	lines := []string{styleStatLabel.Render("reached wave ") + styleStatValue.Render(fmt.Sprintf("%d", m.fallingWave))}
+	start := 0
+	if len(m.fallingWaveStats) > maxWaveStatLines {
+		start = len(m.fallingWaveStats) - maxWaveStatLines
+	}
+	for i := start; i < len(m.fallingWaveStats); i++ {
+		w := m.fallingWaveStats[i]
+		line := styleStatLabel.Render(fmt.Sprintf("  wave %-3d   ", i+1)) +
+			styleStatValue.Render(fmt.Sprintf("%2d", w.destroyed)) + styleStatLabel.Render(" destroyed  ") +
+			styleStatValue.Render(fmt.Sprintf("%d", w.leaked)) + styleStatLabel.Render(" leaked")
+		if w.bonus > 0 {
+			line += "  " + styleHighlight.Render(fmt.Sprintf("+%d", w.bonus))
+		}
+		lines = append(lines, line)
+	}
+	return lines
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newFallingGame is a falling game past its countdown with the given
+// words on screen, none targeted yet.
+func newFallingGame(t testing.TB, words ...string) model {
+	t.Helper()
+	m := newTestModel(t)
+	m.gameMode = gameModeFalling
+	m.reduceMotion = false
+	m = initFallingState(m)
+	m.fallingReadyFrames = 0
+	m.fallingWords = nil
+	for i, w := range words {
+		m.fallingWords = append(m.fallingWords, fallingWord{
+			id: i + 1, word: w, x: 5 + 10*i, fx: float64(5 + 10*i), y: 3, sprite: buildAlienArt(w, powerNone),
+		})
+	}
+	m.fallingNextID = len(words)
+	return m
+}
+
+func TestFallingMultiRuneKey(t *testing.T) {
+	m := newFallingGame(t, "cat", "dog")
+	m, _ = handleFallingKey(m, runesKey("ca"))
+	if m.fallingTarget != 0 || string(m.fallingInput) != "ca" || m.fallingWords[0].typed != 2 {
+		t.Fatalf("after \"ca\": target %d, input %q, typed %d; want 0, \"ca\", 2",
+			m.fallingTarget, string(m.fallingInput), m.fallingWords[0].typed)
+	}
+
+	// The space is skipped; the rest finishes "cat" and starts on "dog"
+	m, _ = handleFallingKey(m, runesKey("t do"))
+	if !m.fallingWords[0].doomed {
+		t.Errorf("\"cat\" wasn't shot")
+	}
+	if m.fallingTarget != 1 || string(m.fallingInput) != "do" {
+		t.Errorf("after \"t do\": target %d, input %q; want 1, \"do\"", m.fallingTarget, string(m.fallingInput))
+	}
+	if m.fallingKeystrokes != 5 || m.fallingMistypes != 0 {
+		t.Errorf("%d keystrokes and %d mistypes, want 5 and 0", m.fallingKeystrokes, m.fallingMistypes)
+	}
+}
+
+func TestFallingMultiRuneKeyMatchesSingleKeys(t *testing.T) {
+	batched := newFallingGame(t, "cat", "dog")
+	batched, _ = handleFallingKey(batched, runesKey("cxg"))
+	single := newFallingGame(t, "cat", "dog")
+	for _, r := range "cxg" {
+		single, _ = handleFallingKey(single, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if string(batched.fallingInput) != string(single.fallingInput) || batched.fallingMistypes != single.fallingMistypes ||
+		batched.fallingTarget != single.fallingTarget {
+		t.Errorf("batched: input %q, %d mistypes, target %d; one at a time: %q, %d, %d",
+			string(batched.fallingInput), batched.fallingMistypes, batched.fallingTarget,
+			string(single.fallingInput), single.fallingMistypes, single.fallingTarget)
+	}
+}
+
+func TestFallingClearDropsTarget(t *testing.T) {
+	for _, key := range clearWordKeys {
+		m := newFallingGame(t, "cat", "dog")
+		m, _ = handleFallingKey(m, runesKey("ca"))
+		if m.fallingTarget != 0 {
+			t.Fatalf("\"ca\" didn't target \"cat\"")
+		}
+		m, _ = handleFallingKey(m, key)
+		if m.fallingTarget != -1 || len(m.fallingInput) != 0 {
+			t.Errorf("%s: target %d, input %q; want no target and no input", key, m.fallingTarget, string(m.fallingInput))
+		}
+		if w := m.fallingWords[0]; w.active || w.typed != 0 {
+			t.Errorf("%s left \"cat\" active %v with %d typed", key, w.active, w.typed)
+		}
+		// With nothing targeted, the next key picks afresh
+		m, _ = handleFallingKey(m, runesKey("d"))
+		if m.fallingTarget != 1 {
+			t.Errorf("after %s, \"d\" targeted %d, want 1", key, m.fallingTarget)
+		}
+	}
+}
+
+// wideWords have characters that take more than a byte, or more than a
+// column, and lengths that pick each of the alien bodies.
+var wideWords = []string{"cat", "café", "naïve", "日本語", "東京タワー", "crème brûlée", "résumé"}
+
+func TestAlienArtWidth(t *testing.T) {
+	for _, word := range wideWords {
+		for _, power := range []powerUp{powerNone, powerFreeze, powerNuke, powerHeart} {
+			art := buildAlienArt(word, power)
+			for i, line := range art.lines {
+				if w := lipgloss.Width(line); w != art.width {
+					t.Errorf("%q (power %d) row %d %q is %d wide, want %d", word, power, i, line, w, art.width)
+				}
+			}
+			if art.wordWidth != lipgloss.Width(word) || art.wordLen != len([]rune(word)) {
+				t.Errorf("%q: word width %d and length %d, want %d and %d",
+					word, art.wordWidth, art.wordLen, lipgloss.Width(word), len([]rune(word)))
+			}
+			body := []rune(art.lines[art.wordRow])
+			if got := string(body[art.wordCol : art.wordCol+art.wordLen]); got != word {
+				t.Errorf("%q: the body row has %q at the word's column", word, got)
+			}
+		}
+	}
+}
+
+func TestNarrowFallingKeepsAliensOnScreen(t *testing.T) {
+	m := newFallingGame(t)
+	m.width = 30
+	m.contentMode = modeCustom
+	m.customWords = []string{"accomplishment"}
+
+	// Every alien that spawns, armored or not, is between the edges
+	m.fallingSpeed = armorMinSpeed
+	for i := 0; i < 200; i++ {
+		m.fallingWords = nil
+		m = spawnFallingWord(m)
+		if len(m.fallingWords) != 1 {
+			t.Fatalf("\"accomplishment\" didn't spawn at width %d", m.width)
+		}
+		fw := m.fallingWords[0]
+		if fw.x < edgePadding || fw.x+fw.sprite.width > m.width-edgePadding {
+			t.Fatalf("%q spawned across columns %d-%d of %d", fw.word, fw.x, fw.x+fw.sprite.width, m.width)
+		}
+	}
+
+	// Narrower still, it's passed over for a word that fits, or for none
+	m.width = 20
+	m.customWords = []string{"accomplishment", "cat"}
+	for i := 0; i < 200; i++ {
+		if word, ok := pickFallingWord(m); !ok || word != "cat" {
+			t.Fatalf("picked %q (%v) at width %d", word, ok, m.width)
+		}
+	}
+	m.customWords = []string{"accomplishment"}
+	m.fallingWords = nil
+	if m = spawnFallingWord(m); len(m.fallingWords) != 0 {
+		t.Errorf("spawned %q at width %d", m.fallingWords[0].word, m.width)
+	}
+}
+
+// benchFallingGame is a falling game on a 200x50 terminal with 15 aliens
+// spread over it, the first one partly typed.
+func benchFallingGame(b *testing.B) model {
+	m := newFallingGame(b)
+	m.width, m.height = 200, 50
+	m = initFallingState(m)
+	m.fallingReadyFrames = 0
+	m.fallingWords = nil
+	for i, w := range commonWords[:15] {
+		x := edgePadding + (i%5)*38
+		m.fallingWords = append(m.fallingWords, fallingWord{
+			id: i + 1, word: w, x: x, fx: float64(x), y: float64(2 + (i/5)*12), sprite: buildAlienArt(w, powerNone),
+		})
+	}
+	m.fallingNextID = len(m.fallingWords)
+	m, _ = handleFallingKey(m, runesKey(m.fallingWords[0].word[:1]))
+	return m
+}
+
+func BenchmarkViewFalling(b *testing.B) {
+	m := benchFallingGame(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	var frame string
+	for i := 0; i < b.N; i++ {
+		frame = viewFalling(m)
+	}
+	b.ReportMetric(float64(len(frame)), "bytes/frame")
+}
+
+func BenchmarkFallingTick(b *testing.B) {
+	m := benchFallingGame(b)
+	words := m.fallingWords
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Each tick starts from the same 15 aliens
+		b.StopTimer()
+		m.fallingWords = append(m.fallingWords[:0:0], words...)
+		b.StartTimer()
+		m = fallingTick(m)
+	}
+}
+
+func TestFallingGameOverFitsItsMinimumSize(t *testing.T) {
+	m := newFallingGame(t)
+	m.waves = true
+	m.fallingWave = 6
+	m.fallingWaveStats = make([]waveStat, 5)
+	m.fallingGameOver = true
+	m.width, m.height = minFallingWidth, minFallingHeight
+	if !screenTooSmall(m) {
+		t.Fatalf("the game over screen with waves fits in %dx%d", m.width, m.height)
+	}
+	m.width, m.height = minScreenSize(m)
+	view := m.View()
+	if lines := strings.Split(view, "\n"); len(lines) > m.height {
+		t.Errorf("at its minimum of %dx%d the screen is %d lines", m.width, m.height, len(lines))
+	}
+	if !strings.Contains(view, "GAME OVER") || !strings.Contains(view, "restart") {
+		t.Errorf("at %dx%d the screen lost its title or hint:\n%s", m.width, m.height, view)
+	}
+}
+
+func TestFallingMinWidthFitsShortestCustomWord(t *testing.T) {
+	m := newFallingGame(t)
+	m.contentMode = modeCustom
+	m.customWords = []string{"pneumonoultramicroscopicsilicovolcanoconiosis", "supercalifragilisticexpialidocious"}
+	want := buildAlienArt("supercalifragilisticexpialidocious", powerNone).width + 2*edgePadding
+	if want <= minFallingWidth {
+		t.Fatalf("the shortest word needs %d columns, which the fixed minimum of %d covers", want, minFallingWidth)
+	}
+	if got := fallingMinWidth(m); got != want {
+		t.Errorf("fallingMinWidth = %d, want %d for the shortest word's alien", got, want)
+	}
+
+	m.fallingMinWidth = fallingMinWidth(m)
+	m.height = minFallingHeight
+	for _, tt := range []struct {
+		width    int
+		tooSmall bool
+	}{{minFallingWidth, true}, {want - 1, true}, {want, false}} {
+		m.width = tt.width
+		if got := screenTooSmall(m); got != tt.tooSmall {
+			t.Errorf("at width %d, too small is %v, want %v", tt.width, got, tt.tooSmall)
+		}
+	}
+}
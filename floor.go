@@ -0,0 +1,112 @@
+package main
+
+// Fail floors (settings screen): a minimum WPM and a minimum accuracy a
+// classic test has to stay above. Falling below either ends the test on
+// the spot as failed, and the results say which one and what it fell to.
+//
+// Speed is the raw WPM of the last floorWindow seconds, every key typed,
+// so a slow start is forgotten once you pick up. Accuracy is over the
+// whole test so far, counting only what's been typed of the current word.
+// Neither is checked until floorWindow seconds in, when there's enough
+// typing for them to mean something, and pauses don't count.
+//
+// The check runs on its own one-second tick, since word-count tests have
+// no clock of their own to hang it on.
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const floorWindow = 5 // seconds
+
+// minWPMLevels and minAccuracyLevels are the settings screen's floors;
+// 0 is off.
+var (
+	minWPMLevels      = []int{0, 30, 40, 60, 80, 100}
+	minAccuracyLevels = []int{0, 90, 95, 98}
+)
+
+type floorTickMsg struct {
+	id int
+}
+
+func floorTickCmd(m model) tea.Cmd {
+	if m.minWPM <= 0 && m.minAccuracy <= 0 {
+		return nil
+	}
+	id := m.floorTickID
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return floorTickMsg{id: id}
+	})
+}
+
+// rollingWPM is the raw WPM of the last floorWindow full seconds. ok is
+// false until the test has run that long.
+func rollingWPM(m model) (wpm float64, ok bool) {
+	full := int(typingElapsed(m).Seconds())
+	if full < floorWindow {
+		return 0, false
+	}
+	keys := 0
+	for sec := full - floorWindow; sec < full && sec < len(m.keystrokes); sec++ {
+		keys += m.keystrokes[sec]
+	}
+	return float64(keys) / 5 / (floorWindow / 60.0), true
+}
+
+// liveAccuracy is the accuracy of everything typed so far, as the results
+// count it, except that the rest of the current word isn't a mistake yet.
+func liveAccuracy(m model) (accuracy float64, ok bool) {
+	correct, total := 0, 0
+	for i := 0; i <= m.wordIndex && i < len(m.words); i++ {
+		typed, target := m.input[i], []rune(m.words[i])
+		n := len(target)
+		if i == m.wordIndex {
+			n = min(len(typed), n)
+		}
+		for j := 0; j < n; j++ {
+			total++
+			if j < len(typed) && typed[j] == target[j] {
+				correct++
+			}
+		}
+		if len(typed) > len(target) {
+			total += len(typed) - len(target)
+		}
+		if i < m.wordIndex {
+			total++
+			correct++
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(correct) / float64(total) * 100, true
+}
+
+// checkFloors ends the test as failed if it has dropped below a floor.
+func checkFloors(m model) (model, tea.Cmd) {
+	if m.paused || int(typingElapsed(m).Seconds()) < floorWindow {
+		return m, nil
+	}
+	if wpm, ok := rollingWPM(m); ok && m.minWPM > 0 && wpm < float64(m.minWPM) {
+		m.failReason = fmt.Sprintf("wpm dropped to %.0f", wpm)
+		return failTyping(m)
+	}
+	if acc, ok := liveAccuracy(m); ok && m.minAccuracy > 0 && acc < float64(m.minAccuracy) {
+		m.failReason = fmt.Sprintf("accuracy dropped to %.1f%%", acc)
+		return failTyping(m)
+	}
+	return m, nil
+}
+
+// floorName names a floor level for the settings screen.
+func floorName(level int, unit string) string {
+	if level <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d%s", level, unit)
+}
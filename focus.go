@@ -0,0 +1,54 @@
+package main
+
+// Pausing on focus loss. The program asks the terminal for focus events
+// (tea.WithReportFocus); with "blur" turned on in settings, switching away
+// from the terminal pauses a classic test or falling game in progress, and
+// switching back resumes it. A countdown starts over instead.
+//
+// Pausing uses the normal pause, so time spent away is left out of every
+// elapsed-time stat. A game the player paused themselves stays paused on
+// focus. Terminals that don't send focus events just never trigger it,
+// which is why it's a setting.
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// handleFocus reacts to the terminal gaining (focused) or losing focus.
+func handleFocus(m model, focused bool) (model, tea.Cmd) {
+	// A race goes on regardless (see race.go)
+	if !m.pauseOnBlur || racing(m) {
+		return m, nil
+	}
+	if !focused {
+		switch {
+		case m.state == stateTyping && m.countdownLeft > 0:
+			m.countdownID++ // stop counting until focus returns
+			m.blurPaused = true
+			return m, nil
+		case m.state == stateTyping && m.timerStarted && !m.paused:
+			m.blurPaused = true
+			return pauseTyping(m)
+		case m.state == stateFalling && !m.fallingPaused && !m.fallingGameOver:
+			m.blurPaused = true
+			return pauseFalling(m)
+		}
+		return m, nil
+	}
+
+	if !m.blurPaused {
+		return m, nil
+	}
+	m.blurPaused = false
+	if screenTooSmall(m) {
+		return m, nil // stay paused behind the guard screen
+	}
+	switch {
+	case m.state == stateTyping && m.countdownLeft > 0:
+		m = startCountdown(m)
+		return m, countdownTickCmd(m)
+	case m.state == stateTyping && m.paused:
+		return resumeTyping(m)
+	case m.state == stateFalling && m.fallingPaused && !m.fallingGameOver:
+		return resumeFalling(m)
+	}
+	return m, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+// Ghost racing. With the menu's ghost option on, a classic test loads the
+// keystroke log of your best saved run with the same setup (see replay.go)
+// and plays it alongside you: a faded caret marks where that run's cursor
+// was at the same elapsed time.
+//
+// The ghost only follows the run's position — word and character index —
+// so it works even though each test has different words. Its position is
+// advanced on its own tick, several times a second, from the first
+// keypress until the test ends.
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const ghostInterval = 100 * time.Millisecond
+
+type ghostTickMsg struct {
+	id int
+}
+
+// classicSetupEntry is a history entry carrying only the setup of the
+// menu's current classic test, for matching against saved runs.
+func classicSetupEntry(m model) historyEntry {
+	e := historyEntry{
+		Mode:     "classic",
+		Content:  contentModeNames[m.contentMode],
+		Duration: int(m.duration.Seconds()),
+		WordSet:  wordSetTag(m),
+	}
+	if m.contentMode == modeQuotes {
+		e.Length = quoteLengthNames[m.quoteLength]
+	} else if m.testMode == testModeWords {
+		e.WordCount = m.wordCount
+	} else if zenTest(m) {
+		e.Zen = true
+	}
+	return e
+}
+
+// ghostRun returns the best saved run with a keystroke log for the current
+// setup, or nil if there isn't one.
+func ghostRun(m model) *historyEntry {
+	setup := classicSetupEntry(m)
+	var best *historyEntry
+	for i := range m.history {
+		h := &m.history[i]
+		if h.Failed || h.Repeat > 0 || len(h.Keys) == 0 || !sameSetup(*h, setup) {
+			continue
+		}
+		if best == nil || entryScore(*h) > entryScore(*best) {
+			best = h
+		}
+	}
+	return best
+}
+
+// loadGhost sets up the ghost for a fresh test, if the option is on and
+// there's a run to race.
+func loadGhost(m model) model {
+	m.ghostKeys = nil
+	m.ghostWPM = 0
+	m.ghostLens = nil
+	m.ghostWordIndex = 0
+	m.ghostPos = 0
+	m.ghostTickID++
+	if !m.ghost {
+		return m
+	}
+	run := ghostRun(m)
+	if run == nil || len(run.Words) == 0 {
+		return m
+	}
+	m.ghostKeys = run.Keys
+	m.ghostWPM = run.WPM
+	m.ghostLens = make([]int, len(run.Words))
+	return m
+}
+
+// ghostActive reports whether a ghost is racing the current test.
+func ghostActive(m model) bool {
+	return m.state == stateTyping && m.ghostKeys != nil
+}
+
+func ghostTickCmd(m model) tea.Cmd {
+	if m.ghostKeys == nil {
+		return nil
+	}
+	id := m.ghostTickID
+	return tea.Tick(ghostInterval, func(time.Time) tea.Msg {
+		return ghostTickMsg{id: id}
+	})
+}
+
+// advanceGhost applies every logged key up to the current elapsed time,
+// the same way applyReplayKey does, tracking only word lengths.
+func advanceGhost(m model) model {
+	now := typingElapsed(m).Milliseconds()
+	for m.ghostPos < len(m.ghostKeys) && m.ghostKeys[m.ghostPos].At <= now {
+		i := m.ghostWordIndex
+		switch m.ghostKeys[m.ghostPos].Key {
+		case keyBackspace:
+			if m.ghostLens[i] > 0 {
+				m.ghostLens[i]--
+			} else if i > 0 {
+				m.ghostWordIndex--
+			}
+		case keyClearWord:
+			m.ghostLens[i] = 0
+		case keySpace:
+			if i < len(m.ghostLens)-1 {
+				m.ghostWordIndex++
+			}
+		default:
+			m.ghostLens[i]++
+		}
+		m.ghostPos++
+	}
+	return m
+}
+
+// ghostCaretAt reports whether the ghost's caret is on the given character
+// of the given word. The player's own caret takes precedence.
+func ghostCaretAt(m model, wordIdx, charIdx int) bool {
+	if !ghostActive(m) || !m.timerStarted || wordIdx != m.ghostWordIndex {
+		return false
+	}
+	if wordIdx == m.wordIndex && charIdx == len(m.input[wordIdx]) {
+		return false
+	}
+	return charIdx == m.ghostLens[m.ghostWordIndex]
+}
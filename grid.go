@@ -0,0 +1,182 @@
+package main
+
+// cellGrid is the falling mode playfield as plain cells: a rune plus the ID
+// of the style it's drawn in. Rendering joins each run of same-styled
+// cells into a single lipgloss Render call, so a frame costs one escape
+// sequence per run instead of one per character.
+//
+// A game keeps one grid (model.fallingGrid) and reuses its buffers from
+// frame to frame. Styles are registered per frame with addStyle; ID 0 is
+// the unstyled background.
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type cell struct {
+	ch    rune // 0 for the right half of a double-width character
+	style int
+}
+
+type cellGrid struct {
+	width, height int
+	cells         []cell
+	styles        []lipgloss.Style
+	line          []rune // scratch for one run of text
+}
+
+// reset clears the grid to blank cells at the given size, keeping its
+// buffers when they're big enough.
+func (g *cellGrid) reset(width, height int) {
+	g.width, g.height = width, height
+	n := width * height
+	if cap(g.cells) < n {
+		g.cells = make([]cell, n)
+	}
+	g.cells = g.cells[:n]
+	for i := range g.cells {
+		g.cells[i] = cell{ch: ' '}
+	}
+	g.styles = append(g.styles[:0], lipgloss.NewStyle())
+}
+
+// addStyle registers a style for this frame and returns its ID.
+func (g *cellGrid) addStyle(s lipgloss.Style) int {
+	g.styles = append(g.styles, s)
+	return len(g.styles) - 1
+}
+
+func (g *cellGrid) inBounds(row, col int) bool {
+	return row >= 0 && row < g.height && col >= 0 && col < g.width
+}
+
+// blank reports whether (row, col) is in bounds and still empty.
+func (g *cellGrid) blank(row, col int) bool {
+	return g.inBounds(row, col) && g.cells[row*g.width+col] == cell{ch: ' '}
+}
+
+// set draws ch at (row, col) in the given style. A double-width character
+// takes two cells, so the cell after it is marked as its right half; one
+// that would hang off the right edge is drawn as a space instead.
+// Out-of-bounds positions are ignored.
+func (g *cellGrid) set(row, col int, ch rune, style int) {
+	if !g.inBounds(row, col) {
+		return
+	}
+	i := row*g.width + col
+	// Don't leave half of a double-width character behind
+	if g.cells[i].ch == 0 && col > 0 {
+		g.cells[i-1].ch = ' '
+	}
+	if col+1 < g.width && g.cells[i+1].ch == 0 {
+		g.cells[i+1].ch = ' '
+	}
+	if runeWidth(ch) < 2 {
+		g.cells[i] = cell{ch: ch, style: style}
+		return
+	}
+	if col+1 >= g.width {
+		g.cells[i] = cell{ch: ' ', style: style}
+		return
+	}
+	if col+2 < g.width && g.cells[i+2].ch == 0 {
+		g.cells[i+2].ch = ' '
+	}
+	g.cells[i] = cell{ch: ch, style: style}
+	g.cells[i+1] = cell{ch: 0, style: style}
+}
+
+// setString draws the first rune of s; sprite and particle tables hold
+// their characters as strings.
+func (g *cellGrid) setString(row, col int, s string, style int) {
+	for _, ch := range s {
+		g.set(row, col, ch, style)
+		return
+	}
+}
+
+// drawLine draws ch on every cell from (row0, col0) to (row1, col1), stepping
+// with Bresenham's algorithm. Ends off the grid are clamped to its edges.
+func (g *cellGrid) drawLine(row0, col0, row1, col1 int, ch rune, style int) {
+	row0, row1 = min(max(row0, 0), g.height-1), min(max(row1, 0), g.height-1)
+	col0, col1 = min(max(col0, 0), g.width-1), min(max(col1, 0), g.width-1)
+	dCol, dRow := abs(col1-col0), -abs(row1-row0)
+	stepCol, stepRow := 1, 1
+	if col0 > col1 {
+		stepCol = -1
+	}
+	if row0 > row1 {
+		stepRow = -1
+	}
+	err := dCol + dRow
+	for {
+		g.set(row0, col0, ch, style)
+		if row0 == row1 && col0 == col1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dRow {
+			err += dRow
+			col0 += stepCol
+		}
+		if e2 <= dCol {
+			err += dCol
+			row0 += stepRow
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// text writes s starting at col; whatever falls off the grid is dropped.
+func (g *cellGrid) text(row, col int, s string, style int) {
+	for _, ch := range s {
+		g.set(row, col, ch, style)
+		col += runeWidth(ch)
+	}
+}
+
+// overlay writes text centered on the given row, replacing whatever was
+// drawn there.
+func (g *cellGrid) overlay(text string, row int, style int) {
+	col := (g.width - lipgloss.Width(text)) / 2
+	for _, ch := range text {
+		g.set(row, col, ch, style)
+		col += runeWidth(ch)
+	}
+}
+
+// render returns the grid as text, one line per row.
+func (g *cellGrid) render() string {
+	var b strings.Builder
+	for row := 0; row < g.height; row++ {
+		if row > 0 {
+			b.WriteByte('\n')
+		}
+		cells := g.cells[row*g.width : (row+1)*g.width]
+		for start := 0; start < len(cells); {
+			style := cells[start].style
+			g.line = g.line[:0]
+			end := start
+			for ; end < len(cells) && cells[end].style == style; end++ {
+				if ch := cells[end].ch; ch != 0 {
+					g.line = append(g.line, ch)
+				}
+			}
+			if style == 0 {
+				b.WriteString(string(g.line))
+			} else {
+				b.WriteString(g.styles[style].Render(string(g.line)))
+			}
+			start = end
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,266 @@
+package main
+
+// The help panel: "?" on any screen but a test or game being played (where
+// "?" is typed; pause first) shows the keys for that screen in a bordered
+// panel over it, until any key is pressed. Nothing moves meanwhile: play
+// is already paused, and a replay holds its place.
+//
+// Each screen's keys are listed in helpPages, bound actions by their
+// keymap entry and the rest as the keys the screen's handler checks for.
+// The keymap takes its list of keys that can't be rebound from the same
+// pages, so the panel can't tell a different story from the keys.
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// noAction marks a help entry of keys that aren't bound through the keymap.
+const noAction keyAction = -1
+
+// helpEntry is a line of the help panel: an action's bound key and any keys
+// the screen adds to it, and what pressing them does.
+type helpEntry struct {
+	action keyAction
+	keys   []string
+	does   string
+}
+
+func actionEntry(action keyAction, does string, keys ...string) helpEntry {
+	return helpEntry{action: action, keys: keys, does: does}
+}
+
+func keysEntry(does string, keys ...string) helpEntry {
+	return helpEntry{action: noAction, keys: keys, does: does}
+}
+
+// helpPage is a screen's keys. screens says which keymap screens its fixed
+// keys belong to.
+type helpPage struct {
+	title   string
+	screens keyScreens
+	entries []helpEntry
+}
+
+var (
+	helpMenu = helpPage{"menu", onMenu, []helpEntry{
+		keysEntry("move between rows", "up", "k", "down", "j"),
+		keysEntry("change the row's value", "left", "h", "right", "l"),
+		keysEntry("start", "enter"),
+		keysEntry("daily challenge", "d"),
+		keysEntry("next lesson", "n"),
+		keysEntry("weak words practice", "w"),
+		keysEntry("stats", "s"),
+		keysEntry("settings", "o"),
+		actionEntry(actionQuit, "quit cli_typer"),
+	}}
+	helpTyping = helpPage{"typing test", onPlay, []helpEntry{
+		keysEntry("next word", " "),
+		keysEntry("delete a character", "backspace"),
+		keysEntry("clear the word", "ctrl+w", "ctrl+u", "alt+backspace"),
+		actionEntry(actionRestart, "restart"),
+		actionEntry(actionPause, "pause and resume"),
+		actionEntry(actionFinish, "finish a zen test", "ctrl+j"),
+		actionEntry(actionQuit, "quit to the menu, when paused"),
+	}}
+	helpFalling = helpPage{"falling", onPlay, []helpEntry{
+		keysEntry("delete a character", "backspace"),
+		keysEntry("release the target", "ctrl+u", "ctrl+w", "alt+backspace"),
+		actionEntry(actionRestart, "restart"),
+		actionEntry(actionPause, "pause and resume"),
+		actionEntry(actionQuit, "quit to the menu, when paused"),
+	}}
+	helpResults = helpPage{"results", onResults, []helpEntry{
+		actionEntry(actionRestart, "new test, same setup", "enter"),
+		keysEntry("repeat the same text", "p"),
+		keysEntry("retry the missed words", "r"),
+		keysEntry("next lesson, once passed", "n"),
+		keysEntry("review what you typed", "v"),
+		keysEntry("watch the replay", "w"),
+		keysEntry("copy a summary", "c"),
+		keysEntry("export as JSON", "e"),
+		actionEntry(actionBack, "back to the menu"),
+	}}
+	helpGameOver = helpPage{"game over", onResults, []helpEntry{
+		actionEntry(actionRestart, "play again", "enter"),
+		keysEntry("export as JSON", "e"),
+		actionEntry(actionBack, "back to the menu"),
+	}}
+	helpStats = helpPage{"stats", onResults, []helpEntry{
+		keysEntry("switch to and from achievements", "a"),
+		actionEntry(actionBack, "back to the menu", "q", "s"),
+	}}
+	helpSettings = helpPage{"settings", onResults, []helpEntry{
+		keysEntry("move between rows", "up", "k", "down", "j"),
+		keysEntry("change the row's value", "left", "h", "right", "l"),
+		actionEntry(actionBack, "back to the menu", "q", "o"),
+	}}
+	helpReplay = helpPage{"replay", onResults, []helpEntry{
+		keysEntry("watch again", "w"),
+		actionEntry(actionBack, "back to the results", "q"),
+	}}
+
+	helpPages = []helpPage{helpMenu, helpTyping, helpFalling, helpResults, helpGameOver, helpStats, helpSettings, helpReplay}
+)
+
+// fixedKeysOn lists the keys screens use for themselves, which can't be
+// bound to an action used there.
+func fixedKeysOn(screens keyScreens) []string {
+	var keys []string
+	for _, p := range helpPages {
+		if p.screens&screens == 0 {
+			continue
+		}
+		for _, e := range p.entries {
+			keys = append(keys, e.keys...)
+		}
+	}
+	return keys
+}
+
+// currentHelpPage is the page for the screen the panel is over.
+func currentHelpPage(m model) helpPage {
+	switch m.state {
+	case stateTyping:
+		return helpTyping
+	case stateFalling:
+		if m.fallingGameOver {
+			return helpGameOver
+		}
+		return helpFalling
+	case stateResults:
+		return helpResults
+	case stateStats:
+		return helpStats
+	case stateSettings:
+		return helpSettings
+	case stateReplay:
+		return helpReplay
+	}
+	return helpMenu
+}
+
+// helpAvailable reports whether the help key opens the panel here. While
+// playing, keys are typed, so it waits for the pause screen.
+func helpAvailable(m model) bool {
+	if screenTooSmall(m) {
+		return false
+	}
+	switch m.state {
+	case stateRaceLobby:
+		return false
+	case stateTyping:
+		return m.paused && !m.confirmingQuit
+	case stateFalling:
+		return m.fallingGameOver || (m.fallingPaused && !m.confirmingQuit)
+	}
+	return true
+}
+
+// openHelp shows the panel, holding a replay where it is.
+func openHelp(m model) (model, tea.Cmd) {
+	m.help = true
+	m.replayID++
+	return m, nil
+}
+
+// closeHelp hides the panel, picking a replay up where it was.
+func closeHelp(m model) (model, tea.Cmd) {
+	m.help = false
+	if m.state == stateReplay {
+		m.replayID++
+		return m, replayTickCmd(m)
+	}
+	return m, nil
+}
+
+// helpKeyName is how a key is written in the panel.
+func helpKeyName(key string) string {
+	if key == " " {
+		return "space"
+	}
+	return key
+}
+
+// renderHelp draws the panel for the current screen. When the screen is
+// too short for the entries in one column, the blank line under the title
+// goes first, then they split into more columns.
+func renderHelp(m model) string {
+	page := currentHelpPage(m)
+	entries := append(append([]helpEntry(nil), page.entries...),
+		actionEntry(actionMute, "mute or unmute sounds"),
+		keysEntry("quit cli_typer now", "ctrl+c"),
+	)
+
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		var names []string
+		if e.action != noAction {
+			names = append(names, helpKeyName(m.keys[e.action]))
+		}
+		for _, k := range e.keys {
+			names = append(names, helpKeyName(k))
+		}
+		keys[i] = strings.Join(names, "/")
+	}
+
+	title := styleTitle.Render("keys "+glyphDash+" "+page.title) + styleHint.Render("  any key closes")
+	parts := []string{title}
+	// The title and the border take three lines
+	room := max(m.height-3, 1)
+	if len(entries) < room {
+		parts = append(parts, "")
+	}
+	columns := (len(entries) + room - 1) / room
+	perColumn := (len(entries) + columns - 1) / columns
+	var cols []string
+	for start := 0; start < len(entries); start += perColumn {
+		end := min(start+perColumn, len(entries))
+		keyWidth := 0
+		for _, k := range keys[start:end] {
+			keyWidth = max(keyWidth, len(k))
+		}
+		var lines []string
+		for i := start; i < end; i++ {
+			lines = append(lines, styleHighlight.Render(fmt.Sprintf("%-*s", keyWidth, keys[i]))+"  "+styleStatLabel.Render(entries[i].does))
+		}
+		col := lipgloss.JoinVertical(lipgloss.Left, lines...)
+		if start > 0 {
+			col = lipgloss.NewStyle().PaddingLeft(3).Render(col)
+		}
+		cols = append(cols, col)
+	}
+	parts = append(parts, lipgloss.JoinHorizontal(lipgloss.Top, cols...))
+
+	return stylePanel.Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+// overlayHelp draws the panel centered over a rendered screen.
+func overlayHelp(m model, screen string) string {
+	rendered := renderHelp(m)
+	panel := strings.Split(rendered, "\n")
+	width := lipgloss.Width(rendered)
+	lines := strings.Split(screen, "\n")
+	for len(lines) < m.height {
+		lines = append(lines, "")
+	}
+	x := max((m.width-width)/2, 0)
+	y := max((len(lines)-len(panel))/2, 0)
+	for i, p := range panel {
+		if y+i >= len(lines) {
+			break
+		}
+		line := lines[y+i]
+		left := ansi.Truncate(line, x, "")
+		if w := ansi.StringWidth(left); w < x {
+			left += strings.Repeat(" ", x-w)
+		}
+		// Reset after the left part, so its colors don't run into the panel
+		lines[y+i] = left + "\x1b[0m" + p + ansi.TruncateLeft(line, x+width, "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,284 @@
+package main
+
+// Results history, persisted as JSON lines under the XDG data directory:
+//
+//   $XDG_DATA_HOME/cli_typer/history.jsonl  (default ~/.local/share/cli_typer)
+//
+// One line is appended per completed classic test or falling game. Appending
+// happens inside a tea.Cmd so a slow disk never stalls the UI. Lines that
+// fail to parse are skipped, so a corrupt file degrades to missing entries
+// rather than a crash.
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type historyEntry struct {
+	Time      time.Time `json:"time"`
+	Mode      string    `json:"mode"` // "classic" or "falling"
+	Content   string    `json:"content"`
+	Duration  int       `json:"duration"`             // seconds: configured for time tests, actual otherwise
+	WordCount int       `json:"word_count,omitempty"` // word-count tests only
+	Length    string    `json:"length,omitempty"`     // quote tests only: all/short/medium/long
+	WordSet   string    `json:"word_set,omitempty"`   // words content only: english_1k/english_5k; "" is the 200 set
+	WPM       float64   `json:"wpm"`
+	Accuracy  float64   `json:"accuracy"`
+	Score     int       `json:"points,omitempty"`    // falling mode only
+	Destroyed int       `json:"score,omitempty"`     // falling games saved before points: words destroyed
+	Wave      int       `json:"wave,omitempty"`      // falling waves format: wave reached
+	Lives     int       `json:"lives,omitempty"`     // falling only: starting lives, when not the default 3
+	Invaders  bool      `json:"invaders,omitempty"`  // falling only: the invaders game
+	TimeLimit int       `json:"limit,omitempty"`     // falling timed format: seconds to survive
+	Failed    bool      `json:"failed,omitempty"`    // master difficulty ended on a mistake
+	Misses    keyMisses `json:"misses,omitempty"`    // classic only: wrong keystrokes per character
+	Daily     string    `json:"daily,omitempty"`     // daily challenge date (UTC)
+	Reattempt bool      `json:"reattempt,omitempty"` // not the day's first daily result
+	Lesson    string    `json:"lesson,omitempty"`    // classic only: the lesson played
+	Practice  bool      `json:"practice,omitempty"`  // classic only: weak-words practice
+	Repeat    int       `json:"repeat,omitempty"`    // classic only: 2+ when the same text was typed again
+	Zen       bool      `json:"zen,omitempty"`       // classic zen test, ended by the typist
+
+	// Classic only: the words reached and the keystroke log, for replays
+	Words []string   `json:"words,omitempty"`
+	Keys  []keyEvent `json:"keys,omitempty"`
+}
+
+// dataDir returns the directory cli_typer stores persistent data in.
+func dataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "cli_typer")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "cli_typer")
+}
+
+func historyPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "history.jsonl")
+}
+
+// loadHistory reads every parseable entry from the history file, oldest first.
+func loadHistory() []historyEntry {
+	path := historyPath()
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var entries []historyEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // skip corrupt lines
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func appendHistory(e historyEntry) error {
+	path := historyPath()
+	if path == "" {
+		return os.ErrNotExist
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// saveHistoryCmd appends an entry to the history file in the background.
+// Failures are ignored — losing a history line shouldn't interrupt play.
+func saveHistoryCmd(e historyEntry) tea.Cmd {
+	return func() tea.Msg {
+		_ = appendHistory(e)
+		return nil
+	}
+}
+
+// classicHistoryEntry builds the history record for a finished classic test.
+func classicHistoryEntry(m model) historyEntry {
+	e := historyEntry{
+		Time:     time.Now(),
+		Mode:     "classic",
+		Content:  contentModeNames[m.contentMode],
+		Duration: int(m.duration.Seconds()),
+		WPM:      m.finalWPM,
+		Accuracy: m.finalAccuracy,
+		Failed:   m.testFailed,
+		WordSet:  wordSetTag(m),
+		Words:    m.words[:min(m.wordIndex+1, len(m.words))],
+		Keys:     m.keyLog,
+		Misses:   m.keyMisses,
+	}
+	if m.attempt > 1 {
+		e.Repeat = m.attempt
+	}
+	if m.daily != "" {
+		e.Daily = m.daily
+		_, e.Reattempt = dailyResult(m.history, m.daily)
+	}
+	e.Lesson = m.lesson
+	e.Practice = m.practice
+	if m.contentMode == modeQuotes {
+		e.Length = quoteLengthNames[m.quoteLength]
+		e.Duration = int(m.finalTime + 0.5)
+	} else if m.testMode == testModeWords {
+		e.WordCount = len(m.words)
+		e.Duration = int(m.finalTime + 0.5)
+	} else if zenTest(m) {
+		e.Zen = true
+		e.Duration = int(m.finalTime + 0.5)
+	}
+	return e
+}
+
+// fallingHistoryEntry builds the history record for a finished falling game.
+func fallingHistoryEntry(m model) historyEntry {
+	wave := 0
+	if hasWaves(m) {
+		wave = m.fallingWave
+	}
+	lives := 0
+	if m.lives != defaultFallingLives {
+		lives = m.lives
+	}
+	e := historyEntry{
+		Time:     time.Now(),
+		Mode:     "falling",
+		Content:  contentModeNames[m.contentMode],
+		Duration: int(fallingElapsed(m).Seconds()),
+		WPM:      m.finalWPM,
+		Accuracy: m.finalAccuracy,
+		Score:    m.fallingScore,
+		Wave:     wave,
+		Lives:    lives,
+		Invaders: invadersGame(m),
+		WordSet:  wordSetTag(m),
+	}
+	if timedGame(m) {
+		e.TimeLimit = m.timeLimit
+	}
+	return e
+}
+
+// sameSetup reports whether two entries were played with comparable
+// settings (mode, content, and test length), so their scores can be ranked.
+func sameSetup(a, b historyEntry) bool {
+	if a.Mode != b.Mode || a.Content != b.Content || a.Daily != b.Daily || a.WordSet != b.WordSet {
+		return false
+	}
+	if a.Lesson != b.Lesson || a.Practice != b.Practice {
+		return false
+	}
+	if a.Mode == "falling" {
+		return (a.Wave > 0) == (b.Wave > 0) && a.Lives == b.Lives && a.Invaders == b.Invaders && a.TimeLimit == b.TimeLimit
+	}
+	if a.Length != "" || b.Length != "" {
+		return a.Length == b.Length
+	}
+	if a.Zen || b.Zen {
+		return a.Zen == b.Zen
+	}
+	if a.WordCount > 0 || b.WordCount > 0 {
+		return a.WordCount == b.WordCount
+	}
+	return a.Duration == b.Duration
+}
+
+// entryScore is the number personal bests are ranked by: WPM for classic
+// tests, points for falling games.
+func entryScore(e historyEntry) float64 {
+	if e.Mode == "falling" {
+		return float64(e.Score)
+	}
+	return e.WPM
+}
+
+// personalBest returns the best score among history entries with the same
+// setup as e. ok is false when there are none. Runs on repeated text don't
+// count, since practicing the same words inflates the score, and neither
+// do falling games from before points, scored in words destroyed.
+func personalBest(history []historyEntry, e historyEntry) (best float64, ok bool) {
+	for _, h := range history {
+		if h.Failed || h.Repeat > 0 || h.Destroyed > 0 || !sameSetup(h, e) {
+			continue
+		}
+		if s := entryScore(h); !ok || s > best {
+			best = s
+			ok = true
+		}
+	}
+	return best, ok
+}
+
+// recordResult checks the entry against the stored personal best, appends
+// it to the in-memory history, and returns the command that persists it.
+// The first result for a setup always counts as a personal best; a tie
+// with the previous best does not, and neither does a failed test or a
+// repeat.
+func recordResult(m model, e historyEntry) (model, tea.Cmd) {
+	m.prevBest, m.hasPrevBest = personalBest(m.history, e)
+	m.newPersonalBest = !e.Failed && e.Repeat == 0 && (!m.hasPrevBest || entryScore(e) > m.prevBest)
+	m.history = append(m.history, e)
+	m.lastResult = newPrintedResult(m, e)
+	return m, saveHistoryCmd(e)
+}
+
+// printedResult is what --json writes to stdout on exit: the history entry
+// plus the character counts behind it.
+type printedResult struct {
+	historyEntry
+	RawWPM       float64 `json:"raw_wpm,omitempty"` // classic only
+	CorrectChars int     `json:"correct_chars"`
+	TotalChars   int     `json:"total_chars"`
+	Seed         *int64  `json:"seed,omitempty"` // nil for missed-word retries
+}
+
+// newPrintedResult snapshots a just-recorded result. For falling games the
+// characters are those of destroyed words against every key typed.
+func newPrintedResult(m model, e historyEntry) *printedResult {
+	r := &printedResult{historyEntry: e}
+	r.Words, r.Keys = nil, nil // replay data stays in the history file
+	if m.seedValid {
+		seed := m.seed
+		r.Seed = &seed
+	}
+	if e.Mode == "falling" {
+		r.CorrectChars = m.fallingCharsTyped
+		r.TotalChars = m.fallingKeystrokes
+	} else {
+		r.RawWPM = m.finalRawWPM
+		r.CorrectChars = m.correctChars
+		r.TotalChars = m.totalChars
+	}
+	return r
+}
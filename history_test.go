@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFallingScoresBeforePoints(t *testing.T) {
+	var old, cur historyEntry
+	if err := json.Unmarshal([]byte(`{"time":"2026-01-01T10:00:00Z","mode":"falling","content":"words","score":42}`), &old); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"time":"2026-02-01T10:00:00Z","mode":"falling","content":"words","points":380}`), &cur); err != nil {
+		t.Fatal(err)
+	}
+	if old.Score != 0 || old.Destroyed != 42 || cur.Score != 380 || cur.Destroyed != 0 {
+		t.Fatalf("old game: %d points, %d words; new: %d points, %d words; want 0, 42, 380, 0",
+			old.Score, old.Destroyed, cur.Score, cur.Destroyed)
+	}
+	if line := renderHistoryLine(old); !strings.Contains(line, "42 words") {
+		t.Errorf("old game's history line %q doesn't show its words", line)
+	}
+	if line := renderHistoryLine(cur); !strings.Contains(line, "380 pts") {
+		t.Errorf("new game's history line %q doesn't show its points", line)
+	}
+
+	if best, ok := personalBest([]historyEntry{old}, cur); ok {
+		t.Errorf("the old game's %v words count as a best in points", best)
+	}
+}
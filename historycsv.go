@@ -0,0 +1,378 @@
+package main
+
+// History as CSV, for spreadsheets and other typing trackers. Both run
+// from the command line and exit without starting the game:
+//
+//   cli_typer --export-csv history.csv   writes every history entry
+//   cli_typer --import-csv history.csv   adds a file's rows to the history
+//
+// The export has one row per entry with the columns in csvColumns, a
+// stable schema that only ever grows at the end. Replays, key logs, and
+// per-key misses stay in the history file.
+//
+// An import reads either that schema or monkeytype's results export,
+// telling them apart by the header, and matches columns by name, so
+// their order doesn't matter. Rows already in the history (by time) and
+// rows with a bad time, mode, or number are skipped. The history is
+// rewritten with the new rows in place by time; the lines already there
+// are kept byte for byte, even ones this version can't read.
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the export's header, in order.
+var csvColumns = []string{
+	"time", "mode", "content", "duration", "word_count", "length", "word_set",
+	"wpm", "accuracy", "score", "wave", "lives", "invaders", "limit",
+	"failed", "daily", "reattempt", "lesson", "practice", "repeat", "zen",
+	"destroyed",
+}
+
+// csvRow is an entry's export row, in csvColumns order.
+func csvRow(e historyEntry) []string {
+	return []string{
+		e.Time.UTC().Format(time.RFC3339Nano), e.Mode, e.Content,
+		strconv.Itoa(e.Duration), strconv.Itoa(e.WordCount), e.Length, e.WordSet,
+		strconv.FormatFloat(e.WPM, 'f', 2, 64), strconv.FormatFloat(e.Accuracy, 'f', 2, 64),
+		strconv.Itoa(e.Score), strconv.Itoa(e.Wave), strconv.Itoa(e.Lives),
+		strconv.FormatBool(e.Invaders), strconv.Itoa(e.TimeLimit),
+		strconv.FormatBool(e.Failed), e.Daily, strconv.FormatBool(e.Reattempt),
+		e.Lesson, strconv.FormatBool(e.Practice), strconv.Itoa(e.Repeat),
+		strconv.FormatBool(e.Zen), strconv.Itoa(e.Destroyed),
+	}
+}
+
+// exportCSV writes the whole history to path, returning the rows written.
+func exportCSV(path string) (int, error) {
+	entries := loadHistory()
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	w := csv.NewWriter(f)
+	w.Write(csvColumns)
+	for _, e := range entries {
+		w.Write(csvRow(e))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(entries), f.Close()
+}
+
+// csvRecord is a CSV row read by column name.
+type csvRecord struct {
+	columns map[string]int
+	row     []string
+}
+
+func (r csvRecord) has(name string) bool {
+	_, ok := r.columns[name]
+	return ok
+}
+
+// str is the named column's value, or "" if there's no such column.
+func (r csvRecord) str(name string) string {
+	if i, ok := r.columns[name]; ok && i < len(r.row) {
+		return strings.TrimSpace(r.row[i])
+	}
+	return ""
+}
+
+// num parses the named column as a finite number at least 0; an empty or
+// missing column is 0.
+func (r csvRecord) num(name string) (float64, error) {
+	s := r.str(name)
+	if s == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || f < 0 {
+		return 0, fmt.Errorf("%s %q isn't a number", name, s)
+	}
+	return f, nil
+}
+
+func (r csvRecord) integer(name string) (int, error) {
+	f, err := r.num(name)
+	return int(math.Round(f)), err
+}
+
+// flag parses the named column as a boolean; an empty or missing column
+// is false.
+func (r csvRecord) flag(name string) (bool, error) {
+	s := r.str(name)
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// csvEntry turns one of our own export rows back into an entry.
+func csvEntry(r csvRecord) (historyEntry, error) {
+	var e historyEntry
+	var err error
+	if e.Time, err = time.Parse(time.RFC3339Nano, r.str("time")); err != nil {
+		return e, err
+	}
+	e.Mode = r.str("mode")
+	if e.Mode != "classic" && e.Mode != "falling" {
+		return e, fmt.Errorf("unknown mode %q", e.Mode)
+	}
+	e.Content, e.Length, e.WordSet = r.str("content"), r.str("length"), r.str("word_set")
+	e.Daily, e.Lesson = r.str("daily"), r.str("lesson")
+
+	ints := []struct {
+		name string
+		v    *int
+	}{{"duration", &e.Duration}, {"word_count", &e.WordCount}, {"score", &e.Score}, {"wave", &e.Wave}, {"lives", &e.Lives}, {"limit", &e.TimeLimit}, {"repeat", &e.Repeat}, {"destroyed", &e.Destroyed}}
+	for _, c := range ints {
+		if *c.v, err = r.integer(c.name); err != nil {
+			return e, err
+		}
+	}
+	flags := []struct {
+		name string
+		v    *bool
+	}{{"invaders", &e.Invaders}, {"failed", &e.Failed}, {"reattempt", &e.Reattempt}, {"practice", &e.Practice}, {"zen", &e.Zen}}
+	for _, c := range flags {
+		if *c.v, err = r.flag(c.name); err != nil {
+			return e, err
+		}
+	}
+	if e.WPM, err = r.num("wpm"); err != nil {
+		return e, err
+	}
+	if e.Accuracy, err = r.num("accuracy"); err != nil {
+		return e, err
+	}
+	if e.Accuracy > 100 {
+		return e, fmt.Errorf("accuracy %v is over 100", e.Accuracy)
+	}
+	return e, nil
+}
+
+// monkeytypeEntry turns a row of monkeytype's results export into a
+// classic entry. Its time and quote modes map to ours; its "custom" and
+// funbox tests are imported as words tests.
+func monkeytypeEntry(r csvRecord) (historyEntry, error) {
+	e := historyEntry{Mode: "classic", Content: "words"}
+	ms, err := r.num("timestamp")
+	if err != nil || ms == 0 {
+		return e, fmt.Errorf("bad timestamp %q", r.str("timestamp"))
+	}
+	e.Time = time.UnixMilli(int64(ms)).UTC()
+	if e.WPM, err = r.num("wpm"); err != nil {
+		return e, err
+	}
+	if e.Accuracy, err = r.num("acc"); err != nil {
+		return e, err
+	}
+	if e.Accuracy > 100 {
+		return e, fmt.Errorf("acc %v is over 100", e.Accuracy)
+	}
+	seconds, err := r.num("testDuration")
+	if err != nil {
+		return e, err
+	}
+	e.Duration = int(math.Round(seconds))
+
+	mode2, err := r.integer("mode2")
+	switch r.str("mode") {
+	case "time":
+		if err == nil && mode2 > 0 {
+			e.Duration = mode2
+		}
+	case "words":
+		if err != nil {
+			return e, err
+		}
+		e.WordCount = mode2
+	case "quote":
+		e.Content = "quotes"
+		e.Length = "all"
+	case "zen":
+		e.Zen = true
+	}
+	switch lang := r.str("language"); lang {
+	case "english_1k", "english_5k":
+		e.WordSet = lang
+	}
+	return e, nil
+}
+
+// importCSV adds the rows of the CSV at path to the history. Rows already
+// there, or that can't be read, are skipped.
+func importCSV(path string) (imported, skipped int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	rd := csv.NewReader(f)
+	rd.FieldsPerRecord = -1
+	header, err := rd.Read()
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading the header: %w", err)
+	}
+	// Spreadsheets may start the file with a byte order mark
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	parse := csvEntry
+	switch r := (csvRecord{columns: columns}); {
+	case r.has("timestamp") && r.has("acc"):
+		parse = monkeytypeEntry
+	case !r.has("time") || !r.has("mode") || !r.has("wpm"):
+		return 0, 0, fmt.Errorf("the header is neither cli_typer's nor monkeytype's")
+	}
+
+	lines, err := readHistoryLines()
+	if err != nil {
+		return 0, 0, err
+	}
+	seen := map[int64]bool{}
+	for _, l := range lines {
+		if l.readable {
+			seen[l.at.UnixNano()] = true
+		}
+	}
+	for {
+		row, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				skipped++
+				continue
+			}
+			return 0, 0, err
+		}
+		e, err := parse(csvRecord{columns: columns, row: row})
+		if err != nil || seen[e.Time.UnixNano()] {
+			skipped++
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			skipped++
+			continue
+		}
+		seen[e.Time.UnixNano()] = true
+		lines = append(lines, historyLine{at: e.Time, raw: line, readable: true})
+		imported++
+	}
+	if imported == 0 {
+		return 0, skipped, nil
+	}
+	// Stable, so the lines already there keep their order among themselves
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].at.Before(lines[j].at) })
+	return imported, skipped, writeHistory(lines)
+}
+
+// historyLine is a line of the history file as it was read, or a new
+// entry's line. A line that isn't an entry this version can read sorts
+// with the line before it.
+type historyLine struct {
+	at       time.Time
+	raw      []byte
+	readable bool
+}
+
+// readHistoryLines reads every line of the history file; there are none
+// if it doesn't exist yet.
+func readHistoryLines() ([]historyLine, error) {
+	path := historyPath()
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	var lines []historyLine
+	var at time.Time
+	for _, raw := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		var e historyEntry
+		readable := json.Unmarshal(raw, &e) == nil
+		if readable {
+			at = e.Time
+		}
+		lines = append(lines, historyLine{at: at, raw: raw, readable: readable})
+	}
+	return lines, nil
+}
+
+// writeHistory replaces the history file with lines, writing a new file
+// first so a failure leaves the old one as it was.
+func writeHistory(lines []historyLine) error {
+	path := historyPath()
+	if path == "" {
+		return os.ErrNotExist
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "history-*.jsonl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.Write(l.raw)
+		buf.WriteByte('\n')
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	// CreateTemp makes the file private; the history is written 0644
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// runCSVFlags does what --import-csv and --export-csv ask, importing
+// first, and reports on stdout.
+func runCSVFlags(importPath, exportPath string) error {
+	if importPath != "" {
+		imported, skipped, err := importCSV(importPath)
+		if err != nil {
+			return fmt.Errorf("--import-csv: %w", err)
+		}
+		fmt.Printf("imported %d rows, skipped %d\n", imported, skipped)
+	}
+	if exportPath != "" {
+		n, err := exportCSV(exportPath)
+		if err != nil {
+			return fmt.Errorf("--export-csv: %w", err)
+		}
+		fmt.Printf("exported %d rows to %s\n", n, exportPath)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportCSVKeepsUnreadableLines(t *testing.T) {
+	newTestModel(t)
+	path := historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := strings.Join([]string{
+		`{"time":"2026-01-01T10:00:00Z","mode":"classic","wpm":50,"accuracy":95,"future_field":1}`,
+		`not json at all`,
+		`{"time":"2026-03-01T10:00:00Z","mode":"classic","wpm":70,"accuracy":97}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	csvPath := filepath.Join(t.TempDir(), "in.csv")
+	csvData := "time,mode,wpm,accuracy\n" +
+		"2026-02-01T10:00:00Z,classic,60,96\n" +
+		"2026-03-01T10:00:00Z,classic,70,97\n" // already there
+	if err := os.WriteFile(csvPath, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, skipped, err := importCSV(csvPath)
+	if err != nil || imported != 1 || skipped != 1 {
+		t.Fatalf("importCSV: %d imported, %d skipped, err %v; want 1, 1, nil", imported, skipped, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("history has %d lines, want 4:\n%s", len(lines), data)
+	}
+	oldLines := strings.Split(strings.TrimSuffix(old, "\n"), "\n")
+	if lines[0] != oldLines[0] || lines[1] != oldLines[1] || lines[3] != oldLines[2] {
+		t.Errorf("existing lines weren't kept as they were:\n%s", data)
+	}
+	if !strings.Contains(lines[2], `"2026-02-01T10:00:00Z"`) {
+		t.Errorf("imported row isn't between the January and March lines:\n%s", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o644 {
+		t.Errorf("history file mode %v, want 0644", mode)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+// Inline mode (--inline). Instead of taking over the whole terminal with
+// the alt screen, every screen is drawn in the bottom inlineHeight lines,
+// below the prompt, leaving the scrollback alone — handy in a small tmux
+// pane.
+//
+// Screens that don't fit switch to compact layouts: the menu drops the
+// daily, lessons, and practice lines and spacing, settings scroll, and
+// results and stats pack several numbers to a line. Falling mode needs the
+// full screen and isn't offered, and mouse clicks are off, since their
+// coordinates are for the whole terminal, not the lines drawn.
+//
+// On exit the drawn lines are cleared and main prints a one-line summary
+// of the last result, if there was one.
+
+import tea "github.com/charmbracelet/bubbletea"
+
+const inlineHeight = 12
+
+// quit ends the program. The last frame is blank, so inline mode leaves
+// nothing behind but main's summary.
+func quit(m model) (model, tea.Cmd) {
+	m.quitting = true
+	return m, tea.Quit
+}
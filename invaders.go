@@ -0,0 +1,177 @@
+package main
+
+// Invaders, the third game on the menu: falling mode with the aliens in a
+// Space Invaders formation instead of falling one at a time.
+//
+// Each wave is a formation of formationRows × formationCols small aliens.
+// It steps one column sideways every few ticks, and when it reaches an
+// edge it drops a row and turns around. Typing a member's word destroys
+// it like any alien, and the formation steps faster as it thins out. The
+// wave ends when the formation is cleared (with the no-leak bonus) or
+// touches the shield, which costs a life and leaks every member left.
+//
+// Everything else (targeting, the turret and laser, lives, the break
+// between waves, and the game over screen) is falling mode's. Members are
+// ordinary fallingWords that never fall or drift on their own; only
+// formationTick moves them.
+
+import "unicode/utf8"
+
+const (
+	formationRows     = 2
+	formationCols     = 4
+	formationMaxWord  = 3 // runes; longer words get a taller sprite
+	formationSlowStep = 4 // ticks between steps for a full first-wave formation
+
+	minInvadersHeight = 22
+)
+
+// formation is the marching state of the current wave's formation.
+type formation struct {
+	dir    int // 1 stepping right, -1 left
+	stepCD int // ticks until the next step
+}
+
+func invadersGame(m model) bool {
+	return m.gameMode == gameModeInvaders
+}
+
+// formationStepTicks is the ticks between steps with n members left: the
+// full formation steps every formationSlowStep ticks (less in later
+// waves), and the last member every tick.
+func formationStepTicks(m model, n int) int {
+	slow := max(formationSlowStep-(m.fallingWave-1), 2)
+	full := formationRows * formationCols
+	return 1 + (slow-1)*(n-1)/(full-1)
+}
+
+// pickFormationWord chooses a member's word, preferring ones short enough
+// for the small sprite.
+func pickFormationWord(m model) string {
+	var word string
+	for attempt := 0; attempt < 10; attempt++ {
+		w, ok := pickFallingWord(m)
+		if !ok {
+			continue
+		}
+		word = w
+		if utf8.RuneCountInString(w) <= formationMaxWord {
+			break
+		}
+	}
+	return word
+}
+
+// spawnFormation lines up a new formation at the top of the playfield,
+// centered. Columns that wouldn't fit the terminal are left out.
+func spawnFormation(m model) model {
+	var arts []builtAlien
+	var words []string
+	cellWidth, rowHeight := 0, 0
+	for i := 0; i < formationRows*formationCols; i++ {
+		word := pickFormationWord(m)
+		if word == "" {
+			continue
+		}
+		art := buildAlienArt(word, powerNone)
+		words = append(words, word)
+		arts = append(arts, art)
+		cellWidth = max(cellWidth, art.width+1)
+		rowHeight = max(rowHeight, len(art.lines)+1)
+	}
+	if len(words) == 0 {
+		return m
+	}
+
+	playWidth := fallingPlayWidth(m)
+	cols := min(formationCols, (playWidth-2*edgePadding+1)/cellWidth)
+	cols = max(cols, 1)
+	left := (playWidth - (cols*cellWidth - 1)) / 2
+
+	m.fallingWords = nil
+	for i, word := range words {
+		row, col := i/formationCols, i%formationCols
+		if col >= cols {
+			continue
+		}
+		art := arts[i]
+		x := left + col*cellWidth + (cellWidth-1-art.width)/2
+		m.fallingNextID++
+		m.fallingWords = append(m.fallingWords, fallingWord{
+			id:     m.fallingNextID,
+			word:   word,
+			x:      x,
+			fx:     float64(x),
+			y:      float64(row*rowHeight + art.wordRow),
+			sprite: art,
+		})
+	}
+	m.fallingWaveSpawned = len(m.fallingWords)
+	m.fallingPeakAliens = max(m.fallingPeakAliens, len(m.fallingWords))
+	m.fallingFormation = formation{dir: 1, stepCD: formationStepTicks(m, len(m.fallingWords))}
+	return m
+}
+
+// formationTick runs the formation's part of a game tick: ending the
+// wave once it's cleared, marching it, and checking it against the shield.
+func formationTick(m model) model {
+	if len(m.fallingWords) == 0 {
+		return finishWave(m)
+	}
+	m.fallingFormation.stepCD--
+	if m.fallingFormation.stepCD > 0 {
+		return m
+	}
+	m.fallingFormation.stepCD = formationStepTicks(m, len(m.fallingWords))
+
+	// Step sideways, or drop a row and turn around at an edge
+	left, right := fallingPlayWidth(m), 0
+	for _, fw := range m.fallingWords {
+		left = min(left, fw.x)
+		right = max(right, fw.x+fw.art().width)
+	}
+	dir := m.fallingFormation.dir
+	if left+dir < edgePadding || right+dir > fallingPlayWidth(m)-edgePadding {
+		m.fallingFormation.dir = -dir
+		for i := range m.fallingWords {
+			m.fallingWords[i].y++
+		}
+	} else {
+		for i := range m.fallingWords {
+			m.fallingWords[i].x += dir
+			m.fallingWords[i].fx = float64(m.fallingWords[i].x)
+		}
+	}
+	if m.fallingTarget >= 0 && m.fallingTarget < len(m.fallingWords) {
+		m = aimTurret(m)
+	}
+
+	// Touching the shield costs a life and the rest of the formation
+	playHeight := fallingPlayHeight(m)
+	for _, fw := range m.fallingWords {
+		art := fw.art()
+		if int(fw.y)+len(art.lines)-art.wordRow > playHeight {
+			return formationLanded(m)
+		}
+	}
+	return m
+}
+
+// formationLanded ends the wave with every member left leaked.
+func formationLanded(m model) model {
+	leaked := len(m.fallingWords)
+	for _, fw := range m.fallingWords {
+		m = chargeLeak(m, fw)
+	}
+	m.fallingLeaked += leaked
+	m.fallingStreak = 0
+	m.fallingWaveLeaked += leaked
+	m.fallingWords = nil
+	m.fallingCombo = 0
+	m.fallingLives--
+	if m.fallingLives <= 0 {
+		m.fallingLives = 0
+		return endFallingGame(m)
+	}
+	return finishWave(m)
+}
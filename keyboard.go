@@ -0,0 +1,134 @@
+package main
+
+// The on-screen keyboard (settings screen), drawn under the text of a
+// classic test for learning where the keys are. It shows the emulated
+// layout, if there is one (see layout.go). The key for the next
+// character to type is lit in the accent color, along with shift when the
+// character needs it, and keys missed recently are tinted in the error
+// color until keyboardRecentMisses later misses push them out.
+//
+//    `   1   2   3   4   5   6   7   8   9   0   -   =
+//      q   w   e   r   t   y   u   i   o   p   [   ]   \
+//       a   s   d   f   g   h   j   k   l   ;   '
+//   shift  z   x   c   v   b   n   m   ,   .   /  shift
+//                  [           space           ]
+//
+// It needs keyboardHeight more lines and keyboardWidth columns, and is
+// left out when the terminal is too small for it rather than squeezing
+// the text.
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	keyboardHeight       = 6 // a blank line, four rows of keys, and space
+	keyboardWidth        = 53
+	keyboardRecentMisses = 5
+)
+
+// keyRow is a row of keys: what each types, and what it types with shift.
+// The bottom row is indented by its shift key instead.
+type keyRow struct {
+	indent         int
+	plain, shifted string
+}
+
+// qwertyRows is the keyboard's own layout, which emulated layouts are
+// mapped from.
+var qwertyRows = []keyRow{
+	{0, "`1234567890-=", "~!@#$%^&*()_+"},
+	{2, `qwertyuiop[]\`, "QWERTYUIOP{}|"},
+	{3, "asdfghjkl;'", `ASDFGHJKL:"`},
+	{0, "zxcvbnm,./", "ZXCVBNM<>?"},
+}
+
+// keyPosition finds the key that types r: its row and column, and whether
+// it needs shift. ok is false for characters not on the keyboard.
+func keyPosition(rows []keyRow, r rune) (row, col int, shift, ok bool) {
+	for i, kr := range rows {
+		if j := strings.IndexRune(kr.plain, r); j >= 0 {
+			return i, utf8.RuneCountInString(kr.plain[:j]), false, true
+		}
+		if j := strings.IndexRune(kr.shifted, r); j >= 0 {
+			return i, utf8.RuneCountInString(kr.shifted[:j]), true, true
+		}
+	}
+	return 0, 0, false, false
+}
+
+// nextKey is the character the test expects next: the next one in the
+// current word, or a space once the word is typed out.
+func nextKey(m model) rune {
+	target := []rune(m.words[m.wordIndex])
+	if m.charIndex < len(target) {
+		return target[m.charIndex]
+	}
+	return ' '
+}
+
+// noteMissedKey remembers a missed key for the keyboard to tint.
+func noteMissedKey(m model, expected rune) model {
+	m.missedKeys = append(m.missedKeys, expected)
+	if len(m.missedKeys) > keyboardRecentMisses {
+		m.missedKeys = m.missedKeys[1:]
+	}
+	return m
+}
+
+// keyboardFits reports whether the keyboard fits under content.
+func keyboardFits(m model, content string) bool {
+	return m.width >= keyboardWidth && m.height >= lipgloss.Height(content)+keyboardHeight
+}
+
+// renderKeyboard draws the keyboard with the next key lit.
+func renderKeyboard(m model) string {
+	rows := keyboardLayouts[m.layout].rows
+	next := nextKey(m)
+	nextRow, nextCol, shift, onKeyboard := keyPosition(rows, next)
+
+	missed := map[rune]bool{}
+	for _, r := range m.missedKeys {
+		if i, j, _, ok := keyPosition(rows, r); ok {
+			missed[[]rune(rows[i].plain)[j]] = true
+		}
+	}
+
+	key := func(label string, lit, miss bool) string {
+		switch {
+		case lit:
+			return styleKeyNext.Render(label)
+		case miss:
+			return styleIncorrect.Render(label)
+		}
+		return styleUntyped.Render(label)
+	}
+	shiftKey := key("shift", shift && onKeyboard, false)
+
+	var lines []string
+	for i, kr := range rows {
+		var b strings.Builder
+		if i == len(rows)-1 {
+			b.WriteString(shiftKey + " ")
+		} else {
+			b.WriteString(strings.Repeat(" ", kr.indent))
+		}
+		for j, r := range []rune(kr.plain) {
+			if j > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(key(" "+string(r)+" ", onKeyboard && i == nextRow && j == nextCol, missed[r]))
+		}
+		if i == len(rows)-1 {
+			b.WriteString(" " + shiftKey)
+		}
+		lines = append(lines, b.String())
+	}
+	space := key("["+strings.Repeat(" ", 11)+"space"+strings.Repeat(" ", 11)+"]", next == ' ', false)
+	lines = append(lines, strings.Repeat(" ", 15)+space)
+	// Padded to a block, so centering it keeps the rows lined up
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
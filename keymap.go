@@ -0,0 +1,209 @@
+package main
+
+// Keybindings for the keys that aren't typing: restart, pause, and the
+// rest of keyActions. Each can be rebound in the config file's "keys"
+// object, by action name:
+//
+//   "keys": {"restart": "ctrl+r", "pause": "f2"}
+//
+// Keys are named the way bubbletea names them ("tab", "esc", "enter",
+// "ctrl+r", "f5", "alt+q"). An action left out keeps its default. A
+// binding is rejected, keeping the default, if the key has no such name,
+// if it's taken by another action on a screen they share, or if one of
+// those screens already uses it for something fixed (any character while
+// typing, or a key on the screen's help page). The menu warns about the
+// first rejected binding at startup; the file is left as written, so
+// fixing it is an edit away.
+//
+// Hints are drawn from the keymap, so they always name the bound keys.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type keyAction int
+
+const (
+	actionRestart keyAction = iota
+	actionPause
+	actionQuit
+	actionFinish
+	actionBack
+	actionMute
+	actionHelp
+	numActions
+)
+
+// keyScreens is a set of screens a binding is used on.
+type keyScreens int
+
+const (
+	onPlay    keyScreens = 1 << iota // a test or game being played, or counting down
+	onPaused                         // a paused test or game
+	onMenu                           // the menu
+	onResults                        // results, game over, stats, settings, and replay
+	onAll     = onPlay | onPaused | onMenu | onResults
+)
+
+// keyActionSpec describes an action: its name in the config file, its
+// default key, and the screens it's used on.
+type keyActionSpec struct {
+	name    string
+	key     string
+	screens keyScreens
+}
+
+var keyActions = [numActions]keyActionSpec{
+	actionRestart: {"restart", "tab", onPlay | onResults},       // start over with the same setup
+	actionPause:   {"pause", "esc", onPlay | onPaused},          // pause, resume, or leave a test not yet started
+	actionQuit:    {"quit", "q", onPaused | onMenu},             // the pause screen to the menu, or the menu to the shell
+	actionFinish:  {"finish", "enter", onPlay | onPaused},       // end a zen test
+	actionBack:    {"back", "esc", onResults},                   // back to the menu (or results, from review and replay)
+	actionMute:    {"mute", "ctrl+s", onAll},                    // silence every sound until pressed again
+	actionHelp:    {"help", "?", onPaused | onMenu | onResults}, // the keys for the screen (see help.go)
+}
+
+// keymap is the key bound to each action.
+type keymap [numActions]string
+
+func defaultKeymap() keymap {
+	var k keymap
+	for a, spec := range keyActions {
+		k[a] = spec.key
+	}
+	return k
+}
+
+// is reports whether msg is the key bound to action.
+func (k keymap) is(msg tea.KeyMsg, action keyAction) bool {
+	return msg.String() == k[action]
+}
+
+// keyNames are the names of every key bubbletea reports, besides
+// characters, with and without alt.
+var keyNames = func() map[string]bool {
+	names := map[string]bool{}
+	for t := tea.KeyF20; t <= tea.KeyBackspace; t++ {
+		if s := t.String(); s != "" && t != tea.KeyRunes {
+			names[s] = true
+			names["alt+"+s] = true
+		}
+	}
+	return names
+}()
+
+// keyUsable reports whether key can be bound to an action used on
+// screens, and if not, why.
+func keyUsable(key string, screens keyScreens) (ok bool, why string) {
+	char := len([]rune(strings.TrimPrefix(key, "alt+"))) == 1
+	switch {
+	case !char && !keyNames[key]:
+		return false, "isn't a key"
+	case key == "ctrl+c":
+		return false, "always quits"
+	case char && screens&onPlay != 0 && !strings.HasPrefix(key, "alt+"):
+		return false, "is typed in tests"
+	}
+	// The help pages list the keys each screen uses for itself
+	for _, fixed := range fixedKeysOn(screens) {
+		if key == fixed {
+			return false, "is already used"
+		}
+	}
+	return true, ""
+}
+
+// buildKeymap binds the keys in the config's "keys" object over the
+// defaults. warning describes the first binding it had to reject, or is
+// "" if there were none.
+func buildKeymap(bindings map[string]string) (k keymap, warning string) {
+	k = defaultKeymap()
+	var problems []string
+	bound := [numActions]bool{}
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := bindings[name]
+		a := keyActionByName(name)
+		if a < 0 {
+			problems = append(problems, fmt.Sprintf("no action named %q", name))
+			continue
+		}
+		if ok, why := keyUsable(key, keyActions[a].screens); !ok {
+			problems = append(problems, fmt.Sprintf("%s key %q %s %s using %s", name, key, why, glyphDash, keyActions[a].key))
+			continue
+		}
+		k[a], bound[a] = key, true
+	}
+
+	// Two actions sharing a key on a screen both use leaves the rebound
+	// one (or both) at its default. Defaults never clash, so this ends.
+	for clash := true; clash; {
+		clash = false
+		for a := range k {
+			for b := a + 1; b < len(k); b++ {
+				if k[a] != k[b] || keyActions[a].screens&keyActions[b].screens == 0 {
+					continue
+				}
+				for _, c := range []int{a, b} {
+					if bound[c] {
+						other := keyActions[a+b-c].name
+						problems = append(problems, fmt.Sprintf("%s key %q is %s's %s using %s", keyActions[c].name, k[c], other, glyphDash, keyActions[c].key))
+						k[c], bound[c] = keyActions[c].key, false
+					}
+				}
+				clash = true
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return k, ""
+	}
+	warning = "keys: " + problems[0]
+	if len(problems) > 1 {
+		warning += fmt.Sprintf(" (and %d more)", len(problems)-1)
+	}
+	return k, warning
+}
+
+// keyActionByName finds an action by its config name, or returns -1.
+func keyActionByName(name string) keyAction {
+	for a, spec := range keyActions {
+		if spec.name == name {
+			return keyAction(a)
+		}
+	}
+	return -1
+}
+
+// toggleMute silences every sound, or brings them back. Music in a game
+// being played stops, and starts again on unmuting.
+func toggleMute(m model) (model, tea.Cmd) {
+	m.muted = !m.muted
+	soundVolume = effectiveVolume(m)
+	if m.muted {
+		return m, stopMusicCmd()
+	}
+	if m.state == stateFalling && !m.fallingPaused && !m.fallingGameOver {
+		return m, startMusicCmd(m)
+	}
+	return m, nil
+}
+
+// effectiveVolume is the volume sounds play at: the setting, or 0 while
+// muted.
+func effectiveVolume(m model) int {
+	if m.muted {
+		return 0
+	}
+	return m.volume
+}
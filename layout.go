@@ -0,0 +1,81 @@
+package main
+
+// Layout emulation (settings screen), for practicing another keyboard
+// layout on a keyboard set up as QWERTY. Each character typed in a test or
+// game is swapped for the one the emulated layout has on the same key, so
+// pressing QWERTY's "s" types Colemak's "r". Keys that aren't on the
+// keyboard map (control keys, and anything else not in qwertyRows) pass
+// through untouched, and so does everything outside of play: the menus,
+// pause, and game over screens still read keys as they are.
+//
+// The on-screen keyboard shows the emulated layout, and the status bar
+// names it, so it can't be left on by mistake.
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// keyboardLayout is a layout as rows of keys shaped like qwertyRows, key
+// for key.
+type keyboardLayout struct {
+	name string
+	rows []keyRow
+}
+
+// keyboardLayouts are the settings screen's choices; the first, QWERTY,
+// is no emulation.
+var keyboardLayouts = []keyboardLayout{
+	{name: "qwerty", rows: qwertyRows},
+	{name: "colemak", rows: []keyRow{
+		qwertyRows[0],
+		{2, `qwfpgjluy;[]\`, "QWFPGJLUY:{}|"},
+		{3, "arstdhneio'", `ARSTDHNEIO"`},
+		{0, "zxcvbkm,./", "ZXCVBKM<>?"},
+	}},
+	{name: "dvorak", rows: []keyRow{
+		{0, "`1234567890[]", "~!@#$%^&*(){}"},
+		{2, `',.pyfgcrl/=\`, `"<>PYFGCRL?+|`},
+		{3, "aoeuidhtns-", "AOEUIDHTNS_"},
+		{0, ";qjkxbmwvz", ":QJKXBMWVZ"},
+	}},
+	{name: "workman", rows: []keyRow{
+		qwertyRows[0],
+		{2, `qdrwbjfup;[]\`, "QDRWBJFUP:{}|"},
+		{3, "ashtgyneoi'", `ASHTGYNEOI"`},
+		{0, "zxmcvkl,./", "ZXMCVKL<>?"},
+	}},
+}
+
+// layoutNames is indexed like keyboardLayouts.
+func layoutNames() []string {
+	names := make([]string, len(keyboardLayouts))
+	for i, l := range keyboardLayouts {
+		names[i] = l.name
+	}
+	return names
+}
+
+// emulatingLayout reports whether typed keys are being remapped.
+func emulatingLayout(m model) bool {
+	return m.layout > 0
+}
+
+// emulateLayout swaps the characters of a typed key for the emulated
+// layout's.
+func emulateLayout(m model, msg tea.KeyMsg) tea.KeyMsg {
+	if !emulatingLayout(m) || msg.Type != tea.KeyRunes {
+		return msg
+	}
+	rows := keyboardLayouts[m.layout].rows
+	runes := make([]rune, len(msg.Runes))
+	for i, r := range msg.Runes {
+		runes[i] = r
+		if row, col, shift, ok := keyPosition(qwertyRows, r); ok {
+			keys := rows[row].plain
+			if shift {
+				keys = rows[row].shifted
+			}
+			runes[i] = []rune(keys)[col]
+		}
+	}
+	msg.Runes = runes
+	return msg
+}
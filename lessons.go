@@ -0,0 +1,202 @@
+package main
+
+// Lessons: a short course of drills, started from the menu with "n". Each
+// lesson is a lessonWords-word test made only of its keys, starting on the
+// home row and working up to all three rows together (see
+// generateDrillWords). A result with at least the lesson's accuracy and
+// WPM passes it; the results screen says whether it did, and once it has,
+// "n" goes on to the next lesson.
+//
+// Passed lessons are kept in a small JSON file next to the history:
+//
+//   $XDG_DATA_HOME/cli_typer/lessons.json
+//
+// Like the daily challenge, a lesson's fixed setup temporarily replaces
+// the menu's choices, and they're put back on returning to the menu.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const lessonWords = 25
+
+// lesson is one drill in the course.
+type lesson struct {
+	name        string
+	keys        string  // the only keys its words use
+	minAccuracy float64 // percent, to pass
+	minWPM      float64
+}
+
+// lessons is the course, in order.
+var lessons = []lesson{
+	{name: "home row", keys: "asdfjkl;", minAccuracy: 90, minWPM: 15},
+	{name: "top row", keys: "qwertyuiop", minAccuracy: 90, minWPM: 15},
+	{name: "bottom row", keys: "zxcvbnm,./", minAccuracy: 90, minWPM: 12},
+	{name: "mixed", keys: "asdfghjkl;qwertyuiopzxcvbnm,./", minAccuracy: 95, minWPM: 25},
+}
+
+// lessonProgress records the lessons passed, by name.
+type lessonProgress map[string]bool
+
+// lessonIndex finds a lesson by name, or returns -1.
+func lessonIndex(name string) int {
+	for i, l := range lessons {
+		if l.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextLesson is the lesson "n" starts from the menu: the first one not
+// passed yet, or the first of all once every one has been.
+func nextLesson(progress lessonProgress) lesson {
+	for _, l := range lessons {
+		if !progress[l.name] {
+			return l
+		}
+	}
+	return lessons[0]
+}
+
+// startLesson sets up a lesson and starts its test.
+func startLesson(m model, l lesson) model {
+	m = saveMenuChoices(m)
+	m.daily = ""
+	m.contentMode = modeWords
+	m.testMode = testModeWords
+	m.wordCount = lessonWords
+	m.punctuation = false
+	m.difficulty = difficultyNormal
+	m.wordSet = 0
+	m.lesson = l.name
+	return startLessonTest(m)
+}
+
+// startLessonTest starts a fresh test of the current lesson. No seed
+// recreates its words, so it isn't offered one.
+func startLessonTest(m model) model {
+	m = reseed(m)
+	l := lessons[lessonIndex(m.lesson)]
+	m = startTypingTest(m, generateDrillWords(m.rng, l.keys, lessonWords))
+	m.seedValid = false
+	return m
+}
+
+// endLesson leaves the lesson. The caller restores the menu choices.
+func endLesson(m model) model {
+	m.lesson = ""
+	m.lessonPassed = false
+	return m
+}
+
+// checkLesson decides whether a finished lesson test passed, and returns
+// the command saving the progress when it's the lesson's first pass.
+func checkLesson(m model) (model, tea.Cmd) {
+	m.lessonPassed = false
+	i := lessonIndex(m.lesson)
+	if i < 0 {
+		return m, nil
+	}
+	l := lessons[i]
+	m.lessonPassed = m.finalAccuracy >= l.minAccuracy && m.finalWPM >= l.minWPM
+	if !m.lessonPassed || m.lessonProgress[l.name] {
+		return m, nil
+	}
+	if m.lessonProgress == nil {
+		m.lessonProgress = lessonProgress{}
+	}
+	m.lessonProgress[l.name] = true
+	return m, saveLessonProgressCmd(m.lessonProgress)
+}
+
+// startNextLesson goes on from a passed lesson to the one after it,
+// wrapping around to the first after the last.
+func startNextLesson(m model) model {
+	i := lessonIndex(m.lesson)
+	return startLesson(m, lessons[(i+1)%len(lessons)])
+}
+
+func lessonsPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "lessons.json")
+}
+
+func loadLessonProgress() lessonProgress {
+	progress := lessonProgress{}
+	path := lessonsPath()
+	if path == "" {
+		return progress
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return progress
+	}
+	if err := json.Unmarshal(data, &progress); err != nil || progress == nil {
+		return lessonProgress{}
+	}
+	return progress
+}
+
+// saveLessonProgressCmd writes the lessons file in the background.
+func saveLessonProgressCmd(progress lessonProgress) tea.Cmd {
+	// Copy so later updates to the model's map can't race the write
+	snapshot := make(lessonProgress, len(progress))
+	for k, v := range progress {
+		snapshot[k] = v
+	}
+	return func() tea.Msg {
+		path := lessonsPath()
+		if path == "" {
+			return nil
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil
+		}
+		_ = os.WriteFile(path, data, 0o644)
+		return nil
+	}
+}
+
+// renderLessonStatus is the menu line counting the lessons passed and
+// naming the one "n" starts.
+func renderLessonStatus(m model) string {
+	label := styleStatLabel.Render("lessons   ")
+	passed := 0
+	for _, l := range lessons {
+		if m.lessonProgress[l.name] {
+			passed++
+		}
+	}
+	if passed == len(lessons) {
+		return label + styleCorrect.Render("all passed") +
+			styleHint.Render(" "+glyphDash+" press n to go again")
+	}
+	return label + styleStatValue.Render(fmt.Sprintf("%d/%d passed", passed, len(lessons))) +
+		styleHint.Render(fmt.Sprintf(" %s press n for %s", glyphDash, nextLesson(m.lessonProgress).name))
+}
+
+// renderLessonResult is the results screen note saying how the lesson went.
+func renderLessonResult(m model) string {
+	l := lessons[lessonIndex(m.lesson)]
+	note := styleHighlight.Render("lesson " + l.name)
+	if m.lessonPassed {
+		return note + styleHint.Render(" "+glyphDash+" ") + styleCorrect.Render("passed") +
+			styleHint.Render(" "+glyphDash+" n next lesson")
+	}
+	needs := fmt.Sprintf(" %s needs %.0f%% accuracy, %.0f wpm", glyphDash, l.minAccuracy, l.minWPM)
+	return note + styleHint.Render(" "+glyphDash+" ") + styleIncorrect.Render("not passed") + styleHint.Render(needs)
+}
@@ -1,21 +1,277 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	// Initialize audio (non-fatal — game works silently if audio fails)
-	initAudio()
+	wordsFile := flag.String("words-file", "", "path to a custom word list (whitespace-separated)")
+	mode := flag.String("mode", "", "skip the menu and start a game: classic, falling, or invaders")
+	testTime := flag.Int("time", 0, "classic test duration in seconds: 15, 30, or 60")
+	content := flag.String("content", "", "what to type: words, quotes, numbers, code, or custom")
+	cycle := flag.Bool("cycle", false, "day/night cycle (falling mode)")
+	seed := flag.Int64("seed", 0, "generate every test from this seed, to replay or share identical text")
+	noAudio := flag.Bool("no-audio", false, "disable all sound (skips loading sounds entirely)")
+	printJSON := flag.Bool("json", false, "on exit, print the last completed result as JSON to stdout")
+	stdin := flag.Bool("stdin", false, "type the text piped to standard input, in order")
+	inline := flag.Bool("inline", false, "draw in the bottom lines of the terminal instead of the full screen (classic mode only)")
+	ascii := flag.Bool("ascii", false, "draw only ASCII characters, for fonts or terminals that can't show symbols like hearts and blocks")
+	colors := flag.String("colors", "auto", "color support, if the terminal misreports it: auto, truecolor, 256, or 16")
+	exportDir := flag.String("export-dir", "", "directory results are exported to with e (default: the current directory)")
+	exportCSVPath := flag.String("export-csv", "", "write the history to this CSV file and exit")
+	importCSVPath := flag.String("import-csv", "", "add the rows of this CSV file (cli_typer's or monkeytype's) to the history and exit")
+	fetchQuotes := flag.Bool("fetch-quotes", false, "fetch more quotes from the internet in the background (see the online setting)")
+	host := flag.String("host", "", "host a LAN race on this address, e.g. :4040")
+	join := flag.String("join", "", "race the player hosting at this address, e.g. 192.168.1.20:4040")
+	webhookDryRun := flag.Bool("webhook-dry-run", false, "print each result's webhook payload to stdout on exit instead of sending it")
+	spectatePort := flag.Int("spectate-port", 0, "stream classic tests to a web page and WebSocket on this port of localhost")
+	spectateLAN := flag.Bool("spectate-lan", false, "let other machines watch: serve --spectate-port on every network interface")
+	flag.Parse()
+
+	// History import and export don't need the game
+	if *importCSVPath != "" || *exportCSVPath != "" {
+		if err := runCSVFlags(*importCSVPath, *exportCSVPath); err != nil {
+			fmt.Fprintf(os.Stderr, "cli_typer: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := setColorProfile(*colors); err != nil {
+		fmt.Fprintf(os.Stderr, "cli_typer: %v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Before initialModel, whose warnings use the glyphs
+	if *ascii {
+		useASCIIGlyphs()
+	}
+	m := initialModel()
+	m.exportDir = *exportDir
+	m.fetchQuotesFlag = *fetchQuotes
+	m.webhookDryRun = *webhookDryRun
+	// Init starts the fetch
+	m.fetchingQuotes = fetchingQuotesOn(m)
+	if *inline {
+		m.inline = true
+		m.gameMode = gameModeClassic // falling mode needs the full screen
+	}
+	if *wordsFile != "" {
+		words, err := loadWordsFile(*wordsFile)
+		if err != nil {
+			m.menuWarning = fmt.Sprintf("couldn't load words file (%v) %s using built-in words", err, glyphDash)
+		} else {
+			m.customWords = words
+			m.contentMode = modeCustom
+		}
+	}
+
+	// Read piped text before the TUI starts; bubbletea then reads keys
+	// from the terminal itself
+	if *stdin {
+		if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprintln(os.Stderr, "cli_typer: --stdin needs text piped in, e.g. cat article.txt | cli_typer --stdin")
+			os.Exit(2)
+		}
+		words, truncated, err := readTextWords(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cli_typer: --stdin: %v\n", err)
+			os.Exit(2)
+		}
+		m.textWords = words
+		m.textTruncated = truncated
+		m.contentMode = modeText
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["seed"] {
+		m.seed = *seed
+		m.fixedSeed = true
+	}
+
+	// Any of the start flags skips the menu and goes straight into a game
+	if set["mode"] || set["time"] || set["content"] || set["cycle"] || *stdin {
+		var err error
+		m, err = applyStartFlags(m, *mode, *testTime, *content, *cycle, set)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cli_typer: %v\n\n", err)
+			flag.Usage()
+			os.Exit(2)
+		}
+		if m.gameMode != gameModeClassic && m.inline {
+			fmt.Fprintf(os.Stderr, "cli_typer: falling mode needs the full screen, so it can't be combined with --inline\n")
+			os.Exit(2)
+		}
+		if m.gameMode != gameModeClassic {
+			m = initFallingState(m)
+		} else {
+			m = initTypingState(m)
+		}
+	}
+
+	// A race waits in its lobby, with the host's menu choices and start
+	// flags making the test
+	if *host != "" || *join != "" {
+		if *host != "" && *join != "" {
+			fmt.Fprintf(os.Stderr, "cli_typer: --host and --join can't be combined\n")
+			os.Exit(2)
+		}
+		if set["mode"] && m.gameMode != gameModeClassic {
+			fmt.Fprintf(os.Stderr, "cli_typer: races are classic tests, so --mode %s can't be combined with --host or --join\n", *mode)
+			os.Exit(2)
+		}
+		m.gameMode = gameModeClassic // the last menu session may have left falling mode
+		if *host != "" {
+			var err error
+			if m, err = hostRace(m, *host); err != nil {
+				fmt.Fprintf(os.Stderr, "cli_typer: --host: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			m = joinRace(m, *join)
+		}
+	}
+
+	// Viewers can connect from the start; the server closes with the program
+	if *spectateLAN && !set["spectate-port"] {
+		fmt.Fprintf(os.Stderr, "cli_typer: --spectate-lan needs --spectate-port\n\n")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if set["spectate-port"] {
+		if *spectatePort < 1 || *spectatePort > 65535 {
+			fmt.Fprintf(os.Stderr, "cli_typer: invalid --spectate-port %d\n\n", *spectatePort)
+			flag.Usage()
+			os.Exit(2)
+		}
+		spec, err := startSpectator(*spectatePort, *spectateLAN)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cli_typer: --spectate-port: %v\n", err)
+			os.Exit(1)
+		}
+		m.spectator = spec
+	}
+
+	// Initialize audio in the background (non-fatal — game works silently
+	// if audio fails, and sounds start working once it's done)
+	if !*noAudio {
+		go initAudio()
+	}
 
 	// WithAltScreen() takes over the full terminal (like vim does).
 	// When the program exits, the terminal restores to its previous state.
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	// WithReportFocus lets a game pause while the terminal is unfocused
+	// (see focus.go), and WithMouseCellMotion makes the menu clickable.
+	// Inline mode skips both the alt screen and the mouse (see inline.go).
+	opts := []tea.ProgramOption{tea.WithReportFocus()}
+	if !m.inline {
+		opts = append(opts, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(m, opts...)
+	final, err := p.Run()
+	m.spectator.close()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Like --json, a dry run's payloads wait for the normal terminal
+	if fm, ok := final.(model); ok {
+		printWebhookPayloads(fm.webhookPayloads)
+	}
+
+	// Inline, the last result stays behind as a single line
+	if fm, ok := final.(model); ok && m.inline && !*printJSON && fm.lastResult != nil {
+		fmt.Println(resultSummary(fm.lastResult.historyEntry))
+	}
+
+	// The alt screen is gone by now, so this lands in the normal terminal
+	// (or a pipe). Nothing is printed if no test was completed.
+	if fm, ok := final.(model); ok && *printJSON && fm.lastResult != nil {
+		if err := json.NewEncoder(os.Stdout).Encode(fm.lastResult); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// applyStartFlags sets up the menu options from the command-line start
+// flags, rejecting values and combinations the menu wouldn't allow. set
+// holds the names of the flags actually given.
+func applyStartFlags(m model, mode string, seconds int, content string, cycle bool, set map[string]bool) (model, error) {
+	switch mode {
+	case "", "classic":
+		m.gameMode = gameModeClassic
+	case "falling":
+		m.gameMode = gameModeFalling
+	case "invaders":
+		m.gameMode = gameModeInvaders
+	default:
+		return m, fmt.Errorf("unknown --mode %q (want classic, falling, or invaders)", mode)
+	}
+
+	if set["stdin"] {
+		if m.gameMode != gameModeClassic {
+			return m, fmt.Errorf("--stdin only applies to classic mode")
+		}
+		if set["content"] {
+			return m, fmt.Errorf("--stdin can't be combined with --content")
+		}
+	}
+
+	if set["content"] {
+		found := false
+		for cm, name := range contentModeNames {
+			if name == content {
+				m.contentMode = cm
+				found = true
+			}
+		}
+		if !found {
+			return m, fmt.Errorf("unknown --content %q (want words, quotes, numbers, code, or custom)", content)
+		}
+		if m.contentMode == modeCustom && len(m.customWords) == 0 {
+			return m, fmt.Errorf("--content custom needs a word list from --words-file")
+		}
+		if m.contentMode == modeText {
+			return m, fmt.Errorf("--content text is only for text piped in with --stdin")
+		}
+	}
+
+	if set["time"] {
+		if m.gameMode != gameModeClassic {
+			return m, fmt.Errorf("--time only applies to classic mode")
+		}
+		if m.contentMode == modeQuotes {
+			return m, fmt.Errorf("--time doesn't apply to quotes, which are timed until the quote is done")
+		}
+		d := time.Duration(seconds) * time.Second
+		valid := false
+		for _, option := range durations {
+			if d == option {
+				valid = true
+			}
+		}
+		if !valid {
+			return m, fmt.Errorf("invalid --time %d (want 15, 30, or 60)", seconds)
+		}
+		m.testMode = testModeTime
+		m.duration = d
+	}
+
+	if set["cycle"] {
+		if m.gameMode == gameModeClassic {
+			return m, fmt.Errorf("--cycle only applies to falling mode and invaders")
+		}
+		m.dayCycle = cycle
+	}
+	return m, nil
 }
@@ -2,15 +2,38 @@ package main
 
 // The menu screen. Rows depend on the selected game mode:
 //
-// Classic mode (3 rows):
-//   Row 0: game mode  — classic / falling
-//   Row 1: content    — words / quotes
-//   Row 2: duration   — 15s / 30s / 60s
+// Classic mode (9–12 rows):
+//   game      — classic / falling / invaders
+//   words     — words / quotes / numbers / code (/ custom) (/ text)
+//   set       — 200 / 1k / 5k     (words only; see wordsets.go)
+//   punct     — off / on          (words and custom only)
+//   test      — time / words / zen (not for quotes)
+//   duration  — 15s / 30s / 60s   (time test, and piped text)
+//   count     — 10 / 25 / 50 / 100 (word-count test)
+//   length    — all / short / medium / long (quotes only)
+//   quotes    — all / mine        (quotes, once quotes.json has some)
+//   sprints   — off / on          (10-word splits, see sprint.go)
+//   freedom   — off / on          (backspace into mistyped words)
+//   level     — normal / expert / master
+//   blind     — off / on          (mistakes hidden until the results)
+//   ghost     — off / on          (greyed out until a run is saved)
+//   bot       — off / 40–120 / avg (a bot opponent, see bot.go)
 //
-// Falling mode (3 rows):
-//   Row 0: game mode  — classic / falling
-//   Row 1: content    — words / quotes
-//   Row 2: cycle      — off / on
+// Falling mode (7–8 rows; invaders has no format or drift rows):
+//   game      — classic / falling
+//   words     — words / quotes / numbers / code (/ custom)
+//   set       — 200 / 1k / 5k     (words only)
+//   format    — endless / waves / 60s / 120s
+//   lives     — 1 / 3 / 5         (1 is sudden death)
+//   drift     — off / on          (aliens also move sideways)
+//   strict    — off / on          (mistyped keys are rejected)
+//   cycle     — off / on
+//
+// Options shared by every mode (caret, volume, theme) are on the settings
+// screen. Every change is saved to the config file.
+//
+// The mouse works too: clicking a row's label selects it, clicking a value
+// sets it, and clicking "start" starts the game.
 
 import (
 	"fmt"
@@ -20,13 +43,81 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
+type menuRowKind int
+
+const (
+	rowGameMode menuRowKind = iota
+	rowContent
+	rowTestMode
+	rowDuration
+	rowWordCount
+	rowCycle
+	rowPunctuation
+	rowQuoteLength
+	rowFreedom
+	rowDifficulty
+	rowWaves
+	rowGhost
+	rowBlind
+	rowLives
+	rowDrift
+	rowStrict
+	rowSprints
+	rowQuoteSource
+	rowWordSet
+	rowBot
+)
+
+// menuRows returns the rows shown for the current game mode, top to bottom.
+func menuRows(m model) []menuRowKind {
+	rows := []menuRowKind{rowGameMode, rowContent}
+	if m.contentMode == modeWords {
+		rows = append(rows, rowWordSet)
+	}
+	switch m.gameMode {
+	case gameModeFalling:
+		return append(rows, rowWaves, rowLives, rowDrift, rowStrict, rowCycle)
+	case gameModeInvaders:
+		return append(rows, rowLives, rowStrict, rowCycle)
+	}
+	if m.inline {
+		// Falling mode isn't available inline
+		rows = rows[1:]
+	}
+	if m.contentMode == modeQuotes {
+		// Quote tests are a single quote timed with a stopwatch
+		rows = append(rows, rowQuoteLength)
+		if len(m.customQuotes) > 0 {
+			rows = append(rows, rowQuoteSource)
+		}
+	} else if m.contentMode == modeText {
+		// Piped text is always a time test, ending early if it runs out
+		rows = append(rows, rowDuration)
+	} else {
+		if m.contentMode == modeWords || m.contentMode == modeCustom {
+			rows = append(rows, rowPunctuation)
+		}
+		rows = append(rows, rowTestMode)
+		switch m.testMode {
+		case testModeWords:
+			rows = append(rows, rowWordCount)
+		case testModeTime:
+			rows = append(rows, rowDuration)
+		}
+	}
+	return append(rows, rowSprints, rowDifficulty, rowFreedom, rowBlind, rowGhost, rowBot)
+}
+
 func updateMenu(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	if mouse, ok := msg.(tea.MouseMsg); ok {
+		return clickMenu(m, mouse)
+	}
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if !ok {
 		return m, nil
 	}
 
-	maxRow := 2 // both modes have 3 rows now
+	maxRow := len(menuRows(m)) - 1
 
 	switch keyMsg.String() {
 	case "up", "k":
@@ -40,149 +131,439 @@ func updateMenu(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, playSound(soundClick)
 		}
 	case "left", "h":
-		handleMenuLeft(&m)
-		return m, playSound(soundClick)
+		handleMenuChange(&m, -1)
+		return m, tea.Batch(playSound(soundClick), saveConfigCmd(m))
 	case "right", "l":
-		handleMenuRight(&m)
-		return m, playSound(soundClick)
+		handleMenuChange(&m, 1)
+		return m, tea.Batch(playSound(soundClick), saveConfigCmd(m))
 	case "enter":
-		if m.gameMode == gameModeFalling {
-			m = initFallingState(m)
-			return m, fallingTickCmd()
-		}
-		m = initTypingState(m)
-		return m, nil
-	case "q":
-		return m, tea.Quit
+		return startMenuGame(m)
+	case "d":
+		m = startDaily(m)
+		return m, countdownTickCmd(m)
+	case "n":
+		m = startLesson(m, nextLesson(m.lessonProgress))
+		return m, countdownTickCmd(m)
+	case "w":
+		m = startPractice(m)
+		return m, countdownTickCmd(m)
+	case "s":
+		m.state = stateStats
+		m.statsAchievements = false
+		return m, playSound(soundClick)
+	case "o":
+		m.state = stateSettings
+		return m, playSound(soundClick)
+	}
+	if m.keys.is(keyMsg, actionQuit) {
+		return quit(m)
 	}
 
 	return m, nil
 }
 
-func handleMenuLeft(m *model) {
-	switch m.menuRow {
-	case 0: // game mode
-		if m.gameMode == gameModeClassic {
-			m.gameMode = gameModeFalling
-		} else {
-			m.gameMode = gameModeClassic
+// handleMenuChange steps the value of the selected row left (-1) or right (+1).
+func handleMenuChange(m *model, direction int) {
+	rows := menuRows(*m)
+	switch rows[m.menuRow] {
+	case rowGameMode:
+		m.gameMode = gameMode(cycleIndex(int(m.gameMode), len(gameModeNames), direction))
+	case rowContent:
+		m.contentMode = cycleContentMode(*m, direction)
+	case rowTestMode:
+		m.testMode = testMode(cycleIndex(int(m.testMode), len(testModeNames), direction))
+	case rowDuration:
+		m.duration = cycleDuration(m.duration, direction)
+	case rowWordCount:
+		m.wordCount = cycleInt(wordCounts, m.wordCount, direction)
+	case rowCycle:
+		m.dayCycle = !m.dayCycle
+	case rowWaves:
+		setFallingFormat(m, cycleIndex(fallingFormat(*m), 2+len(timeLimitChoices), direction))
+	case rowLives:
+		m.lives = cycleInt(livesChoices, m.lives, direction)
+	case rowDrift:
+		m.drift = !m.drift
+	case rowStrict:
+		m.strict = !m.strict
+	case rowPunctuation:
+		m.punctuation = !m.punctuation
+	case rowQuoteLength:
+		m.quoteLength = quoteLength(cycleIndex(int(m.quoteLength), len(quoteLengthNames), direction))
+	case rowQuoteSource:
+		m.ownQuotes = !m.ownQuotes
+	case rowWordSet:
+		m.wordSet = cycleIndex(m.wordSet, len(wordSets), direction)
+	case rowFreedom:
+		m.freedom = !m.freedom
+	case rowSprints:
+		m.sprints = !m.sprints
+	case rowBlind:
+		m.blind = !m.blind
+	case rowGhost:
+		if ghostRun(*m) != nil {
+			m.ghost = !m.ghost
 		}
-	case 1: // content mode
-		if m.contentMode == modeWords {
-			m.contentMode = modeQuotes
-		} else {
-			m.contentMode = modeWords
-		}
-	case 2: // duration (classic) or cycle (falling)
-		if m.gameMode == gameModeClassic {
-			m.duration = cycleDuration(m.duration, -1)
-		} else {
-			m.dayCycle = !m.dayCycle
+	case rowDifficulty:
+		m.difficulty = difficulty(cycleIndex(int(m.difficulty), len(difficultyNames), direction))
+	case rowBot:
+		if !zenTest(*m) {
+			m.bot = cycleInt(botLevels, m.bot, direction)
 		}
 	}
+
+	// Switching game mode can change the number of rows
+	if maxRow := len(menuRows(*m)) - 1; m.menuRow > maxRow {
+		m.menuRow = maxRow
+	}
+}
+
+func viewMenu(m model) string {
+	return lipgloss.JoinVertical(lipgloss.Left, layoutMenu(m).lines...)
+}
+
+// menuRowSpec describes one menu row: its label, its choices, and which
+// one is selected. Rendering and mouse hit-testing both lay rows out from
+// it, so they always agree on where each choice is.
+type menuRowSpec struct {
+	label    string
+	choices  []string
+	selected int
+	disabled string // why the row can't be changed, "" if it can
+}
+
+func onOffSpec(label string, on bool) menuRowSpec {
+	spec := menuRowSpec{label: label, choices: []string{"off", "on"}}
+	if on {
+		spec.selected = 1
+	}
+	return spec
 }
 
-func handleMenuRight(m *model) {
-	switch m.menuRow {
-	case 0:
-		if m.gameMode == gameModeClassic {
-			m.gameMode = gameModeFalling
-		} else {
-			m.gameMode = gameModeClassic
+func menuRowSpecFor(m model, kind menuRowKind) menuRowSpec {
+	switch kind {
+	case rowGameMode:
+		return menuRowSpec{label: "game", choices: gameModeNames, selected: int(m.gameMode)}
+
+	case rowContent:
+		spec := menuRowSpec{label: "words"}
+		for i, cm := range contentModes(m) {
+			spec.choices = append(spec.choices, contentModeNames[cm])
+			if cm == m.contentMode {
+				spec.selected = i
+			}
+		}
+		return spec
+
+	case rowTestMode:
+		return menuRowSpec{label: "test", choices: testModeNames, selected: int(m.testMode)}
+
+	case rowDuration:
+		spec := menuRowSpec{label: "duration"}
+		for i, d := range durations {
+			spec.choices = append(spec.choices, fmt.Sprintf("%ds", int(d.Seconds())))
+			if d == m.duration {
+				spec.selected = i
+			}
+		}
+		return spec
+
+	case rowWordCount:
+		spec := menuRowSpec{label: "count"}
+		for i, n := range wordCounts {
+			spec.choices = append(spec.choices, fmt.Sprintf("%d", n))
+			if n == m.wordCount {
+				spec.selected = i
+			}
+		}
+		return spec
+
+	case rowCycle:
+		return onOffSpec("cycle", m.dayCycle)
+
+	case rowWaves:
+		spec := menuRowSpec{label: "format", choices: []string{"endless", "waves"}, selected: fallingFormat(m)}
+		for _, n := range timeLimitChoices {
+			spec.choices = append(spec.choices, fmt.Sprintf("%ds", n))
+		}
+		return spec
+
+	case rowLives:
+		spec := menuRowSpec{label: "lives"}
+		for i, n := range livesChoices {
+			spec.choices = append(spec.choices, fmt.Sprintf("%d", n))
+			if n == m.lives {
+				spec.selected = i
+			}
+		}
+		return spec
+
+	case rowDrift:
+		return onOffSpec("drift", m.drift)
+
+	case rowStrict:
+		return onOffSpec("strict", m.strict)
+
+	case rowPunctuation:
+		return onOffSpec("punct", m.punctuation)
+
+	case rowQuoteLength:
+		return menuRowSpec{label: "length", choices: quoteLengthNames, selected: int(m.quoteLength)}
+
+	case rowQuoteSource:
+		spec := menuRowSpec{label: "quotes", choices: []string{"all", fmt.Sprintf("mine (%d)", len(m.customQuotes))}}
+		if m.ownQuotes {
+			spec.selected = 1
+		}
+		return spec
+
+	case rowWordSet:
+		spec := menuRowSpec{label: "set", selected: m.wordSet}
+		for _, s := range wordSets {
+			spec.choices = append(spec.choices, s.label)
 		}
-	case 1:
-		if m.contentMode == modeWords {
-			m.contentMode = modeQuotes
-		} else {
-			m.contentMode = modeWords
+		return spec
+
+	case rowDifficulty:
+		return menuRowSpec{label: "level", choices: difficultyNames, selected: int(m.difficulty)}
+
+	case rowFreedom:
+		return onOffSpec("freedom", m.freedom)
+
+	case rowSprints:
+		return onOffSpec("sprints", m.sprints)
+
+	case rowBlind:
+		return onOffSpec("blind", m.blind)
+
+	case rowGhost:
+		spec := onOffSpec("ghost", m.ghost)
+		if ghostRun(m) == nil {
+			// Nothing to race yet for this setup
+			spec.disabled = "no saved run"
+		}
+		return spec
+
+	case rowBot:
+		spec := menuRowSpec{label: "bot"}
+		for i, b := range botLevels {
+			switch {
+			case b == 0:
+				spec.choices = append(spec.choices, "off")
+			case b == botMatchAverage:
+				spec.choices = append(spec.choices, "avg")
+			default:
+				spec.choices = append(spec.choices, fmt.Sprintf("%d", b))
+			}
+			if b == m.bot {
+				spec.selected = i
+			}
 		}
-	case 2:
-		if m.gameMode == gameModeClassic {
-			m.duration = cycleDuration(m.duration, 1)
-		} else {
-			m.dayCycle = !m.dayCycle
+		if zenTest(m) {
+			// Zen has no end to race to
+			spec.disabled = "not in zen"
 		}
+		return spec
 	}
+	return menuRowSpec{}
 }
 
-func viewMenu(m model) string {
-	title := styleTitle.Render("cli_typer")
-
-	// Row 0: Game mode
-	gameModeLabel := styleStatLabel.Render("game      ")
-	var classicText, fallingText string
-	if m.gameMode == gameModeClassic {
-		classicText = styleHighlight.Render("[ classic ]")
-		fallingText = styleUntyped.Render("  falling ")
-	} else {
-		classicText = styleUntyped.Render("  classic  ")
-		fallingText = styleHighlight.Render("[ falling ]")
+const menuLabelWidth = 10
+
+// menuTarget is what a click on the menu can land on.
+type menuTarget struct {
+	row    int // index into menuRows
+	choice int // index into the row's choices, -1 for the row's label
+	start  bool
+}
+
+// menuRegion is a clickable span on one line of the menu.
+type menuRegion struct {
+	line, col, width int
+	target           menuTarget
+}
+
+// menuLayout is the menu as lines of text plus its clickable regions, in
+// lines and columns from the top left of the menu block.
+type menuLayout struct {
+	lines   []string
+	regions []menuRegion
+}
+
+func layoutMenu(m model) menuLayout {
+	var l menuLayout
+	add := func(line string) {
+		l.lines = append(l.lines, line)
 	}
-	gameModeRow := gameModeLabel + classicText + " " + fallingText
 
-	// Row 1: Content mode
-	modeLabel := styleStatLabel.Render("words     ")
-	var wordsText, quotesText string
-	if m.contentMode == modeWords {
-		wordsText = styleHighlight.Render("[ words ]")
-		quotesText = styleUntyped.Render("  quotes ")
+	// Inline, the warning takes the title's place to save lines
+	if m.inline && m.menuWarning != "" {
+		add(styleIncorrect.Render(m.menuWarning))
 	} else {
-		wordsText = styleUntyped.Render("  words  ")
-		quotesText = styleHighlight.Render("[ quotes ]")
+		add(styleTitle.Render("cli_typer"))
 	}
-	modeRow := modeLabel + wordsText + "  " + quotesText
+	add("")
 
-	// Build the list of rows
-	rows := []string{gameModeRow, modeRow}
+	for i, kind := range menuRows(m) {
+		spec := menuRowSpecFor(m, kind)
+		lineNo := len(l.lines)
 
-	// Row 2: depends on game mode
-	if m.gameMode == gameModeClassic {
-		durLabel := styleStatLabel.Render("duration  ")
-		var durParts []string
-		for _, d := range durations {
-			text := fmt.Sprintf("%ds", int(d.Seconds()))
-			if d == m.duration {
-				durParts = append(durParts, styleHighlight.Render(fmt.Sprintf("[ %s ]", text)))
-			} else {
-				durParts = append(durParts, styleUntyped.Render(fmt.Sprintf("  %s  ", text)))
+		// Arrow indicator for the selected row
+		line := "  "
+		if i == m.menuRow {
+			line = styleHighlight.Render(glyphPointer + " ")
+		}
+		line += styleStatLabel.Render(fmt.Sprintf("%-*s", menuLabelWidth, spec.label))
+		col := 2 + menuLabelWidth
+		l.regions = append(l.regions, menuRegion{line: lineNo, col: 0, width: col, target: menuTarget{row: i, choice: -1}})
+
+		for j, choice := range spec.choices {
+			text := renderChoice(choice, j == spec.selected)
+			if spec.disabled != "" {
+				text = styleUntyped.Render(fmt.Sprintf("  %s  ", choice))
 			}
+			w := lipgloss.Width(text)
+			l.regions = append(l.regions, menuRegion{line: lineNo, col: col, width: w, target: menuTarget{row: i, choice: j}})
+			line += text + " "
+			col += w + 1
 		}
-		durRow := durLabel
-		for _, p := range durParts {
-			durRow += p + " "
+		if spec.disabled != "" {
+			line += styleHint.Render(spec.disabled)
 		}
-		rows = append(rows, durRow)
-	} else {
-		cycleLabel := styleStatLabel.Render("cycle     ")
-		var offText, onText string
-		if m.dayCycle {
-			offText = styleUntyped.Render("  off  ")
-			onText = styleHighlight.Render("[ on ]")
-		} else {
-			offText = styleHighlight.Render("[ off ]")
-			onText = styleUntyped.Render("  on  ")
-		}
-		cycleRow := cycleLabel + offText + "  " + onText
-		rows = append(rows, cycleRow)
-	}
-
-	// Add arrow indicator for selected row
-	var renderedRows []string
-	for i, row := range rows {
-		if i == m.menuRow {
-			renderedRows = append(renderedRows, styleHighlight.Render("▸ ")+row)
-		} else {
-			renderedRows = append(renderedRows, "  "+row)
+		add(line)
+	}
+
+	if !m.inline {
+		add("")
+	}
+	start := renderChoice("start", true)
+	l.regions = append(l.regions, menuRegion{line: len(l.lines), col: 2, width: lipgloss.Width(start), target: menuTarget{start: true}})
+	add("  " + start)
+
+	if !m.inline {
+		add("")
+		add("  " + renderDailyStatus(m))
+		add("  " + renderLessonStatus(m))
+		add("  " + renderPracticeStatus(m))
+		if m.menuWarning != "" {
+			add("")
+			add(styleIncorrect.Render(m.menuWarning))
 		}
+		add("")
 	}
+	add(styleHint.Render(glyphUpDown + " navigate  " + glyphLeftRight + " change  enter start  d daily  s stats  o settings  " + m.keys[actionQuit] + " quit"))
+	return l
+}
 
-	hint := styleHint.Render("↑↓ navigate  ←→ change  enter start  q quit")
+// clickMenu handles a mouse click on the menu: a row's label selects the
+// row, a choice selects the row and sets it, and "start" starts the game.
+func clickMenu(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || screenTooSmall(m) {
+		return m, nil
+	}
 
-	parts := []string{title, ""}
-	parts = append(parts, renderedRows...)
-	parts = append(parts, "", hint)
+	// View centers the menu block, rounding leftover space down on the top
+	// and left
+	layout := layoutMenu(m)
+	blockWidth := 0
+	for _, line := range layout.lines {
+		blockWidth = max(blockWidth, lipgloss.Width(line))
+	}
+	x := msg.X - max(m.width-blockWidth, 0)/2
+	y := msg.Y - max(m.height-len(layout.lines), 0)/2
 
-	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+	for _, r := range layout.regions {
+		if y != r.line || x < r.col || x >= r.col+r.width {
+			continue
+		}
+		switch {
+		case r.target.start:
+			return startMenuGame(m)
+		case r.target.choice < 0:
+			m.menuRow = r.target.row
+			return m, playSound(soundClick)
+		default:
+			m.menuRow = r.target.row
+			selectMenuChoice(&m, r.target.choice)
+			return m, tea.Batch(playSound(soundClick), saveConfigCmd(m))
+		}
+	}
+	return m, nil
+}
+
+// selectMenuChoice sets the selected row to one of its choices. It steps
+// the row with handleMenuChange, exactly as the right arrow would, so
+// mouse and keyboard can't disagree about what a choice does.
+func selectMenuChoice(m *model, choice int) {
+	kind := menuRows(*m)[m.menuRow]
+	for range menuRowSpecFor(*m, kind).choices {
+		if menuRowSpecFor(*m, kind).selected == choice {
+			return
+		}
+		handleMenuChange(m, 1)
+	}
+}
+
+// startMenuGame starts the game set up on the menu.
+func startMenuGame(m model) (tea.Model, tea.Cmd) {
+	if m.gameMode != gameModeClassic {
+		m = initFallingState(m)
+		return m, startFallingCmd(m)
+	}
+	m = initTypingState(m)
+	return m, countdownTickCmd(m)
+}
+
+// renderChoice renders a single menu value, bracketed when selected.
+func renderChoice(text string, selected bool) string {
+	if selected {
+		return styleHighlight.Render(fmt.Sprintf("[ %s ]", text))
+	}
+	return styleUntyped.Render(fmt.Sprintf("  %s  ", text))
+}
+
+// quoteLengthNames is indexed by quoteLength.
+var quoteLengthNames = []string{"all", "short", "medium", "long"}
+
+// difficultyNames is indexed by difficulty.
+var difficultyNames = []string{"normal", "expert", "master"}
+
+// caretNames is indexed by caretStyle.
+var caretNames = []string{"block", "underline", "bar", "off"}
+
+// errorMarkingNames is indexed by errorMarking.
+var errorMarkingNames = []string{"color", "underline", "highlight"}
+
+var contentModeNames = map[contentMode]string{
+	modeWords:   "words",
+	modeQuotes:  "quotes",
+	modeCustom:  "custom",
+	modeNumbers: "numbers",
+	modeCode:    "code",
+	modeText:    "text",
+}
+
+// contentModes lists the content modes selectable in the menu. "custom"
+// only appears when a word list was loaded from --words-file.
+func contentModes(m model) []contentMode {
+	modes := []contentMode{modeWords, modeQuotes, modeNumbers, modeCode}
+	if len(m.customWords) > 0 {
+		modes = append(modes, modeCustom)
+	}
+	if len(m.textWords) > 0 {
+		modes = append(modes, modeText)
+	}
+	return modes
+}
+
+func cycleContentMode(m model, direction int) contentMode {
+	modes := contentModes(m)
+	for i, cm := range modes {
+		if cm == m.contentMode {
+			return modes[(i+direction+len(modes))%len(modes)]
+		}
+	}
+	return modes[0]
 }
 
 func cycleDuration(current time.Duration, direction int) time.Duration {
@@ -200,3 +581,40 @@ func cycleDuration(current time.Duration, direction int) time.Duration {
 	}
 	return current
 }
+
+// cycleIndex steps an index into a list of n options, wrapping at either end.
+func cycleIndex(current, n, direction int) int {
+	return (current + direction + n) % n
+}
+
+// cycleInt steps through a list of int options, wrapping at either end.
+// fallingFormat is the format row's choice: 0 endless, 1 waves, then one
+// for each of timeLimitChoices.
+func fallingFormat(m model) int {
+	if m.waves {
+		return 1
+	}
+	for i, n := range timeLimitChoices {
+		if n == m.timeLimit {
+			return 2 + i
+		}
+	}
+	return 0
+}
+
+func setFallingFormat(m *model, format int) {
+	m.waves = format == 1
+	m.timeLimit = 0
+	if format >= 2 {
+		m.timeLimit = timeLimitChoices[format-2]
+	}
+}
+
+func cycleInt(options []int, current, direction int) int {
+	for i, v := range options {
+		if v == current {
+			return options[(i+direction+len(options))%len(options)]
+		}
+	}
+	return options[0]
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"math/rand"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/stopwatch"
 	"github.com/charmbracelet/bubbles/timer"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -11,17 +15,73 @@ import (
 type gameState int
 
 const (
-	stateMenu    gameState = iota
+	stateMenu gameState = iota
 	stateTyping
 	stateResults
 	stateFalling
+	stateStats
+	stateSettings
+	stateReplay
+	stateRaceLobby
 )
 
 type contentMode int
 
 const (
-	modeWords  contentMode = iota
+	modeWords contentMode = iota
 	modeQuotes
+	modeCustom  // words loaded from --words-file
+	modeNumbers // random numbers for number-row practice
+	modeCode    // tokens from code snippets
+	modeText    // text piped in with --stdin, typed in order
+)
+
+// testMode decides how a classic test ends: when the timer runs out, when
+// the last of a fixed number of words is typed, or when you say so.
+type testMode int
+
+const (
+	testModeTime testMode = iota
+	testModeWords
+	testModeZen // no end until you finish it (see zen.go)
+)
+
+// quoteLength filters which quotes a classic quote test can pick.
+type quoteLength int
+
+const (
+	quoteAll quoteLength = iota
+	quoteShort
+	quoteMedium
+	quoteLong
+)
+
+// caretStyle is how the next character to type is marked.
+type caretStyle int
+
+const (
+	caretBlock     caretStyle = iota // accent background on the next char
+	caretUnderline                   // accent underline on the next char
+	caretBar                         // "|" drawn just before the next char
+	caretOff
+)
+
+// errorMarking is how mistyped characters stand out besides their color,
+// for anyone who can't tell the error color apart from the text color.
+type errorMarking int
+
+const (
+	markColor     errorMarking = iota // the error color alone
+	markUnderline                     // error color, underlined
+	markHighlight                     // error color as the background
+)
+
+type difficulty int
+
+const (
+	difficultyNormal difficulty = iota
+	difficultyExpert            // can't move past a wrong word
+	difficultyMaster            // any mistake fails the test
 )
 
 type gameMode int
@@ -29,32 +89,165 @@ type gameMode int
 const (
 	gameModeClassic gameMode = iota
 	gameModeFalling
+	gameModeInvaders // falling mode with a marching formation (see invaders.go)
 )
 
 // model holds ALL application state.
 type model struct {
 	// Global
-	state  gameState
-	width  int
-	height int
+	state    gameState
+	width    int
+	height   int  // capped at inlineHeight in inline mode
+	inline   bool // drawn below the prompt instead of on the alt screen (see inline.go)
+	quitting bool // the program is exiting; draw nothing
 
 	// Menu
 	menuRow     int
 	gameMode    gameMode
 	contentMode contentMode
+	testMode    testMode
 	duration    time.Duration
+	wordCount   int // words per test (word-count mode)
+	quoteLength quoteLength
+	wordSet     int // index into wordSets (words content only)
+	caret       caretStyle
+	freedom     bool // allow backspacing into a mistyped previous word
+	difficulty  difficulty
+	punctuation bool // capitals and punctuation in generated words (classic only)
 	dayCycle    bool // day/night cycle (falling mode only)
+	waves       bool // waves instead of one endless stream (falling mode only)
+	timeLimit   int  // seconds to survive in the timed format, 0 if untimed
+	lives       int  // falling mode starting lives, one of livesChoices
+	drift       bool // aliens drift sideways (falling mode only)
+	strict      bool // falling keys that don't continue the target are rejected
+	ghost       bool // race the best saved run (classic only)
+	blind       bool // hide mistakes until the results (classic only)
+	sprints     bool // time classic tests in sprints (see sprint.go)
+	bot         int  // bot opponent's WPM, 0 for off (see bot.go)
+	menuWarning string
+
+	// Settings screen (see settings.go)
+	settingsRow  int
+	volume       int  // percent, one of volumeLevels
+	keySounds    bool // click/thud on each classic keypress
+	music        bool // background music in falling mode
+	pace         int  // pace caret target in WPM, 0 for off
+	minWPM       int  // fail below this rolling WPM, 0 for off (see floor.go)
+	minAccuracy  int  // fail below this accuracy percent, 0 for off
+	ignorePaste  bool // drop pasted text in classic tests (see paste.go)
+	keyboard     bool // on-screen keyboard under classic tests
+	layout       int  // index into keyboardLayouts; 0 (qwerty) is no emulation
+	countdown    bool // 3-2-1 countdown before classic tests
+	errorMarking errorMarking
+	wordVariety  wordVariety
+	reduceMotion bool   // no explosions, shots, or turret glide; calm day/night colors
+	pauseOnBlur  bool   // pause games while the terminal is unfocused
+	blurPaused   bool   // the current pause came from focus loss
+	theme        int    // index into themes
+	themeWarning string // why the selected theme couldn't be applied
+
+	// Keybindings (see keymap.go)
+	keys      keymap
+	keyConfig map[string]string // the config file's bindings, saved back as they were
+	muted     bool              // sounds silenced with the mute key, until pressed again
+	help      bool              // the help panel is over the screen (see help.go)
+
+	// Custom word list (from --words-file), nil if none was loaded
+	customWords []string
+
+	// Your own quotes (see quotes.go), nil if there are none
+	customQuotes []quote
+	ownQuotes    bool   // quote tests use only your quotes
+	quoteAuthor  string // who said the quote being typed, "" if unknown
+
+	// Quotes fetched online (see quotefetch.go)
+	onlineQuotes    []quote // the cache, oldest first
+	fetchQuotes     bool    // the settings toggle
+	fetchQuotesFlag bool    // --fetch-quotes, for this run only
+	fetchingQuotes  bool    // a fetch is under way
+
+	// Text from --stdin, nil if none was given
+	textWords     []string
+	textTruncated bool // cut down to maxTextWords
+
+	// Randomness. Each fresh test or game draws from its own seed so it can
+	// be replayed with --seed; when --seed is given, every one reuses it.
+	rng       *rand.Rand
+	seed      int64
+	fixedSeed bool
+	seedValid bool // false for missed-word retries, which no seed recreates
+
+	// Daily challenge (see daily.go)
+	daily      string   // date of the challenge being played, "" otherwise
+	dailyWords []string // the challenge's words, generated once
+
+	// Lessons (see lessons.go)
+	lesson         string         // name of the lesson being played, "" otherwise
+	lessonPassed   bool           // the last lesson result met its thresholds
+	lessonProgress lessonProgress // lessons passed (loaded from disk at startup)
+
+	// Weak-words practice (see weak.go)
+	practice         bool // playing a practice test
+	practiceFallback bool // too few missed words, so it's common words only
+
+	// Menu choices a daily challenge, lesson, or practice replaced, to
+	// restore afterwards
+	savedChoices *menuChoices
+
+	// Completed results, oldest first (loaded from disk at startup)
+	history    []historyEntry
+	lastResult *printedResult // most recent result this session, for --json
+	exportDir  string         // where "e" exports results, "" for the current directory
+
+	// Results webhook (see webhook.go)
+	webhookURL      string // the config file's results_webhook_url, saved back as it was
+	webhookDryRun   bool   // --webhook-dry-run
+	webhookStatus   webhookStatus
+	webhookPayloads [][]byte // a dry run's payloads, printed on exit
+
+	// Notice shown in place of the results hint (see setFlash)
+	flash    string
+	flashErr bool // the notice is an error, and stays until the next result
+	flashID  int  // identifies the live notice; stale clears are dropped
+
+	// Falling mode high scores per content mode (loaded from disk at startup)
+	fallingRecords    fallingRecords
+	newSurvivalRecord bool
+	prevBestSurvived  int
+
+	// Achievements unlocked so far (loaded from disk at startup)
+	achievements      achievementUnlocks
+	statsAchievements bool // the stats screen is on its achievements page
 
 	// Classic typing test
-	words     []string
-	input     [][]rune
-	wordIndex int
-	charIndex int
+	words      []string
+	input      [][]rune
+	wordIndex  int
+	charIndex  int
+	keystrokes []int      // keystrokes per elapsed second, for consistency
+	keyLog     []keyEvent // every edit to the text, for the replay
+	keyMisses  keyMisses  // wrong keystrokes by expected character
+	missedKeys []rune     // the keyboard's recently missed keys, oldest first
+	attempt    int        // times this exact text has been typed, from 1
+
+	// Classic timer (time tests) and stopwatch (quote tests)
+	timer         timer.Model
+	stopwatch     stopwatch.Model
+	timerStarted  bool
+	countdownLeft int       // seconds left on the countdown, 0 when not counting
+	countdownID   int       // identifies the live countdown; stale ticks are dropped
+	startTime     time.Time // shifted forward on resume so pauses don't count
+	paused        bool
+	pausedAt      time.Time
 
-	// Classic timer
-	timer        timer.Model
-	timerStarted bool
-	startTime    time.Time
+	confirmingQuit bool // the pause screen is asking before quitting (see confirm.go)
+
+	spaceRejected bool   // expert mode refused the last space (flash the word)
+	testFailed    bool   // master mode ended the test on a mistake
+	failReason    string // the floor that failed the test, "" for master's mistakes
+
+	// Elapsed time at the end of each full sprint (see sprint.go)
+	splits []time.Duration
 
 	// Results (shared between modes)
 	finalWPM      float64
@@ -63,25 +256,108 @@ type model struct {
 	totalChars    int
 	correctWords  int
 	totalWords    int
+	finalTime     float64 // seconds the test actually took
+
+	finalRawWPM      float64
+	finalConsistency float64
+	hasConsistency   bool      // false when there were too few samples
+	wpmSamples       []float64 // raw WPM for each full second
+	missedWords      []string  // distinct words typed incorrectly
+	reviewing        bool      // results show the typed text instead of stats
+
+	// Replay of the last classic test (see replay.go)
+	replayInput     [][]rune
+	replayWordIndex int
+	replayPos       int // next entry of keyLog to play
+	replayID        int // identifies the live replay; stale ticks are dropped
+
+	// Ghost race against the best saved run (see ghost.go)
+	ghostKeys      []keyEvent // the run's keystroke log, nil when not racing
+	ghostWPM       float64
+	ghostLens      []int // typed length of each of the run's words so far
+	ghostWordIndex int
+	ghostPos       int // next entry of ghostKeys to apply
+	ghostTickID    int // identifies the live ghost tick loop
+
+	// Bot opponent scheduled through the current test (see bot.go)
+	botWPM    float64
+	botFinish []time.Duration // when the bot finishes each word, nil for no bot
+	botTickID int
+	botRace   raceResult // how the bot did, once the test is over
+
+	// LAN race against another player (see race.go)
+	race               *raceConn // nil when not racing
+	raceLost           string    // why the opponent is gone, once they are
+	raceOpponent       raceProgress
+	raceResult         *raceResult // ours, once the test is done
+	raceOpponentResult *raceResult
+
+	// Viewers of --spectate-port (see spectate.go)
+	spectator      *spectator // nil without the flag
+	spectateTickID int
+
+	paceTickID  int // identifies the live pace caret tick loop (see pace.go)
+	floorTickID int // identifies the live fail floor tick loop (see floor.go)
+
+	// Personal best (set when a result is recorded)
+	newPersonalBest bool
+	prevBest        float64 // previous best WPM (classic) or score (falling)
+	hasPrevBest     bool
 
 	// Falling words mode
-	fallingWords     []fallingWord  // active words on screen
-	fallingInput     []rune         // what the user is currently typing
-	fallingTarget    int            // index of targeted word, or -1
-	fallingLives     int            // starts at 3, game over at 0
-	fallingScore     int            // words destroyed
-	fallingSpeed     float64        // rows per tick (increases over time)
-	fallingSpawnCD   int            // ticks until next word spawns
-	fallingTicks     int            // total ticks elapsed
-	fallingStartTime time.Time      // for "time survived"
-	fallingGameOver  bool
-	fallingCharsTyped int           // total chars in destroyed words (for WPM)
+	fallingWords       []fallingWord // active words on screen
+	fallingInput       []rune        // what the user is currently typing
+	fallingTarget      int           // index of targeted word, or -1
+	fallingNextID      int           // last ID handed to a spawned word
+	fallingLives       int           // starts at m.lives, game over at 0
+	fallingScore       int           // points (see destroyPoints), net of leaks
+	fallingDestroyed   int           // words destroyed
+	fallingCombo       int           // destroys in a row without a mistype or leak
+	fallingBestCombo   int           // longest streak this game
+	fallingStreak      int           // destroys since the last leak
+	fallingBestStreak  int           // longest run of destroys without a leak
+	fallingLongestWord string        // longest word destroyed
+	fallingPeakAliens  int           // most aliens on screen at once
+	fallingDecoysShot  int           // friendly astronauts shot down
+	fallingSpeed       float64       // rows per second (increases over time)
+	fallingSpawnCD     int           // ticks until next word spawns
+	fallingTicks       int           // total game ticks elapsed
+	fallingFrames      int           // total frames elapsed (framesPerTick per tick)
+	fallingStartTime   time.Time     // for "time survived" (shifted forward on resume)
+	fallingEndTime     time.Time     // when the game ended
+	fallingPaused      bool
+	fallingPausedAt    time.Time
+	fallingTickID      int // identifies the live tick loop; stale ticks are dropped
+	fallingGameOver    bool
+	fallingReadyFrames int       // frames left on the "get ready" countdown
+	fallingWon         bool      // a timed game lasted the full time
+	fallingCharsTyped  int       // total chars in destroyed words (for WPM)
+	fallingKeystrokes  int       // every rune typed (for accuracy)
+	fallingMistypes    int       // runes that didn't continue a target's word
+	fallingTypoFlash   int       // ticks left flashing the input after a mistype
+	fallingLeaked      int       // aliens that reached the shield
+	fallingWarnings    int       // aliens that came within dangerRows of it
+	fallingLeakPoints  int       // points leaks took off the score
+	fallingFreezeTicks int       // ticks left on an active freeze power-up
+	fallingFrozenTicks int       // total ticks spent frozen (excluded from difficulty)
+	fallingMinWidth    int       // terminal width the game needs (see fallingMinWidth)
+	fallingFormation   formation // invaders only: the marching formation
+
+	// Waves format (falling mode with m.waves set)
+	fallingWave          int        // current wave, from 1
+	fallingWaveSpawned   int        // aliens spawned so far this wave
+	fallingWaveDestroyed int        // aliens destroyed this wave
+	fallingWaveLeaked    int        // aliens leaked this wave
+	fallingWaveBreak     int        // ticks left on the "wave cleared" interstitial
+	fallingWaveStats     []waveStat // finished waves, for the game over screen
 
 	// Turret + effects
-	turretX      int           // current X position of the turret
-	turretStartX int           // turret X when target was acquired (for interpolation)
-	explosions   []explosion   // active explosion animations
-	laser        *laserBeam    // active laser beam (nil if none)
+	turretX      int          // current X position of the turret
+	turretStartX int          // turret X when target was acquired (for interpolation)
+	explosions   []explosion  // active explosion animations
+	scorePopups  []scorePopup // points floating up from destroys
+	shots        []shot       // laser shots on their way up
+	fallingGrid  *cellGrid    // playfield cells, reused between frames
 }
 
 var durations = []time.Duration{
@@ -90,47 +366,226 @@ var durations = []time.Duration{
 	60 * time.Second,
 }
 
+var wordCounts = []int{10, 25, 50, 100}
+
 func initialModel() model {
-	return model{
-		state:    stateMenu,
-		duration: 30 * time.Second,
+	m := model{
+		state:          stateMenu,
+		rng:            rand.New(rand.NewSource(rand.Int63())),
+		duration:       30 * time.Second,
+		wordCount:      25,
+		lives:          defaultFallingLives,
+		volume:         100,
+		history:        loadHistory(),
+		fallingRecords: loadFallingRecords(),
+		lessonProgress: loadLessonProgress(),
+		achievements:   loadAchievements(),
+	}
+	themes = append(themes, loadCustomThemes()...)
+	quotesWarning := loadQuotesWarning(&m)
+	m.onlineQuotes = loadQuoteCache()
+	m = applyConfig(m, loadConfig())
+	if m.themeWarning != "" && m.menuWarning == "" {
+		m.menuWarning = m.themeWarning
+	}
+	if quotesWarning != "" && m.menuWarning == "" {
+		m.menuWarning = quotesWarning
+	}
+	return m
+}
+
+// endsOnLastWord reports whether the current classic test finishes when the
+// last word is typed rather than when the timer runs out.
+func endsOnLastWord(m model) bool {
+	return m.testMode == testModeWords || m.contentMode == modeQuotes
+}
+
+// testLength is how many words a fresh classic test needs.
+func testLength(m model) int {
+	switch m.testMode {
+	case testModeWords:
+		return m.wordCount
+	case testModeZen:
+		return zenChunk
+	}
+	return 200
+}
+
+// reseed starts a new random sequence for a fresh test or game: the
+// fixed --seed if one was given, otherwise a new random seed.
+func reseed(m model) model {
+	if !m.fixedSeed {
+		m.seed = rand.Int63()
 	}
+	m.rng = rand.New(rand.NewSource(m.seed))
+	m.seedValid = true
+	return m
 }
 
 // initTypingState sets up a fresh classic typing session.
 func initTypingState(m model) model {
+	if m.daily != "" {
+		return startDailyTest(m)
+	}
+	if m.lesson != "" {
+		return startLessonTest(m)
+	}
+	if m.practice {
+		return startPracticeTest(m)
+	}
+	m = reseed(m)
+	count := testLength(m)
+
 	var words []string
-	if m.contentMode == modeQuotes {
-		words = getQuoteWords(200)
-	} else {
-		words = generateWords(200)
+	var author string
+	switch m.contentMode {
+	case modeQuotes:
+		// A quote test is exactly one quote
+		q := pickQuote(m.rng, quotePool(m), m.quoteLength)
+		words, author = strings.Fields(q.text), q.author
+	case modeText:
+		// The whole text, in order, against the clock
+		m.testMode = testModeTime
+		words = append([]string(nil), m.textWords...)
+		m.seedValid = false
+	default:
+		words = generateTestWords(m, count)
 	}
+	m = startTypingTest(m, words)
+	m.quoteAuthor = author
+	return loadGhost(m)
+}
 
+// generateTestWords generates count words of the menu's content from the
+// test's random sequence.
+func generateTestWords(m model, count int) []string {
+	switch m.contentMode {
+	case modeNumbers:
+		return generateNumbers(m.rng, count)
+	case modeCode:
+		return generateCode(m.rng, count)
+	}
+	words := varietyWords(m, wordPool(m), count)
+	if m.punctuation {
+		words = addPunctuation(m.rng, words)
+	}
+	return words
+}
+
+// startTypingTest resets the classic typing state to type the given words.
+func startTypingTest(m model, words []string) model {
 	m.state = stateTyping
 	m.words = words
 	m.input = make([][]rune, len(words))
 	m.wordIndex = 0
 	m.charIndex = 0
+	m.keystrokes = nil
+	m.keyLog = nil
+	m.keyMisses = keyMisses{}
+	m.missedKeys = nil
+	m.reviewing = false
+	m.flash = ""
+	m.attempt = 1
+	m.ghostKeys = nil
+	m.paceTickID++
+	m.spectateTickID++
+	m.paused = false
+	m.spaceRejected = false
+	m.testFailed = false
+	m.failReason = ""
+	m.floorTickID++
+	m.splits = nil
+	m.quoteAuthor = ""
 	m.timerStarted = false
-	m.timer = timer.NewWithInterval(m.duration, time.Second)
-	return m
+	m.countdownLeft = 0
+	if m.countdown {
+		m = startCountdown(m)
+	}
+	// Zen tests have nothing to count down to
+	m.timer = timer.Model{}
+	if !zenTest(m) {
+		m.timer = timer.NewWithInterval(m.duration, time.Second)
+	}
+	m.stopwatch = stopwatch.NewWithInterval(time.Second)
+	return loadBot(m)
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	// main marks a startup quote fetch; it's started here
+	var fetch tea.Cmd
+	if m.fetchingQuotes {
+		fetch = fetchQuotesCmd(m)
+	}
+	// A falling game started from the command line needs its tick loop
+	if m.state == stateFalling {
+		return tea.Batch(startFallingCmd(m), fetch)
+	}
+	// So does a race from --host or --join
+	var race tea.Cmd
+	if m.race != nil {
+		race = raceConnectCmd(m.race)
+	}
+	return tea.Batch(countdownTickCmd(m), fetch, race)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		oldWidth, oldHeight := m.width, m.height
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.inline {
+			m.height = min(msg.Height, inlineHeight)
+		}
+		if m.state == stateFalling {
+			return resizeFalling(m, oldWidth, oldHeight)
+		}
+		// A test in progress waits out the guard screen paused, unless
+		// it's a race, which the opponent won't wait for
+		if m.state == stateTyping && m.timerStarted && !m.paused && !racing(m) && screenTooSmall(m) {
+			return pauseTyping(m)
+		}
 		return m, nil
 	}
 
+	switch msg := msg.(type) {
+	case tea.FocusMsg:
+		return handleFocus(m, true)
+	case tea.BlurMsg:
+		return handleFocus(m, false)
+	case quotesFetchedMsg:
+		return handleQuotesFetched(m, msg)
+	case webhookSentMsg:
+		return handleWebhookSent(m, msg), nil
+	case raceConnectedMsg, raceLostMsg, raceMsg, raceTickMsg:
+		return handleRaceMsg(m, msg)
+	}
+
 	if msg, ok := msg.(tea.KeyMsg); ok && msg.Type == tea.KeyCtrlC {
-		return m, tea.Quit
+		return quit(m)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case m.help:
+			return closeHelp(m)
+		case m.keys.is(msg, actionMute):
+			return toggleMute(m)
+		case m.keys.is(msg, actionHelp) && helpAvailable(m):
+			return openHelp(m)
+		}
 	}
 
+	next, cmd := m.update(msg)
+
+	// Leaving the daily challenge, a lesson, practice, or a race for the
+	// menu restores the menu's choices
+	if nm, ok := next.(model); ok && nm.state == stateMenu && nm.savedChoices != nil {
+		return restoreMenuChoices(endRace(endPractice(endLesson(endDaily(nm))))), cmd
+	}
+	return next, cmd
+}
+
+// update hands a message to the current screen.
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.state {
 	case stateMenu:
 		return updateMenu(m, msg)
@@ -140,16 +595,88 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return updateResults(m, msg)
 	case stateFalling:
 		return updateFalling(m, msg)
+	case stateStats:
+		return updateStats(m, msg)
+	case stateSettings:
+		return updateSettings(m, msg)
+	case stateReplay:
+		return updateReplay(m, msg)
+	case stateRaceLobby:
+		return updateRaceLobby(m, msg)
 	}
 
 	return m, nil
 }
 
+// minScreenSize is the smallest terminal the current screen fits in
+// without wrapping or overlapping.
+func minScreenSize(m model) (width, height int) {
+	if m.inline {
+		// Every screen has a layout that fits the inline lines
+		width, _ = minScreenSize(model{state: m.state})
+		return width, inlineHeight
+	}
+	switch m.state {
+	case stateFalling:
+		width, height = max(minFallingWidth, m.fallingMinWidth), minFallingHeight
+		if invadersGame(m) {
+			height = minInvadersHeight
+		}
+		if m.fallingGameOver {
+			// The stats can outgrow the game, with waves especially
+			content := fallingGameOverContent(m)
+			width = max(width, lipgloss.Width(content))
+			height = max(height, lipgloss.Height(content))
+		}
+		return width, height
+	case stateTyping, stateReplay, stateRaceLobby:
+		return 40, 9
+	case stateSettings:
+		return 50, 23
+	case stateMenu:
+		return 60, 24
+	case stateResults:
+		return 60, 20
+	case stateStats:
+		return 60, 24
+	}
+	return 0, 0
+}
+
+// screenTooSmall reports whether the terminal is below the current
+// screen's minimum size.
+func screenTooSmall(m model) bool {
+	w, h := minScreenSize(m)
+	return m.width < w || m.height < h
+}
+
+// viewTooSmall replaces any screen while the terminal is too small for it.
+// Nothing is lost: the screen comes back as soon as the terminal is big
+// enough, and games in progress are paused meanwhile.
+func viewTooSmall(m model) string {
+	w, h := minScreenSize(m)
+	msg := styleIncorrect.Render("terminal too small") + "\n" +
+		styleHint.Render(fmt.Sprintf("need at least %d%s%d, have %d%s%d", w, glyphTimes, h, m.width, glyphTimes, m.height))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, msg)
+}
+
 func (m model) View() string {
-	if m.width == 0 {
+	if m.width == 0 || m.quitting {
 		return ""
 	}
+	if screenTooSmall(m) {
+		return viewTooSmall(m)
+	}
+
+	view := m.view()
+	if m.help {
+		return overlayHelp(m, view)
+	}
+	return view
+}
 
+// view draws the current screen.
+func (m model) view() string {
 	switch m.state {
 	case stateFalling:
 		// Falling mode manages its own full-screen layout
@@ -163,6 +690,14 @@ func (m model) View() string {
 			content = viewTyping(m)
 		case stateResults:
 			content = viewResults(m)
+		case stateStats:
+			content = viewStats(m)
+		case stateSettings:
+			content = viewSettings(m)
+		case stateReplay:
+			content = viewReplay(m)
+		case stateRaceLobby:
+			content = viewRaceLobby(m)
 		}
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 	}
@@ -0,0 +1,184 @@
+package main
+
+// Background music for falling mode (toggled on the settings screen).
+//
+// The built-in loop isn't a sound file: it's a soft chord pad (Am F C G)
+// synthesized into a buffer when audio starts, then repeated with
+// beep.Loop. Each chord fades in and out on its own, so the loop point is
+// seamless. A music file in the sound pack (see soundpack.go) replaces it
+// and is looped the same way, at the level it was recorded at.
+//
+// While music plays, every effect sound ducks it for a moment so
+// explosions and hits stay audible. All music state is only touched with
+// the speaker locked, since the speaker's goroutine reads it.
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/speaker"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// musicName is the sound pack file that replaces the pad, as music.ogg or
+// music.wav.
+const musicName = "music"
+
+const (
+	musicChordLength = 2500 * time.Millisecond
+	musicLevel       = 0.12 // peak amplitude of the pad, kept well under effects
+	duckLevel        = 0.35 // music gain while an effect plays
+	duckLength       = 400 * time.Millisecond
+)
+
+// musicChords are the frequencies (Hz) of each chord in the loop.
+var musicChords = [][]float64{
+	{110.00, 130.81, 164.81}, // Am
+	{87.31, 110.00, 130.81},  // F
+	{130.81, 164.81, 196.00}, // C
+	{98.00, 123.47, 146.83},  // G
+}
+
+// music is the player while music is playing, nil otherwise.
+var music *musicPlayer
+
+// buildMusicLoop synthesizes one pass of the chord loop.
+func buildMusicLoop(format beep.Format) *beep.Buffer {
+	sr := float64(format.SampleRate)
+	chordSamples := format.SampleRate.N(musicChordLength)
+	total := chordSamples * len(musicChords)
+
+	pos := 0
+	pad := beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		if pos >= total {
+			return 0, false
+		}
+		n := 0
+		for ; n < len(samples) && pos < total; n++ {
+			chord := musicChords[pos/chordSamples]
+			within := float64(pos%chordSamples) / float64(chordSamples)
+			env := math.Sin(math.Pi * within) // fade in and out per chord
+			t := float64(pos) / sr
+
+			var left, right float64
+			for _, f := range chord {
+				left += math.Sin(2 * math.Pi * f * t)
+				right += math.Sin(2 * math.Pi * f * 1.003 * t) // slight detune for width
+			}
+			scale := musicLevel * env / float64(len(chord))
+			samples[n] = [2]float64{left * scale, right * scale}
+			pos++
+		}
+		return n, true
+	})
+
+	buf := beep.NewBuffer(format)
+	buf.Append(pad)
+	return buf
+}
+
+// musicPlayer streams the loop, scaled by the volume and the duck.
+type musicPlayer struct {
+	loop      beep.Streamer
+	gain      float64
+	paused    bool
+	stopped   bool
+	duckLeft  int // samples left at the ducked level
+	duckTotal int
+}
+
+func (p *musicPlayer) Stream(samples [][2]float64) (int, bool) {
+	if p.stopped {
+		return 0, false
+	}
+	if p.paused {
+		for i := range samples {
+			samples[i] = [2]float64{}
+		}
+		return len(samples), true
+	}
+	n, ok := p.loop.Stream(samples)
+	for i := 0; i < n; i++ {
+		g := p.gain
+		if p.duckLeft > 0 {
+			g *= duckLevel
+			p.duckLeft--
+		}
+		samples[i][0] *= g
+		samples[i][1] *= g
+	}
+	return n, ok
+}
+
+func (p *musicPlayer) Err() error { return nil }
+
+// startMusicCmd starts the music loop if it's turned on and not already
+// playing.
+func startMusicCmd(m model) tea.Cmd {
+	bank := audio.Load()
+	if bank == nil || bank.music == nil || !m.music || soundVolume <= 0 {
+		return nil
+	}
+	gain := float64(soundVolume) / 100
+	return func() tea.Msg {
+		speaker.Lock()
+		if music != nil {
+			music.paused = false
+			speaker.Unlock()
+			return nil
+		}
+		p := &musicPlayer{
+			loop:      beep.Loop(-1, bank.music.Streamer(0, bank.music.Len())),
+			gain:      gain,
+			duckTotal: bank.format.SampleRate.N(duckLength),
+		}
+		music = p
+		speaker.Unlock()
+		speaker.Play(p)
+		return nil
+	}
+}
+
+// stopMusicCmd stops the music loop, if it's playing.
+func stopMusicCmd() tea.Cmd {
+	if !audioReady() {
+		return nil
+	}
+	return func() tea.Msg {
+		speaker.Lock()
+		if music != nil {
+			music.stopped = true
+			music = nil
+		}
+		speaker.Unlock()
+		return nil
+	}
+}
+
+// pauseMusicCmd silences the music without losing its place;
+// startMusicCmd resumes it.
+func pauseMusicCmd() tea.Cmd {
+	if !audioReady() {
+		return nil
+	}
+	return func() tea.Msg {
+		speaker.Lock()
+		if music != nil {
+			music.paused = true
+		}
+		speaker.Unlock()
+		return nil
+	}
+}
+
+// duckMusic briefly lowers the music under an effect sound. The caller
+// must not hold the speaker lock.
+func duckMusic() {
+	speaker.Lock()
+	if music != nil {
+		music.duckLeft = music.duckTotal
+	}
+	speaker.Unlock()
+}
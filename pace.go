@@ -0,0 +1,67 @@
+package main
+
+// The pace caret. With a target set on the settings screen, a second caret
+// moves through a classic test at exactly that speed — target WPM × 5
+// characters a minute, spaces included — so you can see at a glance
+// whether you're ahead or behind. It starts with the first keypress and
+// stops on the last character of the text.
+//
+// Its position is worked out from the elapsed time on every render; a tick
+// several times a second keeps renders coming between keypresses.
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paceLevels are the settings screen's pace targets in WPM; 0 is off.
+var paceLevels = []int{0, 40, 60, 80, 100, 120}
+
+const paceInterval = 100 * time.Millisecond
+
+type paceTickMsg struct {
+	id int
+}
+
+func paceTickCmd(m model) tea.Cmd {
+	if m.pace <= 0 {
+		return nil
+	}
+	id := m.paceTickID
+	return tea.Tick(paceInterval, func(time.Time) tea.Msg {
+		return paceTickMsg{id: id}
+	})
+}
+
+// pacePosition returns the word and character the pace caret is on. A
+// character index equal to the word's length is the space after it. ok is
+// false when there's no pace caret to draw.
+func pacePosition(m model) (word, char int, ok bool) {
+	if m.pace <= 0 || m.state != stateTyping || !m.timerStarted || len(m.words) == 0 {
+		return 0, 0, false
+	}
+	chars := int(typingElapsed(m).Minutes() * float64(m.pace) * 5)
+	last := len(m.words) - 1
+	for i, w := range m.words {
+		n := len([]rune(w))
+		if chars <= n {
+			if i == last && chars == n {
+				break
+			}
+			return i, chars, true
+		}
+		chars -= n + 1
+	}
+	return last, max(len([]rune(m.words[last]))-1, 0), true
+}
+
+// paceCaretAt reports whether the pace caret is on the given character of
+// the given word. The player's own caret takes precedence.
+func paceCaretAt(m model, wordIdx, charIdx int) bool {
+	if wordIdx == m.wordIndex && charIdx == len(m.input[wordIdx]) {
+		return false
+	}
+	w, c, ok := pacePosition(m)
+	return ok && w == wordIdx && c == charIdx
+}
@@ -0,0 +1,23 @@
+package main
+
+// Pasted text. Bubbletea hands over a paste as a single key message
+// carrying every character, marked as a paste when the terminal brackets
+// pastes, and a slow terminal can send fast typing together the same way.
+// Classic tests and falling games type every character of such a message
+// in turn, so none go missing.
+//
+// What a paste does in a classic test is the settings screen's paste row:
+// "typed" (the default) types it like any other keys, spaces and line
+// breaks moving on a word; "ignored" drops it whole, so a result is always
+// typed by hand. A terminal that doesn't bracket pastes can't say which
+// messages are pastes, so there any message of more than pasteBatchRunes
+// characters counts as one, more than even fast typing gets batched into.
+
+import tea "github.com/charmbracelet/bubbletea"
+
+const pasteBatchRunes = 10
+
+// pastedKey reports whether msg looks pasted rather than typed.
+func pastedKey(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes && (msg.Paste || len(msg.Runes) > pasteBatchRunes)
+}
@@ -0,0 +1,91 @@
+package main
+
+// Problem keys: which characters get mistyped most, and what as.
+//
+// Every wrong keystroke inside a word is counted against the character that
+// should have been typed. Overflow characters (typed past the end of a
+// word) and spaces pressed early aren't substitutions for any particular
+// character, so they're left out. The counts are saved with each classic
+// result in history, so the stats screen can add them up over every test.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxProblemKeys = 5
+
+// keyMisses counts wrong keystrokes: expected character → typed character
+// → count. Keys are strings so it can be stored in history as-is.
+type keyMisses map[string]map[string]int
+
+func (k keyMisses) add(expected, typed rune) {
+	subs := k[string(expected)]
+	if subs == nil {
+		subs = map[string]int{}
+		k[string(expected)] = subs
+	}
+	subs[string(typed)]++
+}
+
+// merge adds every count in other to k.
+func (k keyMisses) merge(other keyMisses) {
+	for expected, subs := range other {
+		for typed, n := range subs {
+			if k[expected] == nil {
+				k[expected] = map[string]int{}
+			}
+			k[expected][typed] += n
+		}
+	}
+}
+
+type problemKey struct {
+	char   string
+	misses int
+	sub    string // the most common wrong character typed instead
+}
+
+// topProblemKeys returns up to n characters with the most misses, most
+// missed first.
+func topProblemKeys(k keyMisses, n int) []problemKey {
+	var keys []problemKey
+	for expected, subs := range k {
+		p := problemKey{char: expected}
+		best := 0
+		for typed, count := range subs {
+			p.misses += count
+			if count > best || (count == best && typed < p.sub) {
+				best = count
+				p.sub = typed
+			}
+		}
+		keys = append(keys, p)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].misses != keys[j].misses {
+			return keys[i].misses > keys[j].misses
+		}
+		return keys[i].char < keys[j].char
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// renderProblemKeys lists the most missed characters, each with what was
+// most often typed instead, like "e → r ×7".
+func renderProblemKeys(k keyMisses) string {
+	label := styleStatLabel.Render("problem keys ")
+	top := topProblemKeys(k, maxProblemKeys)
+	if len(top) == 0 {
+		return label + styleCorrect.Render("none")
+	}
+	var items []string
+	for _, p := range top {
+		items = append(items, fmt.Sprintf("%s %s %s %s%d", p.char, glyphArrow, p.sub, glyphTimes, p.misses))
+	}
+	return label + styleIncorrect.Render(strings.Join(items, "  "))
+}
@@ -0,0 +1,220 @@
+package main
+
+// Quotes from the internet. With the settings screen's "online" row on, or
+// for one run with --fetch-quotes, a batch of quotes is fetched from
+// quoteAPI in the background at startup (and when the row is turned on)
+// and kept in a cache file in the data directory:
+//
+//   $XDG_DATA_HOME/cli_typer/quotes_cache.json   (default ~/.local/share/cli_typer/)
+//
+// Cached quotes join the built-in ones for as long as fetching is on, so
+// the pool grows a batch at a time and works offline between fetches. A
+// failed fetch changes nothing and says nothing: the quotes already there
+// are used as before.
+//
+// Fetched quotes are made typeable like the built-in ones: punctuation is
+// dropped (dashes and slashes split words), and a quote with characters
+// that aren't on the keyboard, or too few words, is left out. A quote
+// already cached or built in isn't added twice. The cache keeps the newest
+// quoteCacheMax quotes.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	quoteAPI          = "https://api.quotable.io/quotes/random?limit=50"
+	quoteFetchTimeout = 10 * time.Second
+	quoteCacheMax     = 500
+	quoteMinWords     = 4
+)
+
+// cachedQuote is a quote as the cache file stores it.
+type cachedQuote struct {
+	Text   string `json:"text"`
+	Author string `json:"author,omitempty"`
+}
+
+// quotesFetchedMsg carries the cache after a fetch; err is set if the
+// fetch or the write failed, and quotes is then nil.
+type quotesFetchedMsg struct {
+	quotes []quote
+	err    error
+}
+
+func quoteCachePath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "quotes_cache.json")
+}
+
+// fetchingQuotesOn reports whether online quotes are in use this run.
+func fetchingQuotesOn(m model) bool {
+	return m.fetchQuotes || m.fetchQuotesFlag
+}
+
+// loadQuoteCache reads the cached quotes, oldest first. A missing or
+// unreadable cache is no quotes.
+func loadQuoteCache() []quote {
+	path := quoteCachePath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entries []cachedQuote
+	if json.Unmarshal(data, &entries) != nil {
+		return nil
+	}
+	var qs []quote
+	for _, e := range entries {
+		if text, ok := sanitizeQuote(e.Text); ok {
+			qs = append(qs, quote{text: text, author: strings.TrimSpace(e.Author)})
+		}
+	}
+	return qs
+}
+
+// sanitizeQuote makes fetched text typeable the way the built-in quotes
+// are: letters, digits, and single spaces. ok is false if the text has
+// other characters that can't simply be dropped, or too few words.
+func sanitizeQuote(text string) (string, bool) {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '/' || r == '–' || r == '—':
+			b.WriteRune(' ')
+		case unicode.IsPunct(r):
+			// dropped, like the built-in quotes' periods and commas
+		default:
+			return "", false
+		}
+	}
+	words := strings.Fields(b.String())
+	if len(words) < quoteMinWords {
+		return "", false
+	}
+	return strings.Join(words, " "), true
+}
+
+// fetchQuotesCmd fetches a batch of quotes in the background and adds the
+// new ones to the cache, which it reports back whole.
+func fetchQuotesCmd(m model) tea.Cmd {
+	cached := append([]quote(nil), m.onlineQuotes...)
+	return func() tea.Msg {
+		fetched, err := fetchQuotes()
+		if err != nil {
+			return quotesFetchedMsg{err: err}
+		}
+		merged := mergeQuotes(cached, fetched)
+		if err := saveQuoteCache(merged); err != nil {
+			return quotesFetchedMsg{err: err}
+		}
+		return quotesFetchedMsg{quotes: merged}
+	}
+}
+
+// fetchQuotes asks quoteAPI for a batch of quotes, sanitized.
+func fetchQuotes() ([]quote, error) {
+	client := http.Client{Timeout: quoteFetchTimeout}
+	resp, err := client.Get(quoteAPI)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("quote API: %s", resp.Status)
+	}
+	var entries []struct {
+		Content string `json:"content"`
+		Author  string `json:"author"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, err
+	}
+	var qs []quote
+	for _, e := range entries {
+		if text, ok := sanitizeQuote(e.Content); ok {
+			qs = append(qs, quote{text: text, author: strings.TrimSpace(e.Author)})
+		}
+	}
+	return qs, nil
+}
+
+// mergeQuotes adds the fetched quotes that aren't cached or built in to
+// the end of the cache, dropping the oldest past quoteCacheMax.
+func mergeQuotes(cached, fetched []quote) []quote {
+	seen := map[string]bool{}
+	for _, q := range builtinQuotes {
+		seen[strings.ToLower(q.text)] = true
+	}
+	for _, q := range cached {
+		seen[strings.ToLower(q.text)] = true
+	}
+	merged := cached
+	for _, q := range fetched {
+		key := strings.ToLower(q.text)
+		if !seen[key] {
+			seen[key] = true
+			merged = append(merged, q)
+		}
+	}
+	if len(merged) > quoteCacheMax {
+		merged = merged[len(merged)-quoteCacheMax:]
+	}
+	return merged
+}
+
+func saveQuoteCache(qs []quote) error {
+	path := quoteCachePath()
+	if path == "" {
+		return nil
+	}
+	entries := make([]cachedQuote, len(qs))
+	for i, q := range qs {
+		entries[i] = cachedQuote{Text: q.text, Author: q.author}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// startQuoteFetch fetches quotes if fetching is on and one isn't already
+// under way.
+func startQuoteFetch(m model) (model, tea.Cmd) {
+	if !fetchingQuotesOn(m) || m.fetchingQuotes {
+		return m, nil
+	}
+	m.fetchingQuotes = true
+	return m, fetchQuotesCmd(m)
+}
+
+// handleQuotesFetched takes in a fetch's cache. A failed fetch keeps the
+// quotes there were, silently.
+func handleQuotesFetched(m model, msg quotesFetchedMsg) (model, tea.Cmd) {
+	m.fetchingQuotes = false
+	if msg.err == nil {
+		m.onlineQuotes = msg.quotes
+	}
+	return m, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+// Your own quotes, from a JSON file in the config directory:
+//
+//   $XDG_CONFIG_HOME/cli_typer/quotes.json   (default ~/.config/cli_typer/)
+//
+// It holds an array of quotes, each with its author:
+//
+//   [
+//     {"text": "I declare after all there is no enjoyment like reading", "author": "Jane Austen"},
+//     {"text": "Simplicity is prerequisite for reliability"}
+//   ]
+//
+// They join the built-in quotes (and any fetched ones), or, with the
+// menu's quotes row on "mine", replace them; the row only appears once
+// there are quotes to choose. Falling mode always draws words from both.
+// The author, when there is one, is shown on the results.
+//
+// Quotes are read once at startup. Text is typed as written, with its
+// whitespace evened out. An entry with no text, or a text or author that
+// isn't a string, is skipped, and the menu says how many were.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// quote is a quote and who said it ("" if nobody's credited).
+type quote struct {
+	text, author string
+}
+
+// builtinQuotes are the embedded quotes, none of them credited.
+var builtinQuotes = func() []quote {
+	qs := make([]quote, len(quotes))
+	for i, q := range quotes {
+		qs[i] = quote{text: q}
+	}
+	return qs
+}()
+
+func quotesPath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "quotes.json")
+}
+
+// loadCustomQuotes reads the quotes file. A missing file is no quotes and
+// no error; skipped counts the entries that weren't quotes.
+func loadCustomQuotes() (qs []quote, skipped int, err error) {
+	path := quotesPath()
+	if path == "" {
+		return nil, 0, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var entries []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, 0, err
+	}
+	for _, e := range entries {
+		var q quote
+		if json.Unmarshal(e["text"], &q.text) != nil {
+			skipped++
+			continue
+		}
+		if author, ok := e["author"]; ok && json.Unmarshal(author, &q.author) != nil {
+			skipped++
+			continue
+		}
+		q.text = strings.Join(strings.Fields(q.text), " ")
+		q.author = strings.TrimSpace(q.author)
+		if q.text == "" {
+			skipped++
+			continue
+		}
+		qs = append(qs, q)
+	}
+	return qs, skipped, nil
+}
+
+// loadQuotesWarning loads the quotes file into the model, returning what
+// went wrong for the menu to say, or "".
+func loadQuotesWarning(m *model) string {
+	qs, skipped, err := loadCustomQuotes()
+	if err != nil {
+		return fmt.Sprintf("couldn't read quotes.json (%v) %s using built-in quotes", err, glyphDash)
+	}
+	m.customQuotes = qs
+	if skipped > 0 {
+		return fmt.Sprintf("skipped %d malformed quotes in quotes.json", skipped)
+	}
+	return ""
+}
+
+// quotePool is the quotes a quote test picks from.
+func quotePool(m model) []quote {
+	if m.ownQuotes && len(m.customQuotes) > 0 {
+		return m.customQuotes
+	}
+	return allQuotes(m)
+}
+
+// allQuotes is the built-in quotes, the fetched ones while fetching is on
+// (see quotefetch.go), and your own together.
+func allQuotes(m model) []quote {
+	qs := append([]quote(nil), builtinQuotes...)
+	if fetchingQuotesOn(m) {
+		qs = append(qs, m.onlineQuotes...)
+	}
+	return append(qs, m.customQuotes...)
+}
+
+// renderQuoteAuthor credits a finished quote test's quote.
+func renderQuoteAuthor(m model) string {
+	return styleHint.Render(glyphDash + " " + m.quoteAuthor)
+}
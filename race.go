@@ -0,0 +1,649 @@
+package main
+
+// LAN races: two players type the same words at the same time.
+//
+//   cli_typer --host :4040          waits for an opponent on port 4040
+//   cli_typer --join host:4040      races whoever is hosting there
+//
+// The host's menu choices (and any start flags) make the test; zen becomes
+// a time test, since it has no end to race to. Once the guest connects,
+// the host sends the generated words, the guest answers that it's ready,
+// and both start the same 3-2-1 countdown. During the race each side
+// sends its word and character a few times a second, so the opponent
+// shows as a caret in the text and a progress bar under the status line.
+//
+// The first to finish the words wins, or, if the clock runs out on both,
+// the higher WPM. Each side sends its result when it's done, and the
+// results screen says who won and by how much once both are in. A race is
+// a single test on the agreed words, so the results screen has no restart;
+// leaving it for the menu ends the race.
+//
+// Everything on the wire is a raceMessage, one JSON object per line. The
+// connection is read and written by goroutines that hand what they get to
+// the update loop as messages (see waitRaceCmd), and silence for longer
+// than raceTimeout counts as a lost connection. Losing the opponent in the
+// middle of a race leaves the test running solo with a notice.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	raceProtocol    = 1 // bumped when raceMessage changes incompatibly
+	raceInterval    = 200 * time.Millisecond
+	raceTimeout     = 5 * time.Second
+	raceDialTimeout = 10 * time.Second
+	raceBarWidth    = 20
+)
+
+// raceMessage is anything sent over a race connection. Type says which
+// fields are filled in:
+//
+//	setup     host to guest: the test (Version, Words, Test, Content, ...)
+//	ready     guest to host: the test is set up
+//	start     host to guest: start the countdown
+//	progress  the sender's Word, Char, and live WPM
+//	done      the sender's result (WPM, Accuracy, Finished, Elapsed)
+//	ping      nothing, just that the sender is still there
+type raceMessage struct {
+	Type string `json:"type"`
+
+	Version  int      `json:"version,omitempty"`
+	Words    []string `json:"words,omitempty"`
+	Test     string   `json:"test,omitempty"`
+	Content  string   `json:"content,omitempty"`
+	Duration int      `json:"duration,omitempty"` // seconds
+	Seed     int64    `json:"seed,omitempty"`
+	Author   string   `json:"author,omitempty"`
+
+	Word int `json:"word,omitempty"`
+	Char int `json:"char,omitempty"`
+
+	WPM      float64 `json:"wpm,omitempty"`
+	Accuracy float64 `json:"accuracy,omitempty"`
+	Finished bool    `json:"finished,omitempty"` // typed every word
+	Elapsed  float64 `json:"elapsed,omitempty"`  // seconds
+}
+
+// raceProgress is where a racer has got to.
+type raceProgress struct {
+	word, char int
+	wpm        float64
+}
+
+// raceResult is how a racer's test went.
+type raceResult struct {
+	wpm, accuracy float64
+	finished      bool
+	elapsed       float64
+}
+
+// raceConn is the connection to the opponent, and the goroutines reading
+// and writing it. The host listens until the guest connects.
+type raceConn struct {
+	host     bool
+	addr     string
+	joinAddr string // for the host's lobby to show; see raceJoinAddr
+	listener net.Listener
+	conn     net.Conn
+
+	in   chan tea.Msg
+	out  chan raceMessage
+	done chan struct{}
+	once sync.Once
+}
+
+// raceMsg is a message from the opponent.
+type raceMsg struct {
+	rc  *raceConn
+	msg raceMessage
+}
+
+type raceConnectedMsg struct {
+	rc   *raceConn
+	conn net.Conn
+}
+
+// raceLostMsg reports that the connection failed or closed.
+type raceLostMsg struct {
+	rc  *raceConn
+	err error
+}
+
+type raceTickMsg struct {
+	rc *raceConn
+}
+
+func newRaceConn(host bool, addr string) *raceConn {
+	return &raceConn{
+		host: host,
+		addr: addr,
+		in:   make(chan tea.Msg, 16),
+		out:  make(chan raceMessage, 64),
+		done: make(chan struct{}),
+	}
+}
+
+// hostRace listens on addr for an opponent. It's done before the program
+// starts, so a port that's taken is reported straight away.
+func hostRace(m model, addr string) (model, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return m, err
+	}
+	m.race = newRaceConn(true, addr)
+	m.race.listener = listener
+	m.race.joinAddr = raceJoinAddr(listener)
+	return enterRaceLobby(m), nil
+}
+
+// joinRace sets up joining the race hosted at addr; Init connects.
+func joinRace(m model, addr string) model {
+	m.race = newRaceConn(false, addr)
+	return enterRaceLobby(m)
+}
+
+// enterRaceLobby sets the menu's choices aside for the race, like the
+// daily challenge does, and waits in the lobby.
+func enterRaceLobby(m model) model {
+	return holdRaceTest(saveMenuChoices(m))
+}
+
+// raceConnectCmd waits for the guest (host) or connects to the host
+// (guest).
+func raceConnectCmd(rc *raceConn) tea.Cmd {
+	return func() tea.Msg {
+		if rc.host {
+			conn, err := rc.listener.Accept()
+			if err != nil {
+				return raceLostMsg{rc: rc, err: err}
+			}
+			rc.listener.Close() // one opponent is all there's room for
+			return raceConnectedMsg{rc: rc, conn: conn}
+		}
+		conn, err := net.DialTimeout("tcp", rc.addr, raceDialTimeout)
+		if err != nil {
+			return raceLostMsg{rc: rc, err: err}
+		}
+		return raceConnectedMsg{rc: rc, conn: conn}
+	}
+}
+
+// start begins reading and writing the connection.
+func (rc *raceConn) start(conn net.Conn) {
+	rc.conn = conn
+	go rc.read()
+	go rc.write()
+}
+
+// read decodes messages until the connection fails, handing each to the
+// update loop.
+func (rc *raceConn) read() {
+	dec := json.NewDecoder(rc.conn)
+	for {
+		rc.conn.SetReadDeadline(time.Now().Add(raceTimeout))
+		var msg raceMessage
+		if err := dec.Decode(&msg); err != nil {
+			rc.deliver(raceLostMsg{rc: rc, err: err})
+			return
+		}
+		if !rc.deliver(raceMsg{rc: rc, msg: msg}) {
+			return
+		}
+	}
+}
+
+// deliver queues a message for waitRaceCmd, unless the race is over.
+func (rc *raceConn) deliver(msg tea.Msg) bool {
+	select {
+	case rc.in <- msg:
+		return true
+	case <-rc.done:
+		return false
+	}
+}
+
+// write sends queued messages until the race is over or a write fails,
+// which closes the connection so the reader reports it lost.
+func (rc *raceConn) write() {
+	enc := json.NewEncoder(rc.conn)
+	for {
+		select {
+		case msg := <-rc.out:
+			rc.conn.SetWriteDeadline(time.Now().Add(raceTimeout))
+			if err := enc.Encode(msg); err != nil {
+				rc.conn.Close()
+				return
+			}
+		case <-rc.done:
+			return
+		}
+	}
+}
+
+// send queues a message for the opponent without waiting. The queue only
+// fills if the opponent has stopped reading, and then the connection is
+// about to time out anyway.
+func (rc *raceConn) send(msg raceMessage) {
+	select {
+	case rc.out <- msg:
+	default:
+	}
+}
+
+// close ends the race's connection and goroutines.
+func (rc *raceConn) close() {
+	rc.once.Do(func() {
+		close(rc.done)
+		if rc.listener != nil {
+			rc.listener.Close()
+		}
+		if rc.conn != nil {
+			rc.conn.Close()
+		}
+	})
+}
+
+// waitRaceCmd waits for the next message from the connection's reader.
+func waitRaceCmd(rc *raceConn) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-rc.in:
+			return msg
+		case <-rc.done:
+			return nil
+		}
+	}
+}
+
+func raceTickCmd(rc *raceConn) tea.Cmd {
+	return tea.Tick(raceInterval, func(time.Time) tea.Msg {
+		return raceTickMsg{rc: rc}
+	})
+}
+
+// handleRaceMsg handles the race's messages, whatever the screen. Those
+// from a race already left are dropped.
+func handleRaceMsg(m model, msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case raceConnectedMsg:
+		if msg.rc != m.race {
+			msg.conn.Close()
+			return m, nil
+		}
+		m.race.start(msg.conn)
+		cmds := []tea.Cmd{waitRaceCmd(m.race), raceTickCmd(m.race)}
+		if m.race.host {
+			m = hostRaceTest(m)
+			m.race.send(raceSetup(m))
+		}
+		return m, tea.Batch(cmds...)
+
+	case raceLostMsg:
+		if msg.rc != m.race {
+			return m, nil
+		}
+		return loseRace(m, msg.err), nil
+
+	case raceTickMsg:
+		if msg.rc != m.race || m.raceLost != "" {
+			return m, nil
+		}
+		m.race.send(raceProgressMessage(m))
+		return m, raceTickCmd(m.race)
+
+	case raceMsg:
+		if msg.rc != m.race || m.raceLost != "" {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m, cmd = applyRaceMessage(m, msg.msg)
+		if m.race == nil {
+			return m, cmd
+		}
+		return m, tea.Batch(cmd, waitRaceCmd(m.race))
+	}
+	return m, nil
+}
+
+// applyRaceMessage acts on a message from the opponent.
+func applyRaceMessage(m model, msg raceMessage) (model, tea.Cmd) {
+	switch msg.Type {
+	case "setup":
+		if m.race.host || m.state != stateRaceLobby {
+			return m, nil
+		}
+		if msg.Version != raceProtocol {
+			return loseRace(m, fmt.Errorf("the host's cli_typer is a different version")), nil
+		}
+		var err error
+		if m, err = guestRaceTest(m, msg); err != nil {
+			return loseRace(m, err), nil
+		}
+		m.race.send(raceMessage{Type: "ready"})
+	case "ready":
+		if !m.race.host || m.state != stateRaceLobby {
+			return m, nil
+		}
+		m.race.send(raceMessage{Type: "start"})
+		return startRaceCountdown(m)
+	case "start":
+		if m.race.host || m.state != stateRaceLobby || len(m.words) == 0 {
+			return m, nil
+		}
+		return startRaceCountdown(m)
+	case "progress":
+		m.raceOpponent = raceProgress{word: msg.Word, char: msg.Char, wpm: msg.WPM}
+	case "done":
+		m.raceOpponentResult = &raceResult{wpm: msg.WPM, accuracy: msg.Accuracy, finished: msg.Finished, elapsed: msg.Elapsed}
+		if msg.Finished {
+			m.raceOpponent.word = len(m.words)
+		}
+	}
+	return m, nil
+}
+
+// loseRace gives up on the opponent. Before the race starts that's back to
+// the menu; after, the test carries on solo.
+func loseRace(m model, err error) model {
+	if m.state == stateRaceLobby {
+		reason := "couldn't connect to " + m.race.addr
+		if m.race.conn != nil || m.race.host {
+			reason = "lost the connection to the opponent"
+		}
+		m.menuWarning = fmt.Sprintf("%s (%v)", reason, err)
+		m.state = stateMenu
+		return restoreMenuChoices(endRace(m))
+	}
+	m.race.close()
+	m.raceLost = "opponent disconnected"
+	if m.raceResult == nil {
+		m.raceLost += " " + glyphDash + " finishing solo"
+	}
+	return m
+}
+
+// hostRaceTest prepares the host's test from its menu choices.
+func hostRaceTest(m model) model {
+	if m.testMode == testModeZen {
+		m.testMode = testModeTime
+	}
+	m = initTypingState(m)
+	m.ghostKeys = nil
+	return holdRaceTest(m)
+}
+
+// raceSetup describes the host's prepared test to the guest.
+func raceSetup(m model) raceMessage {
+	msg := raceMessage{
+		Type:     "setup",
+		Version:  raceProtocol,
+		Words:    m.words,
+		Test:     testModeNames[m.testMode],
+		Content:  contentModeNames[m.contentMode],
+		Duration: int(m.duration.Seconds()),
+		Author:   m.quoteAuthor,
+	}
+	if m.seedValid {
+		msg.Seed = m.seed
+	}
+	return msg
+}
+
+// guestRaceTest sets up the test the host described.
+func guestRaceTest(m model, msg raceMessage) (model, error) {
+	test := nameIndex(testModeNames, msg.Test)
+	content, found := modeWords, false
+	for cm, name := range contentModeNames {
+		if name == msg.Content {
+			content, found = cm, true
+		}
+	}
+	if len(msg.Words) == 0 || test < 0 || testMode(test) == testModeZen || !found || msg.Duration <= 0 {
+		return m, fmt.Errorf("the host sent a test that can't be raced")
+	}
+	m.testMode = testMode(test)
+	m.contentMode = content
+	m.duration = time.Duration(msg.Duration) * time.Second
+	m.wordCount = len(msg.Words)
+	m = startTypingTest(m, msg.Words)
+	m.quoteAuthor = msg.Author
+	m.seed, m.seedValid = msg.Seed, msg.Seed != 0
+	return holdRaceTest(m), nil
+}
+
+// holdRaceTest keeps a prepared test in the lobby until the start.
+func holdRaceTest(m model) model {
+	m.state = stateRaceLobby
+	m.countdownLeft = 0
+	m.countdownID++
+	return m
+}
+
+func startRaceCountdown(m model) (model, tea.Cmd) {
+	m.state = stateTyping
+	m = startCountdown(m)
+	return m, countdownTickCmd(m)
+}
+
+// raceProgressMessage is what the tick sends: where we are while typing,
+// or just a ping to show we're still here.
+func raceProgressMessage(m model) raceMessage {
+	if m.state != stateTyping || !m.timerStarted || m.raceResult != nil {
+		return raceMessage{Type: "ping"}
+	}
+	return raceMessage{Type: "progress", Word: m.wordIndex, Char: m.charIndex, WPM: liveWPM(m)}
+}
+
+// racing reports whether a race is in progress with the opponent still
+// connected.
+func racing(m model) bool {
+	return m.race != nil && m.raceLost == ""
+}
+
+// playerRaceResult is the player's finished test as a race result.
+func playerRaceResult(m model) raceResult {
+	return raceResult{
+		wpm:      m.finalWPM,
+		accuracy: m.finalAccuracy,
+		finished: !m.testFailed && m.totalWords == len(m.words),
+		elapsed:  m.finalTime,
+	}
+}
+
+// finishRace sends the opponent a finished test's result.
+func finishRace(m model) model {
+	if m.race == nil || m.raceResult != nil {
+		return m
+	}
+	r := playerRaceResult(m)
+	m.raceResult = &r
+	if m.raceLost != "" {
+		return m
+	}
+	m.race.send(raceMessage{Type: "done", WPM: r.wpm, Accuracy: r.accuracy, Finished: r.finished, Elapsed: r.elapsed})
+	return m
+}
+
+// endRace leaves the race, closing the connection.
+func endRace(m model) model {
+	if m.race != nil {
+		m.race.close()
+	}
+	m.race = nil
+	m.raceLost = ""
+	m.raceOpponent = raceProgress{}
+	m.raceResult = nil
+	m.raceOpponentResult = nil
+	return m
+}
+
+// raceWinner compares two results: 1 if a beat b, -1 if b won, 0 for a
+// tie. Finishing the words beats not finishing them; between two who
+// finished the faster wins, and between two who didn't the higher WPM.
+func raceWinner(a, b raceResult) int {
+	var x, y float64
+	switch {
+	case a.finished != b.finished:
+		if a.finished {
+			return 1
+		}
+		return -1
+	case a.finished:
+		x, y = b.elapsed, a.elapsed
+	default:
+		x, y = a.wpm, b.wpm
+	}
+	// Compare at the precision shown, so a "win by 0" can't happen
+	x, y = float64(int(x*10+0.5)), float64(int(y*10+0.5))
+	switch {
+	case x > y:
+		return 1
+	case x < y:
+		return -1
+	}
+	return 0
+}
+
+// raceMargin says by how much the race was won.
+func raceMargin(a, b raceResult) string {
+	switch {
+	case a.finished != b.finished:
+		return "finished first"
+	case a.finished:
+		return fmt.Sprintf("by %.1fs", max(a.elapsed-b.elapsed, b.elapsed-a.elapsed))
+	}
+	return fmt.Sprintf("by %.1f wpm", max(a.wpm-b.wpm, b.wpm-a.wpm))
+}
+
+// renderRaceResult is the results screen's note on the race.
+func renderRaceResult(m model) string {
+	them := m.raceOpponentResult
+	if them == nil {
+		if m.raceLost != "" {
+			return styleIncorrect.Render("race") + styleHint.Render(" "+glyphDash+" opponent disconnected")
+		}
+		return styleHighlight.Render("race") + styleHint.Render(fmt.Sprintf(" %s waiting for the opponent, word %d of %d",
+			glyphDash, min(m.raceOpponent.word+1, len(m.words)), len(m.words)))
+	}
+	var verdict string
+	switch raceWinner(*m.raceResult, *them) {
+	case 1:
+		verdict = styleHighlight.Bold(true).Render("you won " + raceMargin(*m.raceResult, *them))
+	case -1:
+		verdict = styleIncorrect.Bold(true).Render("you lost " + raceMargin(*them, *m.raceResult))
+	default:
+		verdict = styleHighlight.Bold(true).Render("a tie")
+	}
+	opponent := fmt.Sprintf("opponent %.0f wpm %.1f%%", them.wpm, them.accuracy)
+	if them.finished {
+		opponent += fmt.Sprintf(" in %.1fs", them.elapsed)
+	}
+	return verdict + styleHint.Render(" "+glyphDash+" "+opponent)
+}
+
+// renderRaceBar is the opponent's progress through the words, under the
+// typing test's status line.
+func renderRaceBar(m model) string {
+	if m.raceLost != "" {
+		return styleIncorrect.Render(m.raceLost)
+	}
+	var note string
+	if m.raceOpponentResult != nil {
+		note = fmt.Sprintf("done, %.0f wpm", m.raceOpponentResult.wpm)
+	} else if m.raceOpponent.wpm > 0 {
+		note = fmt.Sprintf("%.0f wpm", m.raceOpponent.wpm)
+	}
+	return renderOpponentBar("opponent ", styleAlien, m.raceOpponent.word, len(m.words), note)
+}
+
+// renderOpponentBar draws an opponent's progress through the words, filled
+// in the color of its caret, with a note after the count.
+func renderOpponentBar(label string, fill lipgloss.Style, done, total int, note string) string {
+	done = min(done, total)
+	filled := 0
+	if total > 0 {
+		filled = done * raceBarWidth / total
+	}
+	bar := fill.Render(strings.Repeat(glyphBarFull, filled)) + styleUntyped.Render(strings.Repeat(glyphBarEmpty, raceBarWidth-filled))
+	status := fmt.Sprintf(" %d/%d", done, total)
+	if note != "" {
+		status += "  " + note
+	}
+	return styleStatLabel.Render(label) + bar + styleHint.Render(status)
+}
+
+// raceCaretAt reports whether the opponent's caret is on the given
+// character of the given word. The player's own caret takes precedence.
+func raceCaretAt(m model, wordIdx, charIdx int) bool {
+	if !racing(m) || !m.timerStarted || m.raceOpponentResult != nil {
+		return false
+	}
+	if wordIdx == m.wordIndex && charIdx == len(m.input[wordIdx]) {
+		return false
+	}
+	return wordIdx == m.raceOpponent.word && charIdx == m.raceOpponent.char
+}
+
+func updateRaceLobby(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if m.keys.is(keyMsg, actionBack) || m.keys.is(keyMsg, actionQuit) {
+			m.state = stateMenu
+		}
+	}
+	return m, nil
+}
+
+func viewRaceLobby(m model) string {
+	var status string
+	switch {
+	case m.race.conn != nil:
+		status = "connected " + glyphDash + " getting ready"
+	case m.race.host:
+		status = "waiting for an opponent on " + m.race.addr + glyphEllipsis
+	default:
+		status = "connecting to " + m.race.addr + glyphEllipsis
+	}
+	parts := []string{styleTitle.Render("race"), "", styleCorrect.Render(status)}
+	if m.race.host && m.race.conn == nil {
+		if m.race.joinAddr != "" {
+			parts = append(parts, styleHint.Render("the other player runs: cli_typer --join "+m.race.joinAddr))
+		}
+	}
+	parts = append(parts, "", styleHint.Render(m.keys[actionBack]+" menu"))
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// raceJoinAddr is an address on this machine's network the guest can
+// join: the one listened on, or when that's every interface, the first
+// that isn't loopback. It's "" if there's no network to find one on.
+// Looking it up lists the interfaces, so it's done once, when hosting
+// starts, rather than on every render of the lobby.
+func raceJoinAddr(listener net.Listener) string {
+	addr := listener.Addr().String()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil && !ip.IsUnspecified() {
+		return addr
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, a := range addrs {
+		if ip, ok := a.(*net.IPNet); ok && !ip.IP.IsLoopback() && ip.IP.To4() != nil {
+			return net.JoinHostPort(ip.IP.String(), port)
+		}
+	}
+	return ""
+}
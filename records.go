@@ -0,0 +1,133 @@
+package main
+
+// Falling mode high scores, kept per content mode (and separately for the
+// waves format, whose bonuses inflate scores, for invaders, for each time
+// limit, for each number of lives, and for the 1k and 5k word sets) in a
+// small JSON file next to the history:
+//
+//   $XDG_DATA_HOME/cli_typer/records.json
+//
+// Loaded once at startup and rewritten (via a tea.Cmd) whenever a game beats
+// a record. A missing or unreadable file just means no records yet.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type fallingRecord struct {
+	// Stored as "points": scores saved as "score" were counted one point
+	// per word, and can't be compared with today's
+	Score    int `json:"points"`
+	Survived int `json:"survived"` // seconds
+}
+
+// fallingRecords maps a record key ("words", "quotes waves", ...) to its best run.
+type fallingRecords map[string]fallingRecord
+
+// fallingRecordKey is the records entry the current falling setup competes in.
+func fallingRecordKey(m model) string {
+	key := contentModeNames[m.contentMode]
+	if tag := wordSetTag(m); tag != "" {
+		key += " " + wordSets[m.wordSet].label
+	}
+	if invadersGame(m) {
+		key += " invaders"
+	} else if m.waves {
+		key += " waves"
+	} else if timedGame(m) {
+		key += fmt.Sprintf(" %ds", m.timeLimit)
+	}
+	// Three lives, the default, keeps the plain key records started with
+	switch {
+	case m.lives == 1:
+		key += " sudden death"
+	case m.lives != defaultFallingLives:
+		key += fmt.Sprintf(" %d lives", m.lives)
+	}
+	return key
+}
+
+func recordsPath() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "records.json")
+}
+
+func loadFallingRecords() fallingRecords {
+	records := fallingRecords{}
+	path := recordsPath()
+	if path == "" {
+		return records
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return records
+	}
+	if err := json.Unmarshal(data, &records); err != nil || records == nil {
+		return fallingRecords{}
+	}
+	return records
+}
+
+// saveFallingRecordsCmd writes the records file in the background.
+func saveFallingRecordsCmd(records fallingRecords) tea.Cmd {
+	// Copy so later updates to the model's map can't race the write
+	snapshot := make(fallingRecords, len(records))
+	for k, v := range records {
+		snapshot[k] = v
+	}
+	return func() tea.Msg {
+		path := recordsPath()
+		if path == "" {
+			return nil
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil
+		}
+		_ = os.WriteFile(path, data, 0o644)
+		return nil
+	}
+}
+
+// updateFallingRecords compares a finished game with the stored records for
+// its content mode, sets the personal-best fields shown on the game over
+// screen, and returns a save command if any record was beaten.
+func updateFallingRecords(m model) (model, tea.Cmd) {
+	key := fallingRecordKey(m)
+	prev := m.fallingRecords[key]
+	survived := int(fallingElapsed(m).Seconds())
+
+	m.prevBest = float64(prev.Score)
+	m.hasPrevBest = prev.Score > 0
+	m.newPersonalBest = m.fallingScore > prev.Score
+	m.newSurvivalRecord = survived > prev.Survived
+	m.prevBestSurvived = prev.Survived
+
+	if !m.newPersonalBest && !m.newSurvivalRecord {
+		return m, nil
+	}
+
+	next := prev
+	if m.newPersonalBest {
+		next.Score = m.fallingScore
+	}
+	if m.newSurvivalRecord {
+		next.Survived = survived
+	}
+	if m.fallingRecords == nil {
+		m.fallingRecords = fallingRecords{}
+	}
+	m.fallingRecords[key] = next
+	return m, saveFallingRecordsCmd(m.fallingRecords)
+}
@@ -0,0 +1,142 @@
+package main
+
+// Test replays. Every keypress that changes a classic test's text is logged
+// with its time since the first keypress (pauses excluded), and the log is
+// saved with the result in history.
+//
+// "w" on the results screen plays the log back through the normal word
+// rendering at real speed: each key is scheduled with tea.Tick at the gap
+// after the one before it, so hesitations, overflow characters, and
+// corrections show up exactly as they happened. Esc goes back to results.
+//
+// Only edits are logged — a refused space or a rune past the overflow limit
+// changed nothing, so there's nothing to replay.
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type keyEvent struct {
+	At  int64  `json:"t"` // milliseconds since the first keypress, excluding pauses
+	Key string `json:"k"` // the rune typed, or keySpace / keyBackspace / keyClearWord
+}
+
+const (
+	keySpace     = "space"
+	keyBackspace = "backspace"
+	keyClearWord = "clear" // Ctrl+W and friends: the current word emptied
+)
+
+type replayTickMsg struct {
+	id int
+}
+
+// logKey records an edit made to the test's text.
+func logKey(m model, key string) model {
+	m.keyLog = append(m.keyLog, keyEvent{At: typingElapsed(m).Milliseconds(), Key: key})
+	return m
+}
+
+// startReplay plays the last test's key log from the beginning.
+func startReplay(m model) (model, tea.Cmd) {
+	m.state = stateReplay
+	m.replayInput = make([][]rune, len(m.words))
+	m.replayWordIndex = 0
+	m.replayPos = 0
+	m.replayID++
+	return m, replayTickCmd(m)
+}
+
+// replayTickCmd schedules the next logged key, as long after the previous
+// one as it was typed.
+func replayTickCmd(m model) tea.Cmd {
+	if m.replayPos >= len(m.keyLog) {
+		return nil
+	}
+	wait := m.keyLog[m.replayPos].At
+	if m.replayPos > 0 {
+		wait -= m.keyLog[m.replayPos-1].At
+	}
+	id := m.replayID
+	return tea.Tick(time.Duration(wait)*time.Millisecond, func(time.Time) tea.Msg {
+		return replayTickMsg{id: id}
+	})
+}
+
+func updateReplay(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case replayTickMsg:
+		if msg.id != m.replayID || m.replayPos >= len(m.keyLog) {
+			return m, nil
+		}
+		m = applyReplayKey(m, m.keyLog[m.replayPos])
+		m.replayPos++
+		return m, replayTickCmd(m)
+
+	case tea.KeyMsg:
+		switch {
+		case msg.String() == "q" || m.keys.is(msg, actionBack):
+			m.state = stateResults
+			return m, nil
+		case msg.String() == "w":
+			return startReplay(m)
+		}
+	}
+	return m, nil
+}
+
+// applyReplayKey makes the same edit to the replayed text that the key
+// made during the test (see processKeypress).
+func applyReplayKey(m model, e keyEvent) model {
+	i := m.replayWordIndex
+	switch e.Key {
+	case keyBackspace:
+		if n := len(m.replayInput[i]); n > 0 {
+			m.replayInput[i] = m.replayInput[i][:n-1]
+		} else if i > 0 {
+			m.replayWordIndex--
+		}
+	case keyClearWord:
+		m.replayInput[i] = m.replayInput[i][:0]
+	case keySpace:
+		if i < len(m.words)-1 {
+			m.replayWordIndex++
+		}
+	default:
+		m.replayInput[i] = append(m.replayInput[i], []rune(e.Key)...)
+	}
+	return m
+}
+
+func viewReplay(m model) string {
+	// Render the replayed text exactly like the live test
+	r := m
+	r.input = m.replayInput
+	r.wordIndex = m.replayWordIndex
+	r.spaceRejected = false
+	textBlock := renderTypingText(r)
+
+	var at int64
+	if m.replayPos > 0 {
+		at = m.keyLog[m.replayPos-1].At
+	}
+	statusBar := styleTimer.Render(fmt.Sprintf("%.1fs", float64(at)/1000)) +
+		"    " + styleHint.Render("replay")
+	if m.replayPos >= len(m.keyLog) {
+		statusBar += styleHint.Render(" " + glyphDash + " done")
+	}
+
+	hint := styleHint.Render("w watch again  " + m.keys[actionBack] + " back")
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		statusBar,
+		"",
+		textBlock,
+		"",
+		hint,
+	)
+}
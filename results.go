@@ -7,9 +7,16 @@ package main
 // test uses. We calculate "net WPM" which only counts correct characters.
 //
 //   Net WPM = (correct characters / 5) / minutes elapsed
+//   Raw WPM = (all typed characters / 5) / minutes elapsed
+//
+// Consistency follows monkeytype: take the raw WPM of every full second of
+// the test, compute the coefficient of variation (stddev / mean), and map it
+// onto 0–100% so that perfectly even typing scores 100.
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,6 +33,10 @@ func calculateResults(m model) model {
 	correctChars := 0
 	totalChars := 0
 	correctWords := 0
+	typedChars := 0
+	attempted := 0
+	var missed []string
+	seenMissed := make(map[string]bool)
 
 	for i := 0; i < len(m.words); i++ {
 		if i > m.wordIndex {
@@ -33,10 +44,21 @@ func calculateResults(m model) model {
 		}
 
 		typed := m.input[i]
+		if i == m.wordIndex && len(typed) == 0 {
+			break // the test ended before this word was started
+		}
+		attempted++
 		target := []rune(m.words[i])
+		typedChars += len(typed)
 
-		wordCorrect := true
-		for j := 0; j < len(target); j++ {
+		// Where the test ended mid-word, only what was typed of it counts,
+		// the same as liveAccuracy
+		n := len(target)
+		if i == m.wordIndex {
+			n = min(len(typed), n)
+		}
+		wordCorrect := len(typed) >= len(target)
+		for j := 0; j < n; j++ {
 			totalChars++
 			if j < len(typed) && typed[j] == target[j] {
 				correctChars++
@@ -55,10 +77,15 @@ func calculateResults(m model) model {
 		if i < m.wordIndex {
 			totalChars++
 			correctChars++
+			typedChars++
 		}
 
 		if wordCorrect && len(typed) == len(target) {
 			correctWords++
+		} else if (i < m.wordIndex || len(typed) >= len(target)) && !seenMissed[m.words[i]] {
+			// A word cut off mid-way by the timer isn't a mistake yet
+			seenMissed[m.words[i]] = true
+			missed = append(missed, m.words[i])
 		}
 	}
 
@@ -74,52 +101,402 @@ func calculateResults(m model) model {
 	}
 
 	m.finalWPM = netWPM
+	m.finalRawWPM = (float64(typedChars) / 5.0) / minutes
+	m.missedWords = missed
+	m.wpmSamples = wpmSamples(m.keystrokes, elapsed)
+	m.finalConsistency, m.hasConsistency = consistency(m.wpmSamples)
 	m.finalAccuracy = accuracy
 	m.correctChars = correctChars
 	m.totalChars = totalChars
 	m.correctWords = correctWords
-	m.totalWords = m.wordIndex + 1
+	m.totalWords = attempted
+	m.finalTime = elapsed
 	return m
 }
 
 func updateResults(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case exportedMsg:
+		return handleExported(m, msg)
+	case flashClearMsg:
+		return clearFlash(m, msg), nil
+	}
+
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if !ok {
 		return m, nil
 	}
 
-	switch keyMsg.Type {
-	case tea.KeyTab, tea.KeyEnter:
+	if m.reviewing {
+		if keyMsg.String() == "v" || m.keys.is(keyMsg, actionBack) {
+			m.reviewing = false
+		}
+		return m, nil
+	}
+
+	// A race was one test on agreed words, with nothing to play again
+	if m.race != nil {
+		switch keyMsg.String() {
+		case "r", "p", "n", "enter":
+			return m, nil
+		}
+		if m.keys.is(keyMsg, actionRestart) {
+			return m, nil
+		}
+	}
+
+	switch {
+	case m.keys.is(keyMsg, actionRestart) || keyMsg.Type == tea.KeyEnter:
 		// Restart with same settings
 		m = initTypingState(m)
-		return m, nil
-	case tea.KeyEsc:
+		return m, countdownTickCmd(m)
+	case m.keys.is(keyMsg, actionBack):
 		m.state = stateMenu
 		return m, nil
 	}
 
+	switch keyMsg.String() {
+	case "r":
+		// Retry only the words that were missed
+		if len(m.missedWords) > 0 {
+			count := testLength(m)
+			if m.contentMode == modeQuotes {
+				count = len(m.words) // keep it as long as the quote was
+			}
+			m = startTypingTest(m, generateWords(m.rng, m.missedWords, count))
+			m.seedValid = false
+			m.daily = "" // practice, not a daily attempt or a lesson
+			m = endLesson(m)
+		}
+		return m, countdownTickCmd(m)
+	case "p":
+		// Repeat the identical text, to measure improvement on it
+		attempt, author := m.attempt+1, m.quoteAuthor
+		m = startTypingTest(m, append([]string(nil), m.words...))
+		m.attempt, m.quoteAuthor = attempt, author
+		return m, countdownTickCmd(m)
+	case "n":
+		if m.lessonPassed {
+			m = startNextLesson(m)
+			return m, countdownTickCmd(m)
+		}
+		return m, nil
+	case "v":
+		m.reviewing = true
+		return m, nil
+	case "e":
+		return m, exportCmd(m)
+	case "c":
+		var flashCmd tea.Cmd
+		m, flashCmd = setFlash(m, "copied", false)
+		return m, tea.Batch(copyCmd(resultSummary(m.lastResult.historyEntry)), flashCmd)
+	case "w":
+		if len(m.keyLog) > 0 {
+			return startReplay(m)
+		}
+		return m, nil
+	}
+
 	return m, nil
 }
 
 func viewResults(m model) string {
+	if m.reviewing {
+		return viewReview(m)
+	}
+
 	// Large WPM number as the hero stat
 	wpmNum := styleBigWPM.Render(fmt.Sprintf("%.0f", m.finalWPM))
 	wpmLabel := styleHint.Render(" wpm")
 
 	// Stats
-	acc := styleStatLabel.Render("accuracy     ") + styleStatValue.Render(fmt.Sprintf("%.1f%%", m.finalAccuracy))
-	chars := styleStatLabel.Render("characters   ") + styleStatValue.Render(fmt.Sprintf("%d/%d", m.correctChars, m.totalChars))
-	words := styleStatLabel.Render("words        ") + styleStatValue.Render(fmt.Sprintf("%d/%d", m.correctWords, m.totalWords))
-
-	hint := styleHint.Render("tab/enter restart  esc menu")
-
-	return lipgloss.JoinVertical(lipgloss.Left,
-		wpmNum+wpmLabel,
-		"",
-		acc,
-		chars,
-		words,
-		"",
-		hint,
+	stats := []statPair{
+		{"raw", fmt.Sprintf("%.0f", m.finalRawWPM)},
+		{"accuracy", fmt.Sprintf("%.1f%%", m.finalAccuracy)},
+	}
+	if m.hasConsistency {
+		stats = append(stats, statPair{"consistency", fmt.Sprintf("%.0f%%", m.finalConsistency)})
+	}
+	stats = append(stats,
+		statPair{"characters", fmt.Sprintf("%d/%d", m.correctChars, m.totalChars)},
+		statPair{"words", fmt.Sprintf("%d/%d", m.correctWords, m.totalWords)},
 	)
+	if endsOnLastWord(m) || zenTest(m) {
+		stats = append(stats, statPair{"time", fmt.Sprintf("%.1fs", m.finalTime)})
+	}
+	if m.seedValid {
+		stats = append(stats, statPair{"seed", fmt.Sprintf("%d", m.seed)})
+	}
+	var sprints []sprint
+	if m.sprints {
+		sprints = finishedSprints(m)
+	}
+
+	// Two hint lines, to stay inside the narrowest results screen: playing
+	// again, then looking back at this test
+	playHint := m.keys[actionRestart] + "/enter restart  p repeat  "
+	if len(m.missedWords) > 0 {
+		playHint += "r retry missed  "
+	}
+	playHint += m.keys[actionBack] + " menu"
+	if m.race != nil {
+		playHint = m.keys[actionBack] + " leave the race"
+	}
+	lookHint := "v review  "
+	if len(m.keyLog) > 0 {
+		lookHint += "w watch replay  "
+	}
+	lookHint += "c copy  e export"
+	hint := styleHint.Render(playHint) + "\n" + styleHint.Render(lookHint)
+	if sync := renderWebhookStatus(m); sync != "" {
+		hint += "  " + sync
+	}
+	if m.flash != "" {
+		hint = styleHint.Render(playHint) + "\n" + renderFlash(m)
+	}
+
+	// Notes on how this result came about, under the WPM
+	var notes []string
+	if m.race != nil {
+		notes = append(notes, renderRaceResult(m))
+	}
+	if botActive(m) {
+		notes = append(notes, renderBotResult(m))
+	}
+	if m.quoteAuthor != "" {
+		notes = append(notes, renderQuoteAuthor(m))
+	}
+	if tag := wordSetTag(m); tag != "" {
+		notes = append(notes, styleHint.Render(wordSetLabel(tag)))
+	}
+	if m.attempt > 1 {
+		notes = append(notes, styleHint.Render(fmt.Sprintf("repeat #%d %s same text as before", m.attempt, glyphDash)))
+	}
+	if m.daily != "" {
+		daily := styleHighlight.Render("daily challenge " + m.daily)
+		if m.lastResult != nil && m.lastResult.Reattempt {
+			daily += styleHint.Render(" " + glyphDash + " re-attempt")
+		}
+		notes = append(notes, daily)
+	}
+	if m.lesson != "" {
+		notes = append(notes, renderLessonResult(m))
+	}
+	if m.practice {
+		notes = append(notes, renderPracticeNote(m))
+	}
+	if m.testFailed {
+		progress := fmt.Sprintf(" %s reached word %d of %d", glyphDash, m.wordIndex+1, len(m.words))
+		if m.failReason != "" {
+			progress = " " + glyphDash + " " + m.failReason
+		}
+		notes = append(notes, styleIncorrect.Bold(true).Render("failed")+styleHint.Render(progress))
+	}
+	if pb := renderPersonalBest(m, "%.0f wpm"); pb != "" {
+		notes = append(notes, pb)
+	}
+
+	parts := []string{wpmNum + wpmLabel}
+	if m.inline {
+		// Compact: notes on one line, three stats to a line, no spacing
+		if len(notes) > 0 {
+			parts = append(parts, strings.Join(notes, "  "))
+		}
+		parts = append(parts, renderStats(stats, 3)...)
+		if i := bestSprint(sprints); i >= 0 {
+			// No room for the list, just the best
+			best := fmt.Sprintf("%.1fs %.0f wpm", sprints[i].time.Seconds(), sprints[i].wpm)
+			parts = append(parts, styleStatLabel.Render("best sprint ")+styleStatValue.Render(best))
+		}
+	} else {
+		parts = append(parts, notes...)
+		parts = append(parts, "")
+		parts = append(parts, renderStats(stats, 1)...)
+		if len(sprints) > 0 {
+			parts = append(parts, renderSprints(sprints)...)
+		}
+		parts = append(parts, "")
+	}
+	parts = append(parts, renderMissedWords(m.missedWords), renderProblemKeys(m.keyMisses), "", hint)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// statPair is one labelled value on the results or stats screen.
+type statPair struct {
+	label, value string
+}
+
+// renderStats lays out stats one to a line with their values lined up, or
+// perLine to a line, label by value, for the compact inline screens.
+func renderStats(stats []statPair, perLine int) []string {
+	var lines []string
+	for i := 0; i < len(stats); i += perLine {
+		var line string
+		for j, s := range stats[i:min(i+perLine, len(stats))] {
+			if perLine == 1 {
+				line = styleStatLabel.Render(fmt.Sprintf("%-13s", s.label)) + styleStatValue.Render(s.value)
+				continue
+			}
+			if j > 0 {
+				line += "  "
+			}
+			line += styleStatLabel.Render(s.label+" ") + styleStatValue.Render(s.value)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// flashDuration is how long a notice replaces the hint line.
+const flashDuration = 3 * time.Second
+
+type flashClearMsg struct {
+	id int
+}
+
+// setFlash shows a notice in place of the results hint. Errors stay until
+// the next result; anything else clears after flashDuration.
+func setFlash(m model, text string, isErr bool) (model, tea.Cmd) {
+	m.flash = text
+	m.flashErr = isErr
+	m.flashID++
+	if isErr {
+		return m, nil
+	}
+	id := m.flashID
+	return m, tea.Tick(flashDuration, func(time.Time) tea.Msg {
+		return flashClearMsg{id: id}
+	})
+}
+
+// clearFlash removes the notice a flashClearMsg was scheduled for, unless
+// a newer one has replaced it.
+func clearFlash(m model, msg flashClearMsg) model {
+	if msg.id == m.flashID {
+		m.flash = ""
+	}
+	return m
+}
+
+func renderFlash(m model) string {
+	if m.flashErr {
+		return styleIncorrect.Render(m.flash)
+	}
+	return styleHighlight.Render(m.flash)
+}
+
+const maxMissedShown = 15
+
+// renderMissedWords lists up to maxMissedShown missed words in the error style.
+func renderMissedWords(missed []string) string {
+	label := styleStatLabel.Render("missed       ")
+	if len(missed) == 0 {
+		return label + styleCorrect.Render("no mistakes")
+	}
+	shown := missed
+	if len(shown) > maxMissedShown {
+		shown = shown[:maxMissedShown]
+	}
+	text := styleIncorrect.Render(strings.Join(shown, " "))
+	if len(missed) > len(shown) {
+		text += styleHint.Render(fmt.Sprintf(" +%d more", len(missed)-len(shown)))
+	}
+	return label + text
+}
+
+// wpmSamples converts per-second keystroke counts into raw WPM samples,
+// one per full second of the test. A trailing partial second is dropped.
+func wpmSamples(keystrokes []int, elapsed float64) []float64 {
+	full := int(elapsed)
+	samples := make([]float64, full)
+	for i := 0; i < full; i++ {
+		if i < len(keystrokes) {
+			samples[i] = float64(keystrokes[i]) / 5.0 * 60.0
+		}
+	}
+	return samples
+}
+
+// consistency maps the coefficient of variation of the WPM samples to a
+// 0–100% score. ok is false with fewer than 3 samples, or if nothing was
+// typed at all, since the number would be meaningless.
+func consistency(samples []float64) (pct float64, ok bool) {
+	if len(samples) < 3 {
+		return 0, false
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	mean := sum / float64(len(samples))
+	if mean == 0 {
+		return 0, false
+	}
+	var variance float64
+	for _, s := range samples {
+		variance += (s - mean) * (s - mean)
+	}
+	cv := math.Sqrt(variance/float64(len(samples))) / mean
+
+	// Same curve monkeytype uses ("kogasa"): smooth, bounded to [0, 100]
+	return 100 * (1 - math.Tanh(cv+math.Pow(cv, 3)/3+math.Pow(cv, 5)/5)), true
+}
+
+// renderPersonalBest returns the "new personal best!" banner and/or the
+// previous best for comparison, formatted with valueFmt. Empty if there's
+// nothing to say (no new best and no previous result).
+func renderPersonalBest(m model, valueFmt string) string {
+	var prev string
+	if m.hasPrevBest {
+		prev = styleStatLabel.Render("best ") + styleStatValue.Render(fmt.Sprintf(valueFmt, m.prevBest))
+	}
+	if m.newPersonalBest {
+		banner := styleHighlight.Bold(true).Render("new personal best!")
+		if prev != "" {
+			return banner + "  " + styleStatLabel.Render("previous ") + styleStatValue.Render(fmt.Sprintf(valueFmt, m.prevBest))
+		}
+		return banner
+	}
+	return prev
+}
+
+// renderSeed shows the seed a test was generated from, for replaying it
+// with --seed.
+func renderSeed(m model) string {
+	return styleStatLabel.Render("seed         ") + styleStatValue.Render(fmt.Sprintf("%d", m.seed))
+}
+
+// viewReview shows everything that was typed, mistakes highlighted, for
+// reviewing a test (especially a blind one). Text that doesn't fit on
+// screen is cut off with a note of how much is left.
+func viewReview(m model) string {
+	r := m
+	r.caret = caretOff // no caret: the test is over
+	lines := wrapWords(m.words[:m.wordIndex+1], textWrapWidth(m))
+
+	maxLines := max(m.height-6, 3)
+	shown := lines
+	if len(shown) > maxLines {
+		shown = shown[:maxLines]
+	}
+
+	var rendered []string
+	for _, line := range shown {
+		var b strings.Builder
+		for j, wIdx := range line {
+			if j > 0 {
+				b.WriteString(styleUntyped.Render(" "))
+			}
+			b.WriteString(renderWord(r, wIdx))
+		}
+		rendered = append(rendered, b.String())
+	}
+	if len(lines) > len(shown) {
+		rendered = append(rendered, styleHint.Render(fmt.Sprintf("%s %d more lines", glyphEllipsis, len(lines)-len(shown))))
+	}
+
+	parts := []string{styleTitle.Render("review"), ""}
+	parts = append(parts, rendered...)
+	parts = append(parts, "", styleHint.Render("v/"+m.keys[actionBack]+" back to results"))
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
 }
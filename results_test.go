@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/timer"
+)
+
+func TestResultsWhenTimeRunsOut(t *testing.T) {
+	tests := []struct {
+		name                     string
+		typed                    string
+		correctWords, totalWords int
+		correctChars, totalChars int
+		missed                   []string
+	}{
+		{"on a space", "alpha ", 1, 1, 6, 6, nil},
+		{"with no input", "", 0, 0, 0, 0, nil},
+		{"mid-word", "alpha be", 1, 2, 8, 8, nil},
+		{"mid-word with overflow", "alpha betaxx", 1, 2, 10, 12, []string{"beta"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The clock starts as the countdown would start it, so even
+			// with nothing typed the test has been running
+			m := newTimeTest(t, "alpha", "beta", "gamma")
+			m, _ = startTypingClock(m)
+			m = typeText(t, m, tt.typed)
+			m = typeKey(t, m, timer.TimeoutMsg{ID: m.timer.ID()})
+			if m.state != stateResults {
+				t.Fatalf("the timeout didn't end the test")
+			}
+			if m.correctWords != tt.correctWords || m.totalWords != tt.totalWords {
+				t.Errorf("words %d/%d, want %d/%d", m.correctWords, m.totalWords, tt.correctWords, tt.totalWords)
+			}
+			if m.correctChars != tt.correctChars || m.totalChars != tt.totalChars {
+				t.Errorf("characters %d/%d, want %d/%d", m.correctChars, m.totalChars, tt.correctChars, tt.totalChars)
+			}
+			if !slices.Equal(m.missedWords, tt.missed) {
+				t.Errorf("missed words %q, want %q", m.missedWords, tt.missed)
+			}
+			wantAccuracy := 0.0
+			if tt.totalChars > 0 {
+				wantAccuracy = float64(tt.correctChars) / float64(tt.totalChars) * 100
+			}
+			if m.finalAccuracy != wantAccuracy {
+				t.Errorf("accuracy %.2f%%, want %.2f%%", m.finalAccuracy, wantAccuracy)
+			}
+		})
+	}
+}
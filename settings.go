@@ -0,0 +1,310 @@
+package main
+
+// The settings screen, opened from the menu with "o". Holds the options
+// that apply to every game mode rather than a single test:
+//
+//   caret   — block / underline / bar / off
+//   pace    — off / 40 / 60 / 80 / 100 / 120 wpm (pace caret in classic tests)
+//   min wpm — off / 30 / 40 / 60 / 80 / 100 (fail classic tests below it)
+//   min acc — off / 90% / 95% / 98%
+//   paste   — typed / ignored (pasted text in classic tests; see paste.go)
+//   countdown — off / on (3-2-1 countdown before classic tests)
+//   keyboard — off / on (on-screen keyboard under classic tests)
+//   variety — natural / varied (how words tests pick words; see variety.go)
+//   layout  — qwerty / colemak / dvorak / workman (layout emulation)
+//   errors  — color / underline / highlight (how mistakes are marked)
+//   motion  — full / reduced (falling mode effects; see reduceMotion)
+//   volume  — 0% / 25% / 50% / 75% / 100%
+//   blur    — play on / pause (pause games while the terminal is unfocused)
+//   keys    — off / on   (keypress sounds in classic tests, mistypes in falling mode)
+//   music   — off / on   (background music in falling mode)
+//   online  — off / on   (fetch quotes from the internet; see quotefetch.go)
+//   theme   — serika / nord / dracula (/ custom themes from files)
+//
+// Like menu choices, every change is saved to the config file.
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type settingsRowKind int
+
+const (
+	settingCaret settingsRowKind = iota
+	settingPace
+	settingMinWPM
+	settingMinAccuracy
+	settingPaste
+	settingCountdown
+	settingKeyboard
+	settingVariety
+	settingLayout
+	settingErrorMarking
+	settingMotion
+	settingPauseOnBlur
+	settingVolume
+	settingKeySounds
+	settingMusic
+	settingFetchQuotes
+	settingTheme
+)
+
+var settingsRows = []settingsRowKind{settingCaret, settingPace, settingMinWPM, settingMinAccuracy, settingPaste, settingCountdown, settingKeyboard, settingVariety, settingLayout, settingErrorMarking, settingMotion, settingPauseOnBlur, settingVolume, settingKeySounds, settingMusic, settingFetchQuotes, settingTheme}
+
+func updateSettings(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.settingsRow > 0 {
+			m.settingsRow--
+			return m, playSound(soundClick)
+		}
+	case "down", "j":
+		if m.settingsRow < len(settingsRows)-1 {
+			m.settingsRow++
+			return m, playSound(soundClick)
+		}
+	case "left", "h":
+		return changeSetting(m, -1)
+	case "right", "l":
+		return changeSetting(m, 1)
+	case "q", "o":
+		m.state = stateMenu
+		return m, playSound(soundClick)
+	}
+	if m.keys.is(keyMsg, actionBack) {
+		m.state = stateMenu
+		return m, playSound(soundClick)
+	}
+	return m, nil
+}
+
+// changeSetting steps the selected row's value and saves it. Turning
+// online quotes on fetches a batch straight away.
+func changeSetting(m model, direction int) (model, tea.Cmd) {
+	m = handleSettingsChange(m, direction)
+	cmds := []tea.Cmd{playSound(soundClick), saveConfigCmd(m)}
+	if settingsRows[m.settingsRow] == settingFetchQuotes {
+		var fetch tea.Cmd
+		m, fetch = startQuoteFetch(m)
+		cmds = append(cmds, fetch)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleSettingsChange steps the value of the selected row left (-1) or
+// right (+1). Volume, error marking, and theme take effect immediately.
+func handleSettingsChange(m model, direction int) model {
+	switch settingsRows[m.settingsRow] {
+	case settingCaret:
+		m.caret = caretStyle(cycleIndex(int(m.caret), len(caretNames), direction))
+	case settingPace:
+		m.pace = cycleInt(paceLevels, m.pace, direction)
+	case settingMinWPM:
+		m.minWPM = cycleInt(minWPMLevels, m.minWPM, direction)
+	case settingMinAccuracy:
+		m.minAccuracy = cycleInt(minAccuracyLevels, m.minAccuracy, direction)
+	case settingVolume:
+		m.volume = cycleInt(volumeLevels, m.volume, direction)
+		m.muted = false // picking a volume is wanting to hear it
+		soundVolume = m.volume
+	case settingPaste:
+		m.ignorePaste = !m.ignorePaste
+	case settingCountdown:
+		m.countdown = !m.countdown
+	case settingKeyboard:
+		m.keyboard = !m.keyboard
+	case settingVariety:
+		m.wordVariety = wordVariety(cycleIndex(int(m.wordVariety), len(wordVarietyNames), direction))
+	case settingLayout:
+		m.layout = cycleIndex(m.layout, len(keyboardLayouts), direction)
+	case settingErrorMarking:
+		m.errorMarking = errorMarking(cycleIndex(int(m.errorMarking), len(errorMarkingNames), direction))
+		mistakeMarking = m.errorMarking
+		m = selectTheme(m, m.theme)
+	case settingMotion:
+		m.reduceMotion = !m.reduceMotion
+	case settingPauseOnBlur:
+		m.pauseOnBlur = !m.pauseOnBlur
+	case settingKeySounds:
+		m.keySounds = !m.keySounds
+	case settingMusic:
+		m.music = !m.music
+	case settingFetchQuotes:
+		m.fetchQuotes = !m.fetchQuotes
+	case settingTheme:
+		m = selectTheme(m, cycleIndex(m.theme, len(themes), direction))
+	}
+	return m
+}
+
+func viewSettings(m model) string {
+	title := styleTitle.Render("settings")
+	if m.inline && m.themeWarning != "" {
+		// Inline, the warning takes the title's place to save lines
+		title = styleIncorrect.Render(m.themeWarning)
+	}
+
+	parts := []string{title}
+	if !m.inline {
+		// Inline there's no line to spare
+		parts = append(parts, "")
+	}
+	first, last := settingsWindow(m)
+	for i := first; i < last; i++ {
+		row := renderSettingsRow(m, settingsRows[i])
+		if i == m.settingsRow {
+			parts = append(parts, styleHighlight.Render(glyphPointer+" ")+row)
+		} else {
+			parts = append(parts, "  "+row)
+		}
+	}
+	if m.themeWarning != "" && !m.inline {
+		parts = append(parts, "", styleIncorrect.Render(m.themeWarning))
+	}
+	if !m.inline {
+		parts = append(parts, "")
+	}
+	parts = append(parts, styleHint.Render(glyphUpDown+" navigate  "+glyphLeftRight+" change  "+m.keys[actionBack]+" back"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// settingsWindow is the range of rows shown. Inline, the title and hint
+// leave room for fewer rows than there are, so the rows scroll to keep the
+// selected one in view.
+func settingsWindow(m model) (first, last int) {
+	n := len(settingsRows)
+	if !m.inline || n <= inlineHeight-2 {
+		return 0, n
+	}
+	shown := inlineHeight - 2
+	first = min(max(m.settingsRow-shown/2, 0), n-shown)
+	return first, first + shown
+}
+
+func renderSettingsRow(m model, kind settingsRowKind) string {
+	switch kind {
+	case settingCaret:
+		row := styleStatLabel.Render("caret     ")
+		for i, name := range caretNames {
+			row += renderChoice(name, caretStyle(i) == m.caret) + " "
+		}
+		return row
+
+	case settingPace:
+		row := styleStatLabel.Render("pace      ")
+		for _, p := range paceLevels {
+			name := "off"
+			if p > 0 {
+				name = fmt.Sprintf("%d", p)
+			}
+			row += renderChoice(name, p == m.pace) + " "
+		}
+		return row
+
+	case settingMinWPM:
+		row := styleStatLabel.Render("min wpm   ")
+		for _, w := range minWPMLevels {
+			row += renderChoice(floorName(w, ""), w == m.minWPM) + " "
+		}
+		return row
+
+	case settingMinAccuracy:
+		row := styleStatLabel.Render("min acc   ")
+		for _, a := range minAccuracyLevels {
+			row += renderChoice(floorName(a, "%"), a == m.minAccuracy) + " "
+		}
+		return row
+
+	case settingVolume:
+		row := styleStatLabel.Render("volume    ")
+		for _, v := range volumeLevels {
+			row += renderChoice(fmt.Sprintf("%d%%", v), v == m.volume) + " "
+		}
+		if m.muted {
+			row += styleHint.Render("muted")
+		}
+		return row
+
+	case settingPaste:
+		return styleStatLabel.Render("paste     ") +
+			renderChoice("typed", !m.ignorePaste) + " " +
+			renderChoice("ignored", m.ignorePaste)
+
+	case settingCountdown:
+		return styleStatLabel.Render("countdown ") +
+			renderChoice("off", !m.countdown) + " " +
+			renderChoice("on", m.countdown)
+
+	case settingKeyboard:
+		return styleStatLabel.Render("keyboard  ") +
+			renderChoice("off", !m.keyboard) + " " +
+			renderChoice("on", m.keyboard)
+
+	case settingVariety:
+		row := styleStatLabel.Render("variety   ")
+		for i, name := range wordVarietyNames {
+			row += renderChoice(name, wordVariety(i) == m.wordVariety) + " "
+		}
+		return row
+
+	case settingLayout:
+		row := styleStatLabel.Render("layout    ")
+		for i, l := range keyboardLayouts {
+			row += renderChoice(l.name, i == m.layout) + " "
+		}
+		return row
+
+	case settingErrorMarking:
+		row := styleStatLabel.Render("errors    ")
+		for i, name := range errorMarkingNames {
+			row += renderChoice(name, errorMarking(i) == m.errorMarking) + " "
+		}
+		return row
+
+	case settingMotion:
+		return styleStatLabel.Render("motion    ") +
+			renderChoice("full", !m.reduceMotion) + " " +
+			renderChoice("reduced", m.reduceMotion)
+
+	case settingPauseOnBlur:
+		return styleStatLabel.Render("blur      ") +
+			renderChoice("play on", !m.pauseOnBlur) + " " +
+			renderChoice("pause", m.pauseOnBlur)
+
+	case settingKeySounds:
+		return styleStatLabel.Render("keys      ") +
+			renderChoice("off", !m.keySounds) + " " +
+			renderChoice("on", m.keySounds)
+
+	case settingMusic:
+		return styleStatLabel.Render("music     ") +
+			renderChoice("off", !m.music) + " " +
+			renderChoice("on", m.music)
+
+	case settingFetchQuotes:
+		row := styleStatLabel.Render("online    ") +
+			renderChoice("off", !m.fetchQuotes) + " " +
+			renderChoice("on", m.fetchQuotes)
+		if m.fetchQuotes && len(m.onlineQuotes) > 0 {
+			row += " " + styleHint.Render(fmt.Sprintf("%d quotes", len(m.onlineQuotes)))
+		}
+		return row
+
+	case settingTheme:
+		row := styleStatLabel.Render("theme     ")
+		for i, t := range themes {
+			row += renderChoice(t.name, i == m.theme) + " "
+		}
+		return row
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package main
+
+// Sound packs: any embedded sound, or the falling mode music, can be
+// replaced by a file of the same name in the config directory,
+//
+//   $XDG_CONFIG_HOME/cli_typer/sounds/   (default ~/.config/cli_typer/)
+//
+// e.g. sounds/hit.wav, sounds/click.ogg, or sounds/music.ogg. OGG and WAV are supported, and
+// files at a different sample rate are resampled to the speaker's. A file
+// that's missing or fails to decode leaves the embedded sound in place.
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/vorbis"
+	"github.com/gopxl/beep/wav"
+)
+
+func soundPackDir() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "sounds")
+}
+
+// loadPackSound decodes the sound pack's replacement for name, preferring
+// .ogg over .wav. It returns nil if there's no usable file.
+func loadPackSound(name string, format beep.Format) *beep.Buffer {
+	dir := soundPackDir()
+	if dir == "" {
+		return nil
+	}
+	for _, ext := range []string{".ogg", ".wav"} {
+		f, err := os.Open(filepath.Join(dir, name+ext))
+		if err != nil {
+			continue
+		}
+		var s beep.StreamSeekCloser
+		var fileFormat beep.Format
+		if ext == ".ogg" {
+			s, fileFormat, err = vorbis.Decode(f)
+		} else {
+			s, fileFormat, err = wav.Decode(f)
+		}
+		if err != nil {
+			f.Close()
+			continue
+		}
+
+		var streamer beep.Streamer = s
+		if fileFormat.SampleRate != format.SampleRate {
+			streamer = beep.Resample(4, fileFormat.SampleRate, format.SampleRate, s)
+		}
+		buf := beep.NewBuffer(format)
+		buf.Append(streamer)
+		s.Close()
+		if buf.Len() == 0 {
+			continue
+		}
+		return buf
+	}
+	return nil
+}
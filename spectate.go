@@ -0,0 +1,415 @@
+package main
+
+// Spectating: with --spectate-port, a small web server streams classic
+// tests as they're typed, for putting on a stream or a second screen.
+//
+//   cli_typer --spectate-port 8080
+//
+// The server only listens on localhost unless --spectate-lan is given, as
+// anyone who can reach it can watch. http://localhost:8080/ is a page
+// showing the live WPM, and /ws is a WebSocket sending a spectateMessage as
+// JSON a few times a second while a test runs, then one with the result
+// when it ends. A viewer that connects between tests gets the last message
+// straight away.
+//
+// The update loop never waits on the server: it hands each message to
+// spectator.send, which drops it if the broadcaster is behind. The
+// broadcaster goroutine copies it to every connected client, and each
+// client has a goroutine of its own writing to it, so a slow viewer only
+// misses updates. The server starts before the program and closes with it.
+//
+// The WebSocket side is the minimum of RFC 6455 a browser needs: the
+// handshake, unfragmented text frames out, and ping and close frames in.
+// Anything else a client sends is read and ignored.
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	spectateInterval     = 250 * time.Millisecond
+	spectateWriteTimeout = 5 * time.Second
+	spectateRecentErrors = 5    // mistyped words in a state message
+	spectateMaxFrame     = 4096 // bytes; clients only send control frames
+	spectateBacklog      = 16   // messages queued for a slow client
+)
+
+//go:embed spectate.html
+var spectatePage []byte
+
+// spectateMessage is what viewers are sent. Type is "state" for the test
+// in progress or "results" once it's over.
+type spectateMessage struct {
+	Type      string          `json:"type"`
+	Elapsed   float64         `json:"elapsed"`             // seconds, excluding pauses
+	Remaining float64         `json:"remaining,omitempty"` // seconds, time tests only
+	WPM       float64         `json:"wpm"`
+	Word      int             `json:"word"`  // the word being typed
+	Words     int             `json:"words"` // in the test; zen keeps adding more
+	Paused    bool            `json:"paused,omitempty"`
+	Errors    []spectateError `json:"errors,omitempty"` // the last few, oldest first
+
+	RawWPM   float64 `json:"raw_wpm,omitempty"`
+	Accuracy float64 `json:"accuracy,omitempty"`
+	Failed   bool    `json:"failed,omitempty"`
+}
+
+// spectateError is a mistyped word: what it should have been and what was
+// typed so far.
+type spectateError struct {
+	Word  string `json:"word"`
+	Typed string `json:"typed"`
+}
+
+type spectateTickMsg struct {
+	id int
+}
+
+// spectator is the web server and its broadcaster.
+type spectator struct {
+	server *http.Server
+	feed   chan spectateMessage
+	join   chan *spectateClient
+	leave  chan *spectateClient
+	done   chan struct{}
+	once   sync.Once
+}
+
+// spectateClient is a connected viewer.
+type spectateClient struct {
+	conn net.Conn
+	out  chan []byte // frames to write
+	done chan struct{}
+	once sync.Once
+}
+
+// startSpectator listens on port on localhost, or with lan on every
+// interface. It's done before the program starts, so a port that's taken
+// is reported straight away.
+func startSpectator(port int, lan bool) (*spectator, error) {
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	if lan {
+		addr = fmt.Sprintf(":%d", port)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &spectator{
+		feed:  make(chan spectateMessage, spectateBacklog),
+		join:  make(chan *spectateClient),
+		leave: make(chan *spectateClient),
+		done:  make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(spectatePage)
+	})
+	mux.HandleFunc("/ws", s.serveWebSocket)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: spectateWriteTimeout}
+	go s.server.Serve(listener)
+	go s.broadcast()
+	return s, nil
+}
+
+// close stops the server and disconnects every viewer.
+func (s *spectator) close() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		close(s.done)
+		s.server.Close()
+	})
+}
+
+// send queues msg for the viewers without ever blocking; with no
+// spectator, or the broadcaster behind, it's dropped.
+func (s *spectator) send(msg spectateMessage) {
+	if s == nil {
+		return
+	}
+	select {
+	case s.feed <- msg:
+	default:
+	}
+}
+
+// broadcast copies each message to every client until the spectator is
+// closed. It owns the client set, so nothing else needs to lock it.
+func (s *spectator) broadcast() {
+	clients := map[*spectateClient]bool{}
+	var last []byte
+	for {
+		select {
+		case c := <-s.join:
+			clients[c] = true
+			if last != nil {
+				c.send(last)
+			}
+		case c := <-s.leave:
+			delete(clients, c)
+		case msg := <-s.feed:
+			data, err := json.Marshal(msg)
+			if err != nil {
+				continue
+			}
+			last = wsFrame(wsText, data)
+			for c := range clients {
+				c.send(last)
+			}
+		case <-s.done:
+			for c := range clients {
+				c.close()
+			}
+			return
+		}
+	}
+}
+
+// serveWebSocket upgrades a request to a WebSocket and keeps reading it
+// until the viewer leaves.
+func (s *spectator) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		http.Error(w, "expected a WebSocket connection", http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "can't upgrade this connection", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	accept := sha1.Sum([]byte(key + wsGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+
+	c := &spectateClient{conn: conn, out: make(chan []byte, spectateBacklog), done: make(chan struct{})}
+	go c.write()
+	select {
+	case s.join <- c:
+	case <-s.done:
+		c.close()
+		return
+	}
+	c.read(rw.Reader)
+	c.close()
+	select {
+	case s.leave <- c:
+	case <-s.done:
+	}
+}
+
+// send queues a frame for the client, dropping it if the client is behind.
+func (c *spectateClient) send(frame []byte) {
+	select {
+	case c.out <- frame:
+	default:
+	}
+}
+
+func (c *spectateClient) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *spectateClient) write() {
+	for {
+		select {
+		case frame := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(spectateWriteTimeout))
+			if _, err := c.conn.Write(frame); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// read answers pings until the client closes the connection or it fails.
+func (c *spectateClient) read(r *bufio.Reader) {
+	for {
+		opcode, payload, err := readWSFrame(r)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsPing:
+			c.send(wsFrame(wsPong, payload))
+		case wsClose:
+			c.conn.SetWriteDeadline(time.Now().Add(spectateWriteTimeout))
+			c.conn.Write(wsFrame(wsClose, nil))
+			return
+		}
+	}
+}
+
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xa
+
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // from RFC 6455
+)
+
+// wsFrame is an unfragmented, unmasked frame, as a server sends them.
+func wsFrame(opcode byte, payload []byte) []byte {
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	return append(frame, payload...)
+}
+
+// readWSFrame reads one frame from a client, unmasking its payload.
+func readWSFrame(r *bufio.Reader) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = header[0] & 0x0f
+	n := uint64(header[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > spectateMaxFrame {
+		return 0, nil, errors.New("frame too large")
+	}
+	var mask [4]byte
+	masked := header[1]&0x80 != 0
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+func spectateTickCmd(m model) tea.Cmd {
+	if m.spectator == nil {
+		return nil
+	}
+	id := m.spectateTickID
+	return tea.Tick(spectateInterval, func(time.Time) tea.Msg {
+		return spectateTickMsg{id: id}
+	})
+}
+
+// spectateState is the test in progress, for viewers.
+func spectateState(m model) spectateMessage {
+	msg := spectateMessage{
+		Type:    "state",
+		Elapsed: typingElapsed(m).Seconds(),
+		WPM:     liveWPM(m),
+		Word:    m.wordIndex,
+		Words:   len(m.words),
+		Paused:  m.paused,
+		Errors:  recentErrors(m, spectateRecentErrors),
+	}
+	if m.testMode == testModeTime && !usesStopwatch(m) {
+		msg.Remaining = m.timer.Timeout.Seconds()
+	}
+	return msg
+}
+
+// spectateResults is the finished test's result, for viewers.
+func spectateResults(m model) spectateMessage {
+	return spectateMessage{
+		Type:     "results",
+		Elapsed:  m.finalTime,
+		WPM:      m.finalWPM,
+		Word:     m.wordIndex,
+		Words:    len(m.words),
+		Errors:   recentErrors(m, spectateRecentErrors),
+		RawWPM:   m.finalRawWPM,
+		Accuracy: m.finalAccuracy,
+		Failed:   m.testFailed,
+	}
+}
+
+// recentErrors is up to n of the latest mistyped words, oldest first: typed
+// words that don't match, and the current word if what's typed of it so
+// far is already wrong.
+func recentErrors(m model, n int) []spectateError {
+	var errs []spectateError
+	for i := min(m.wordIndex, len(m.words)-1); i >= 0 && len(errs) < n; i-- {
+		typed := string(m.input[i])
+		wrong := typed != m.words[i]
+		if i == m.wordIndex {
+			wrong = !strings.HasPrefix(m.words[i], typed)
+		}
+		if wrong {
+			errs = append(errs, spectateError{Word: m.words[i], Typed: typed})
+		}
+	}
+	for i, j := 0, len(errs)-1; i < j; i, j = i+1, j-1 {
+		errs[i], errs[j] = errs[j], errs[i]
+	}
+	return errs
+}
@@ -0,0 +1,55 @@
+package main
+
+// Splitting aliens: a big alien, one with a word of splitMinRunes or more,
+// sometimes breaks in two when it's destroyed. The halves are small
+// aliens carrying the first and last splitFragment letters of its word,
+// and they carry on falling from where it was, one to each side. A half
+// that would land on another alien is left out.
+//
+// Nukes destroy big aliens outright, and invaders formations don't split.
+
+import "unicode/utf8"
+
+const (
+	splitMinRunes = 7
+	splitChance   = 3 // 1 in N big aliens split
+	splitFragment = 3 // letters in each half's word
+)
+
+// splitAlien adds the halves of a destroyed alien, if it splits. They go
+// on the end of m.fallingWords, so the target's index is unaffected.
+func splitAlien(m model, parent fallingWord) model {
+	if invadersGame(m) || parent.power != powerNone || parent.decoy ||
+		utf8.RuneCountInString(parent.word) < splitMinRunes || m.rng.Intn(splitChance) != 0 {
+		return m
+	}
+	runes := []rune(parent.word)
+	halves := []string{string(runes[:splitFragment]), string(runes[len(runes)-splitFragment:])}
+
+	center := wordCenter(parent)
+	playWidth := fallingPlayWidth(m)
+	for i, word := range halves {
+		art := buildAlienArt(word, powerNone)
+		x := center + 1
+		if i == 0 {
+			x = center - art.width - 1
+		}
+		x = min(max(x, edgePadding), playWidth-art.width-edgePadding)
+
+		m.fallingNextID++
+		m.fallingWords = append(m.fallingWords, fallingWord{
+			id:     m.fallingNextID,
+			word:   word,
+			x:      x,
+			fx:     float64(x),
+			vx:     driftVelocity(m),
+			y:      parent.y,
+			sprite: art,
+		})
+		if last := len(m.fallingWords) - 1; collidesAt(m, last, x) {
+			m.fallingWords = m.fallingWords[:last]
+		}
+	}
+	m.fallingPeakAliens = max(m.fallingPeakAliens, len(m.fallingWords))
+	return m
+}
@@ -0,0 +1,160 @@
+package main
+
+// Sprints (menu option): a classic test timed in sprintWords-word sprints,
+// like laps. The status bar shows how long the current sprint has taken
+// and the best full sprint so far, and the results list every sprint's
+// time and WPM.
+//
+// A sprint's end is recorded when the space after its last word is
+// pressed, with the test's own elapsed time, so pauses don't count
+// against it and a restart starts the splits over. Stepping back into an
+// earlier word with freedom on doesn't move a recorded end. The last
+// sprint ends with the test: when the test runs out of time part way
+// through, it's shown as partial and can't be the best.
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	sprintWords     = 10
+	sprintsPerLine  = 3
+	maxSprintsShown = 3 * sprintsPerLine
+)
+
+// sprint is one sprint of a finished test.
+type sprint struct {
+	time    time.Duration
+	wpm     float64
+	partial bool // fewer than sprintWords words, cut short by the end
+}
+
+// recordSprint notes the end of a sprint once the word before wordIndex
+// finished it.
+func recordSprint(m model) model {
+	if m.sprints && m.wordIndex/sprintWords > len(m.splits) {
+		m.splits = append(m.splits, typingElapsed(m))
+	}
+	return m
+}
+
+// bestSplit is the fastest full sprint recorded so far.
+func bestSplit(splits []time.Duration) (best time.Duration, ok bool) {
+	var start time.Duration
+	for _, end := range splits {
+		if d := end - start; !ok || d < best {
+			best, ok = d, true
+		}
+		start = end
+	}
+	return best, ok
+}
+
+// renderSprintStatus is the status bar's sprint clock for a started test.
+func renderSprintStatus(m model) string {
+	var start time.Duration
+	if n := len(m.splits); n > 0 {
+		start = m.splits[n-1]
+	}
+	current := typingElapsed(m) - start
+	s := styleHint.Render(fmt.Sprintf("sprint %d ", len(m.splits)+1)) +
+		styleTimer.Render(fmt.Sprintf("%ds", int(current.Seconds())))
+	if best, ok := bestSplit(m.splits); ok {
+		s += styleHint.Render(fmt.Sprintf("  best %.1fs", best.Seconds()))
+	}
+	return s
+}
+
+// finishedSprints splits a finished test into its sprints, the last
+// running from the final recorded end to the end of the test.
+func finishedSprints(m model) []sprint {
+	reached := m.wordIndex
+	if m.wordIndex < len(m.input) && len(m.input[m.wordIndex]) > 0 {
+		reached++
+	}
+	reached = max(reached, len(m.splits)*sprintWords)
+	end := time.Duration(m.finalTime * float64(time.Second))
+
+	var sprints []sprint
+	var start time.Duration
+	for i := 0; i*sprintWords < reached; i++ {
+		first, last := i*sprintWords, min((i+1)*sprintWords, reached)
+		stop := end
+		if i < len(m.splits) {
+			stop = m.splits[i]
+		}
+		s := sprint{time: stop - start, partial: last-first < sprintWords}
+		if s.time > 0 {
+			s.wpm = float64(sprintChars(m, first, last)) / 5 / s.time.Minutes()
+		}
+		sprints = append(sprints, s)
+		start = stop
+	}
+	return sprints
+}
+
+// sprintChars counts the correct characters typed in words first to last
+// (exclusive), with the spaces after them, as calculateResults does.
+func sprintChars(m model, first, last int) int {
+	n := 0
+	for i := first; i < last && i < len(m.words); i++ {
+		typed, target := m.input[i], []rune(m.words[i])
+		for j := 0; j < len(typed) && j < len(target); j++ {
+			if typed[j] == target[j] {
+				n++
+			}
+		}
+		if i < m.wordIndex {
+			n++
+		}
+	}
+	return n
+}
+
+// bestSprint is the index of the fastest full sprint, or -1 if none was.
+func bestSprint(sprints []sprint) int {
+	best := -1
+	for i, s := range sprints {
+		if !s.partial && (best < 0 || s.time < sprints[best].time) {
+			best = i
+		}
+	}
+	return best
+}
+
+// renderSprints lists the sprints of a finished test, sprintsPerLine to a
+// line and up to maxSprintsShown of them, with the best one highlighted
+// and a partial last one dimmed.
+func renderSprints(sprints []sprint) []string {
+	bestIndex := bestSprint(sprints)
+	// Too many to show leaves the last place for the count of the rest
+	shown := sprints
+	if len(shown) > maxSprintsShown {
+		shown = shown[:maxSprintsShown-1]
+	}
+
+	var lines []string
+	line := styleStatLabel.Render("sprints      ")
+	for i, s := range shown {
+		if i > 0 && i%sprintsPerLine == 0 {
+			lines = append(lines, line)
+			line = styleStatLabel.Render("             ")
+		} else if i > 0 {
+			line += "  "
+		}
+		text := fmt.Sprintf("%5.1fs %3.0f wpm", s.time.Seconds(), s.wpm)
+		switch {
+		case i == bestIndex:
+			line += styleHighlight.Render(text)
+		case s.partial:
+			line += styleHint.Render(text)
+		default:
+			line += styleStatValue.Render(text)
+		}
+	}
+	if len(sprints) > len(shown) {
+		line += styleHint.Render(fmt.Sprintf(" +%d more", len(sprints)-len(shown)))
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,154 @@
+package main
+
+// Stars for the night half of the day/night cycle.
+//
+// The star field is scattered by a hash of the playfield size, so the
+// stars hold still from frame to frame and only move when the terminal is
+// resized. They fade in after sunset and out again before dawn.
+//
+// A few times a night a shooting star streaks down across the sky. Its
+// schedule is a hash of the night's number, so nothing is stored on the
+// model. Destroying an alien while one is in the sky is worth
+// shootingStarBonus extra points.
+
+import "github.com/charmbracelet/lipgloss"
+
+const (
+	starDensity       = 60 // one star per this many cells of sky
+	starFade          = 0.1
+	shootingStarsEach = 3 // shooting stars per night
+	shootingStarTicks = 8 // ticks each one is visible
+	shootingStarTrail = 3 // cells of trail behind its head
+	shootingStarBonus = 50
+)
+
+var (
+	starColor   = rgb{170, 180, 210}
+	brightColor = rgb{240, 240, 255}
+)
+
+// starHash is a small integer hash (from splitmix32) for placing stars.
+func starHash(x uint32) uint32 {
+	x += 0x9e3779b9
+	x = (x ^ (x >> 16)) * 0x85ebca6b
+	x = (x ^ (x >> 13)) * 0xc2b2ae35
+	return x ^ (x >> 16)
+}
+
+// nightProgress reports how far through the night tick is, 0 to 1, and
+// false during the day.
+func nightProgress(tick int) (float64, bool) {
+	pos := tick % fullCycleTicks
+	if pos < halfCycleTicks {
+		return 0, false
+	}
+	return float64(pos-halfCycleTicks) / float64(halfCycleTicks), true
+}
+
+// starBrightness is how visible the stars are, 0 to 1: they come out once
+// the sunset has faded and are gone before the dawn starts.
+func starBrightness(tick int) float64 {
+	progress, night := nightProgress(tick)
+	if !night {
+		return 0
+	}
+	const edge = 0.08 // the sunset and dawn transitions (see cycleColors)
+	in := clamp((progress-edge)/starFade, 0, 1)
+	out := clamp((1-edge-progress)/starFade, 0, 1)
+	return in * out
+}
+
+// renderStarsOnGrid draws the star field and any shooting star. It goes
+// first, so everything else is drawn over it.
+func renderStarsOnGrid(grid *cellGrid, tick int) {
+	brightness := starBrightness(tick)
+	if brightness == 0 {
+		return
+	}
+	faint := grid.addStyle(lipgloss.NewStyle().Foreground(profileColor(lipgloss.Color(lerpRGB(nightBg, starColor, brightness).toHex()))))
+	bright := grid.addStyle(lipgloss.NewStyle().Foreground(profileColor(lipgloss.Color(lerpRGB(nightBg, brightColor, brightness).toHex()))))
+
+	// Stars stay out of the bottom rows, where the shield and turret are
+	sky := grid.height - 3
+	if sky < 1 {
+		return
+	}
+	seed := uint32(grid.width)<<16 | uint32(grid.height)
+	count := grid.width * sky / starDensity
+	for i := 0; i < count; i++ {
+		h := starHash(seed + uint32(i))
+		col := int(h % uint32(grid.width))
+		row := int((h >> 12) % uint32(sky))
+		if h>>28 == 0 { // about one in sixteen
+			grid.setString(row, col, glyphSpark, bright)
+		} else {
+			grid.setString(row, col, glyphDust, faint)
+		}
+	}
+
+	if s, ok := shootingStarAt(tick, grid.width, sky); ok {
+		for i := shootingStarTrail; i >= 1; i-- {
+			grid.setString(s.row-i, s.col-i*s.dir*2, glyphDust, faint)
+		}
+		grid.setString(s.row, s.col, glyphSparkBig, bright)
+	}
+}
+
+// shootingStar is the head of a shooting star in one frame.
+type shootingStar struct {
+	row, col int
+	dir      int // 1 heading right, -1 left
+}
+
+// shootingStarAt returns the shooting star in the sky at tick, if any.
+// Each night's stars are spread through the dark part of the night, one
+// to each equal slice of it, at a hashed time within the slice.
+func shootingStarAt(tick, width, sky int) (shootingStar, bool) {
+	progress, night := nightProgress(tick)
+	if !night || starBrightness(tick) < 1 || width < 1 || sky < 1 {
+		return shootingStar{}, false
+	}
+	night0 := tick - int(progress*halfCycleTicks) // first tick of this night
+	nightNum := uint32(tick / fullCycleTicks)
+
+	// The fully dark stretch, split into one slice per shooting star
+	start := night0 + int((0.08+starFade)*halfCycleTicks)
+	end := night0 + int((0.92-starFade)*halfCycleTicks)
+	slice := (end - start) / shootingStarsEach
+	if slice <= shootingStarTicks {
+		return shootingStar{}, false
+	}
+	k := (tick - start) / slice
+	if k < 0 || k >= shootingStarsEach {
+		return shootingStar{}, false
+	}
+
+	h := starHash(nightNum*shootingStarsEach + uint32(k) + 1)
+	begin := start + k*slice + int(h%uint32(slice-shootingStarTicks))
+	age := tick - begin
+	if age < 0 || age >= shootingStarTicks {
+		return shootingStar{}, false
+	}
+
+	// Start in the top third, heading away from the nearer edge
+	s := shootingStar{
+		row: int((h>>8)%uint32(sky/3+1)) + age,
+		col: int((h >> 16) % uint32(width)),
+		dir: 1,
+	}
+	if s.col > width/2 {
+		s.dir = -1
+	}
+	s.col += age * 2 * s.dir
+	return s, true
+}
+
+// shootingStarVisible reports whether a falling game's sky has a shooting
+// star in it right now.
+func shootingStarVisible(m model) bool {
+	if !m.dayCycle {
+		return false
+	}
+	_, ok := shootingStarAt(m.fallingTicks, fallingPlayWidth(m), fallingPlayHeight(m)-3)
+	return ok
+}
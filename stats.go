@@ -0,0 +1,158 @@
+package main
+
+// The stats screen, opened from the menu with "s". Shows lifetime aggregates
+// from the results history plus a list of the most recent runs, and on a
+// second page ("a") the achievements (see achievements.go).
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	recentHistoryRows       = 10
+	inlineRecentHistoryRows = 3 // compact screen for inline mode
+)
+
+func updateStats(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "q", "s":
+		m.state = stateMenu
+	case "a":
+		m.statsAchievements = !m.statsAchievements
+	}
+	if m.keys.is(keyMsg, actionBack) {
+		m.state = stateMenu
+	}
+	return m, nil
+}
+
+func viewStats(m model) string {
+	if m.statsAchievements {
+		return viewAchievements(m)
+	}
+	title := styleTitle.Render("stats")
+
+	var classicCount, fallingCount, bestScore, bestWords int
+	var wpmSum, accSum, bestWPM float64
+	misses := keyMisses{}
+	for _, e := range m.history {
+		if e.Mode == "falling" {
+			fallingCount++
+			bestScore = max(bestScore, e.Score)
+			bestWords = max(bestWords, e.Destroyed)
+			continue
+		}
+		misses.merge(e.Misses)
+		if e.Failed {
+			continue
+		}
+		classicCount++
+		wpmSum += e.WPM
+		accSum += e.Accuracy
+		if e.WPM > bestWPM {
+			bestWPM = e.WPM
+		}
+	}
+
+	// Inline mode packs two stats to a line and drops some spacing
+	perLine, recentRows := 1, recentHistoryRows
+	if m.inline {
+		perLine, recentRows = 2, inlineRecentHistoryRows
+	}
+
+	parts := []string{title, ""}
+	if classicCount > 0 {
+		parts = append(parts, renderStats([]statPair{
+			{"tests", fmt.Sprintf("%d", classicCount)},
+			{"average wpm", fmt.Sprintf("%.0f", wpmSum/float64(classicCount))},
+			{"best wpm", fmt.Sprintf("%.0f", bestWPM)},
+			{"average acc", fmt.Sprintf("%.1f%%", accSum/float64(classicCount))},
+		}, perLine)...)
+		parts = append(parts, renderProblemKeys(misses))
+	} else {
+		parts = append(parts, styleHint.Render("no classic tests yet"))
+	}
+	if !m.inline {
+		parts = append(parts, "")
+	}
+	if fallingCount > 0 {
+		best := fmt.Sprintf("%d pts", bestScore)
+		if bestScore == 0 && bestWords > 0 {
+			// Only games from before points
+			best = fmt.Sprintf("%d words", bestWords)
+		}
+		parts = append(parts, renderStats([]statPair{
+			{"falling games", fmt.Sprintf("%d", fallingCount)},
+			{"best score", best},
+		}, perLine)...)
+	} else {
+		parts = append(parts, styleHint.Render("no falling games yet"))
+	}
+
+	if len(m.history) > 0 {
+		parts = append(parts, "")
+		if !m.inline {
+			parts = append(parts, styleStatLabel.Render("recent"))
+		}
+		start := len(m.history) - recentRows
+		if start < 0 {
+			start = 0
+		}
+		for i := len(m.history) - 1; i >= start; i-- {
+			parts = append(parts, renderHistoryLine(m.history[i]))
+		}
+	}
+
+	parts = append(parts, "", styleHint.Render("a achievements  "+m.keys[actionBack]+" menu"))
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderHistoryLine formats one history entry for the recent list.
+func renderHistoryLine(e historyEntry) string {
+	when := styleUntyped.Render(e.Time.Local().Format("Jan 02 15:04"))
+	var setup, result string
+	if e.Mode == "falling" {
+		setup = fmt.Sprintf("falling %-7s %4ds", e.Content, e.Duration)
+		if e.Invaders {
+			// One column short, so the content is cut to fit
+			setup = fmt.Sprintf("invaders %-6.6s %4ds", e.Content, e.Duration)
+		}
+		result = fmt.Sprintf("%d pts", e.Score)
+		if e.Score == 0 && e.Destroyed > 0 {
+			result = fmt.Sprintf("%d words", e.Destroyed)
+		}
+	} else {
+		if e.Daily != "" {
+			setup = fmt.Sprintf("daily   %-13s", e.Daily)
+		} else if e.Lesson != "" {
+			setup = fmt.Sprintf("lesson  %-13s", e.Lesson)
+		} else if e.Practice {
+			setup = fmt.Sprintf("practice %-7s %4dw", "weak", e.WordCount)
+		} else if e.Length != "" {
+			setup = fmt.Sprintf("classic %-7s %4ds", e.Length, e.Duration)
+		} else if e.WordCount > 0 {
+			setup = fmt.Sprintf("classic %-7s %4dw", e.Content, e.WordCount)
+		} else if e.Zen {
+			setup = fmt.Sprintf("zen     %-7s %4ds", e.Content, e.Duration)
+		} else {
+			setup = fmt.Sprintf("classic %-7s %4ds", e.Content, e.Duration)
+		}
+		result = fmt.Sprintf("%3.0f wpm %5.1f%%", e.WPM, e.Accuracy)
+		if e.Failed {
+			return when + "  " + styleCorrect.Render(setup) + "  " + styleIncorrect.Render("failed")
+		}
+		if e.Repeat > 0 {
+			return when + "  " + styleCorrect.Render(setup) + "  " + styleStatValue.Render(result) +
+				styleHint.Render(fmt.Sprintf("  repeat #%d", e.Repeat))
+		}
+	}
+	return when + "  " + styleCorrect.Render(setup) + "  " + styleStatValue.Render(result)
+}
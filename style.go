@@ -8,79 +8,285 @@ package main
 
 import "github.com/charmbracelet/lipgloss"
 
-// Monkeytype-inspired color palette
+// theme is a color palette for the text, UI, and falling mode scenery.
+// Besides the built-ins below, themes can be loaded from files (see
+// themes.go).
+type theme struct {
+	name      string
+	bg        lipgloss.Color // cursor text
+	dim       lipgloss.Color // untyped text, hints, labels
+	text      lipgloss.Color // correctly typed text
+	error     lipgloss.Color // incorrectly typed text
+	cursor    lipgloss.Color // cursor background
+	accent    lipgloss.Color // highlights, accents
+	success   lipgloss.Color // positive results
+	shield    lipgloss.Color
+	alien     lipgloss.Color
+	laser     lipgloss.Color
+	explosion lipgloss.Color
+
+	err string // set when a theme file couldn't be used
+}
+
+// themes is the list offered on the settings screen; the first is the
+// default. Custom themes from files are appended at startup.
+var themes = []theme{
+	{ // Monkeytype-inspired
+		name: "serika", bg: "#323437", dim: "#646669", text: "#d1d0c5", error: "#ca4754",
+		cursor: "#e2b714", accent: "#e2b714", success: "#98c379",
+		shield: "#4fc1ff", alien: "#7c6f9f", laser: "#ff6b6b", explosion: "#ffaa44",
+	},
+	{
+		name: "nord", bg: "#2e3440", dim: "#4c566a", text: "#d8dee9", error: "#bf616a",
+		cursor: "#88c0d0", accent: "#88c0d0", success: "#a3be8c",
+		shield: "#81a1c1", alien: "#b48ead", laser: "#bf616a", explosion: "#d08770",
+	},
+	{
+		name: "dracula", bg: "#282a36", dim: "#6272a4", text: "#f8f8f2", error: "#ff5555",
+		cursor: "#bd93f9", accent: "#bd93f9", success: "#50fa7b",
+		shield: "#8be9fd", alien: "#ff79c6", laser: "#ff5555", explosion: "#ffb86c",
+	},
+	{ // Okabe–Ito colors: errors differ from text in lightness as well as hue
+		name: "colorblind", bg: "#1e1e1e", dim: "#707070", text: "#f0f0f0", error: "#d55e00",
+		cursor: "#f0e442", accent: "#f0e442", success: "#56b4e9",
+		shield: "#56b4e9", alien: "#cc79a7", laser: "#e69f00", explosion: "#e69f00",
+	},
+}
+
+// mistakeMarking is how styleMistake marks errors besides color. Set it
+// before applyTheme, which builds the style.
+var mistakeMarking = markColor
+
+// The current palette, set by applyTheme
 var (
-	colorBg      = lipgloss.Color("#323437") // dark background
-	colorDim     = lipgloss.Color("#646669") // untyped text
-	colorText    = lipgloss.Color("#d1d0c5") // correctly typed text
-	colorError   = lipgloss.Color("#ca4754") // incorrectly typed text
-	colorAccent  = lipgloss.Color("#e2b714") // cursor, highlights, accents
-	colorSuccess = lipgloss.Color("#98c379") // positive results
+	colorBg      lipgloss.Color
+	colorDim     lipgloss.Color
+	colorText    lipgloss.Color
+	colorError   lipgloss.Color
+	colorAccent  lipgloss.Color
+	colorSuccess lipgloss.Color
 )
 
-// Character-level styles (used in the typing view to color individual chars)
+// Styles built from the palette (see applyTheme)
 var (
-	styleUntyped   = lipgloss.NewStyle().Foreground(colorDim)
-	styleCorrect   = lipgloss.NewStyle().Foreground(colorText)
-	styleIncorrect = lipgloss.NewStyle().Foreground(colorError)
-	styleCursor    = lipgloss.NewStyle().Foreground(colorBg).Background(colorAccent)
+	// Character-level styles (used in the typing view to color individual chars)
+	styleUntyped   lipgloss.Style
+	styleCorrect   lipgloss.Style
+	styleIncorrect lipgloss.Style
+	styleMistake   lipgloss.Style // mistyped characters, marked per mistakeMarking
+	styleCursor    lipgloss.Style
+
+	// Alternative carets (see caretStyle)
+	styleCaretUnderline lipgloss.Style
+	styleCaretBar       lipgloss.Style
+	styleGhost          lipgloss.Style // the ghost race's caret (see ghost.go)
+	stylePace           lipgloss.Style // the pace caret (see pace.go)
+	styleRival          lipgloss.Style // a race opponent's caret (see race.go)
+	styleBot            lipgloss.Style // the bot opponent's caret (see bot.go)
+	styleKeyNext        lipgloss.Style // the next key on the keyboard (see keyboard.go)
+	stylePanel          lipgloss.Style // the help panel's border (see help.go)
+
+	// UI elements
+	styleTitle     lipgloss.Style
+	styleTimer     lipgloss.Style
+	styleHint      lipgloss.Style
+	styleStatLabel lipgloss.Style
+	styleStatValue lipgloss.Style
+	styleHighlight lipgloss.Style
+
+	// Results screen — large WPM display
+	styleBigWPM  lipgloss.Style
+	styleLiveWPM lipgloss.Style
+
+	// Falling words mode
+	styleLife          lipgloss.Style
+	styleShield        lipgloss.Style
+	styleShieldDamaged lipgloss.Style
+	styleAlien         lipgloss.Style
+	styleAlienActive   lipgloss.Style
+	styleFriend        lipgloss.Style
+	styleLaser         lipgloss.Style
+	styleExplosion     lipgloss.Style
 )
 
-// UI element styles
-var (
+func init() {
+	applyTheme(themes[0])
+}
+
+// applyTheme switches the palette and rebuilds every style that uses it.
+func applyTheme(t theme) {
+	colorBg = t.bg
+	colorDim = t.dim
+	colorText = t.text
+	colorError = t.error
+	colorAccent = t.accent
+	colorSuccess = t.success
+
+	styleUntyped = lipgloss.NewStyle().Foreground(colorDim)
+	styleCorrect = lipgloss.NewStyle().Foreground(colorText)
+	styleIncorrect = lipgloss.NewStyle().Foreground(colorError)
+	switch mistakeMarking {
+	case markUnderline:
+		styleMistake = styleIncorrect.Underline(true)
+	case markHighlight:
+		styleMistake = lipgloss.NewStyle().Foreground(colorBg).Background(colorError)
+	default:
+		styleMistake = styleIncorrect
+	}
+	styleCursor = lipgloss.NewStyle().Foreground(colorBg).Background(t.cursor)
+
+	styleCaretUnderline = lipgloss.NewStyle().Foreground(colorAccent).Underline(true)
+	styleCaretBar = lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	styleGhost = lipgloss.NewStyle().Foreground(colorBg).Background(colorDim)
+	stylePace = lipgloss.NewStyle().Foreground(colorBg).Background(colorSuccess)
+	styleRival = lipgloss.NewStyle().Foreground(colorBg).Background(t.alien)
+	styleBot = lipgloss.NewStyle().Foreground(colorBg).Background(t.shield)
+	styleKeyNext = lipgloss.NewStyle().Foreground(colorBg).Background(colorAccent)
+	stylePanel = lipgloss.NewStyle().Border(glyphBorder).BorderForeground(colorDim).Padding(0, 2)
+
 	styleTitle = lipgloss.NewStyle().
-			Foreground(colorAccent).
-			Bold(true)
+		Foreground(colorAccent).
+		Bold(true)
 
 	styleTimer = lipgloss.NewStyle().
-			Foreground(colorAccent).
-			Bold(true)
+		Foreground(colorAccent).
+		Bold(true)
 
 	styleHint = lipgloss.NewStyle().
-			Foreground(colorDim)
+		Foreground(colorDim)
 
 	styleStatLabel = lipgloss.NewStyle().
-			Foreground(colorDim)
+		Foreground(colorDim)
 
 	styleStatValue = lipgloss.NewStyle().
-			Foreground(colorAccent).
-			Bold(true)
+		Foreground(colorAccent).
+		Bold(true)
 
 	styleHighlight = lipgloss.NewStyle().
-			Foreground(colorAccent)
+		Foreground(colorAccent)
 
-	// Results screen — large WPM display
 	styleBigWPM = lipgloss.NewStyle().
-			Foreground(colorSuccess).
-			Bold(true)
+		Foreground(colorSuccess).
+		Bold(true)
 
 	styleLiveWPM = lipgloss.NewStyle().
-			Foreground(colorDim)
+		Foreground(colorDim)
 
-	// Falling words mode
 	styleLife = lipgloss.NewStyle().
-			Foreground(colorError).
-			Bold(true)
+		Foreground(colorError).
+		Bold(true)
 
 	styleShield = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#4fc1ff")).
-			Bold(true)
+		Foreground(t.shield).
+		Bold(true)
 
 	styleShieldDamaged = lipgloss.NewStyle().
-				Foreground(colorError)
+		Foreground(colorError)
 
 	styleAlien = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7c6f9f"))
+		Foreground(t.alien)
 
 	styleAlienActive = lipgloss.NewStyle().
-				Foreground(colorAccent).
-				Bold(true)
+		Foreground(colorAccent).
+		Bold(true)
+
+	styleFriend = lipgloss.NewStyle().
+		Foreground(colorSuccess)
 
 	styleLaser = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#ff6b6b")).
-			Bold(true)
+		Foreground(t.laser).
+		Bold(true)
 
 	styleExplosion = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#ffaa44")).
-			Bold(true)
+		Foreground(t.explosion).
+		Bold(true)
+}
+
+// Symbols drawn beyond plain text. useASCIIGlyphs (--ascii) swaps them
+// all for ASCII, for fonts, terminals, and locales that show them as boxes.
+var (
+	glyphPointer   = "▸" // the selected menu row
+	glyphDash      = "—"
+	glyphArrow     = "→"
+	glyphTimes     = "×"
+	glyphEllipsis  = "…"
+	glyphUpDown    = "↑↓"
+	glyphLeftRight = "←→"
+	glyphUnlocked  = "★" // achievements
+	glyphLocked    = "☆"
+	glyphBarFull   = "█" // progress bars
+	glyphBarEmpty  = "░"
+	glyphCheck     = "✓" // a synced result
+	glyphBorder    = lipgloss.RoundedBorder()
+
+	// Falling mode
+	glyphHeart        = "♥"
+	glyphHeartLost    = "♡"
+	glyphShield       = '█'
+	glyphShieldWorn   = '▒'
+	glyphShieldBroken = '░'
+	glyphTurret       = '▲'
+	glyphLaser        = '│'
+	glyphLaserRight   = '╱' // a shot slanting up to the right
+	glyphLaserLeft    = '╲'
+	glyphSparkBig     = "✦" // explosion particles, largest first
+	glyphSparkRing    = "◇"
+	glyphSpark        = "✧"
+	glyphDust         = "·"
+)
+
+// useASCIIGlyphs switches every glyph to plain ASCII. The replacements in
+// the falling playfield keep the same width, so nothing shifts.
+func useASCIIGlyphs() {
+	glyphPointer = ">"
+	glyphDash = "-"
+	glyphArrow = "->"
+	glyphTimes = "x"
+	glyphEllipsis = "..."
+	glyphUpDown = "j/k"
+	glyphLeftRight = "h/l"
+	glyphUnlocked = "*"
+	glyphLocked = "-"
+	glyphBarFull = "#"
+	glyphBarEmpty = "."
+	glyphCheck = "ok"
+	glyphBorder = lipgloss.ASCIIBorder()
+
+	glyphHeart = "<3"
+	glyphHeartLost = "x"
+	glyphShield = '#'
+	glyphShieldWorn = '='
+	glyphShieldBroken = '-'
+	glyphTurret = '^'
+	glyphLaser = '|'
+	glyphLaserRight = '/'
+	glyphLaserLeft = '\\'
+	glyphSparkBig = "*"
+	glyphSparkRing = "o"
+	glyphSpark = "+"
+	glyphDust = "."
+	moonSprites = asciiMoonSprites
+}
+
+// themeIndex finds a theme by name, or returns -1.
+func themeIndex(name string) int {
+	for i, t := range themes {
+		if t.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Power-up colors, the same in every theme so they're always recognizable
+var (
+	// Power-up aliens, and every alien while a freeze is active
+	styleFrozen = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#9fe8ff"))
+
+	powerUpStyles = map[powerUp]lipgloss.Style{
+		powerFreeze: styleFrozen.Bold(true),
+		powerNuke:   lipgloss.NewStyle().Foreground(lipgloss.Color("#ff6b6b")).Bold(true),
+		powerHeart:  lipgloss.NewStyle().Foreground(lipgloss.Color("#ff8fc8")).Bold(true),
+	}
 )
@@ -0,0 +1,145 @@
+package main
+
+// Custom themes, one JSON file per theme in the config directory:
+//
+//   $XDG_CONFIG_HOME/cli_typer/themes/mytheme.json   (default ~/.config/cli_typer/)
+//
+// The file name (minus .json) is the theme's name on the settings screen.
+// Every role is a hex color:
+//
+//   {
+//     "background": "#323437",   cursor text
+//     "untyped":    "#646669",   untyped text, hints, labels
+//     "correct":    "#d1d0c5",
+//     "incorrect":  "#ca4754",
+//     "cursor":     "#e2b714",   cursor background
+//     "accent":     "#e2b714",   highlights, stats
+//     "shield":     "#4fc1ff",
+//     "alien":      "#7c6f9f",
+//     "laser":      "#ff6b6b",
+//     "explosion":  "#ffaa44",
+//     "success":    "#98c379"    optional, defaults to correct
+//   }
+//
+// Themes are discovered once at startup. A file with a missing or invalid
+// color is still listed, but picking it keeps the default theme and shows
+// a warning saying what's wrong.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// themeRoles lists the keys a theme file must define, in file order.
+var themeRoles = []string{
+	"background", "untyped", "correct", "incorrect", "cursor", "accent",
+	"shield", "alien", "laser", "explosion",
+}
+
+func themesDir() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "themes")
+}
+
+// loadCustomThemes reads every theme file, sorted by name. Files named
+// like a built-in theme are skipped.
+func loadCustomThemes() []theme {
+	dir := themesDir()
+	if dir == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(paths)
+
+	var custom []theme
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if themeIndex(name) >= 0 {
+			continue
+		}
+		custom = append(custom, loadThemeFile(name, path))
+	}
+	return custom
+}
+
+// loadThemeFile parses one theme file. Problems are recorded in the
+// theme's err rather than returned, so the theme can still be listed.
+func loadThemeFile(name, path string) theme {
+	t := theme{name: name}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.err = fmt.Sprintf("can't read %s", filepath.Base(path))
+		return t
+	}
+	var colors map[string]string
+	if err := json.Unmarshal(data, &colors); err != nil {
+		t.err = fmt.Sprintf("%s isn't valid JSON", filepath.Base(path))
+		return t
+	}
+
+	for _, role := range themeRoles {
+		c, ok := colors[role]
+		if !ok {
+			t.err = fmt.Sprintf("missing %q", role)
+			return t
+		}
+		if !hexColor.MatchString(c) {
+			t.err = fmt.Sprintf("%q is %q, not a hex color", role, c)
+			return t
+		}
+	}
+	success := colors["correct"]
+	if c, ok := colors["success"]; ok {
+		if !hexColor.MatchString(c) {
+			t.err = fmt.Sprintf("%q is %q, not a hex color", "success", c)
+			return t
+		}
+		success = c
+	}
+
+	t.bg = lipgloss.Color(colors["background"])
+	t.dim = lipgloss.Color(colors["untyped"])
+	t.text = lipgloss.Color(colors["correct"])
+	t.error = lipgloss.Color(colors["incorrect"])
+	t.cursor = lipgloss.Color(colors["cursor"])
+	t.accent = lipgloss.Color(colors["accent"])
+	t.success = lipgloss.Color(success)
+	t.shield = lipgloss.Color(colors["shield"])
+	t.alien = lipgloss.Color(colors["alien"])
+	t.laser = lipgloss.Color(colors["laser"])
+	t.explosion = lipgloss.Color(colors["explosion"])
+	return t
+}
+
+// selectTheme makes themes[i] the current theme. A broken custom theme
+// stays selected (so the choice is remembered) but the default palette is
+// applied, with a warning saying why.
+func selectTheme(m model, i int) model {
+	if m.menuWarning == m.themeWarning {
+		m.menuWarning = "" // the startup copy of an old warning
+	}
+	m.theme = i
+	m.themeWarning = ""
+	if t := themes[i]; t.err != "" {
+		m.themeWarning = fmt.Sprintf("theme %s: %s %s using %s", t.name, t.err, glyphDash, themes[0].name)
+		applyTheme(themes[0])
+		return m
+	}
+	applyTheme(themes[i])
+	return m
+}
@@ -0,0 +1,53 @@
+package main
+
+// The timed falling format (menu option): survive timeLimit seconds, one
+// of timeLimitChoices, instead of playing until the last life is lost.
+//
+// The clock runs on game ticks, so pauses don't count against it, and the
+// status bar counts it down. The difficulty ramp that takes the endless
+// format minutes is squeezed into the time: it reaches top speed and the
+// fastest spawns with timedHardSeconds to go. Losing every life first is
+// the usual game over; lasting to the end is a win.
+
+import "time"
+
+const timedHardSeconds = 15 // seconds at full difficulty at the end
+
+var timeLimitChoices = []int{60, 120}
+
+// timedGame reports whether a game is played against the clock. Invaders
+// has no format row, so it never is.
+func timedGame(m model) bool {
+	return m.timeLimit > 0 && m.gameMode == gameModeFalling
+}
+
+// secondsToTicks converts seconds of play to game ticks.
+func secondsToTicks(seconds int) int {
+	return int(time.Duration(seconds) * time.Second / (framesPerTick * frameInterval))
+}
+
+// fullRampTicks is how long the endless ramp takes to reach top speed,
+// the slowest part of the difficulty to max out.
+func fullRampTicks() int {
+	return int((fallingMaxSpeed-fallingBaseSpeed)/fallingSpeedStep) * rampStepTicks
+}
+
+// timedRampTicks scales ticks played so the difficulty ramp peaks
+// timedHardSeconds before the clock runs out.
+func timedRampTicks(m model, ticks int) int {
+	peak := max(secondsToTicks(m.timeLimit-timedHardSeconds), 1)
+	return ticks * fullRampTicks() / peak
+}
+
+// timedSecondsLeft is what the status bar counts down, rounded up so it
+// reads 1 until the very end.
+func timedSecondsLeft(m model) int {
+	left := secondsToTicks(m.timeLimit) - m.fallingTicks
+	tick := framesPerTick * frameInterval
+	return int((time.Duration(max(left, 0))*tick + time.Second - 1) / time.Second)
+}
+
+// timeUp reports whether a timed game has lasted the full time.
+func timeUp(m model) bool {
+	return timedGame(m) && m.fallingTicks >= secondsToTicks(m.timeLimit)
+}
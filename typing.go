@@ -6,19 +6,52 @@ package main
 //   - Each regular keypress appends a rune to input[wordIndex]
 //   - Space advances to the next word
 //   - Backspace removes the last rune from the current word
-//   - You can't backspace into a previous word (matches monkeytype)
+//   - You can't backspace into a previous word (matches monkeytype), unless
+//     freedom mode is on and that word was typed incorrectly
 //
-// Timer:
+// Timer (time tests):
 //   - Created in initTypingState but NOT started
-//   - Started on the very first keypress (via timer.Init())
+//   - Started on the very first keypress (via timer.Init()), or when the
+//     3-2-1 countdown (an option on the settings screen) reaches zero;
+//     keys other than tab (restart the countdown) and esc are ignored
+//     until then
 //   - Ticks every second, sending timer.TickMsg which triggers a re-render
 //   - When it hits zero, sends timer.TimeoutMsg → transition to results
+//   - A restart makes a new timer; ticks and timeouts already sent by the
+//     old one are dropped by their ID
+//   - A fast typist can get through all 200 words first; the test then ends
+//     on the last word like a word-count test, with results using the real
+//     elapsed time rather than the full duration
+//
+// Difficulty:
+//   - expert: space is refused while the current word is wrong; the word
+//     flashes in the error style until the next keypress
+//   - master: the first wrong character (or space on a wrong word) ends the
+//     test as failed
+//
+// Pause:
+//   - Esc during a started test pauses instead of leaving. The timer (or
+//     stopwatch) is stopped and startTime is pushed forward by the length of
+//     the pause on resume, so elapsed time, live WPM, and the per-second
+//     keystroke buckets all ignore the time spent paused.
+//
+// Zen tests (see zen.go) run the stopwatch and end on enter.
+//
+// Tab, esc, and enter are the default restart, pause, and finish keys;
+// the keymap has the ones actually bound (see keymap.go).
+//
+// Word-count and quote tests never start the timer. The test ends as soon as
+// the last word is typed correctly (or space is pressed on it), and results
+// use the real elapsed time since the first keypress. Quote tests run a
+// stopwatch instead so the status bar can count up.
 
 import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/charmbracelet/bubbles/stopwatch"
 	"github.com/charmbracelet/bubbles/timer"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -34,27 +67,128 @@ func updateTyping(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
 		// which decrements the remaining time and returns a cmd to schedule
 		// the next tick. This is the "command" pattern in Elm architecture —
 		// side effects (like scheduling a future tick) are returned as commands,
-		// never executed directly.
+		// never executed directly. A restart makes a new timer, so ticks
+		// still on their way from the old one are dropped by ID.
+		if msg.ID != m.timer.ID() {
+			return m, nil
+		}
 		var cmd tea.Cmd
 		m.timer, cmd = m.timer.Update(msg)
 		return m, cmd
 
+	case timer.StartStopMsg:
+		var cmd tea.Cmd
+		m.timer, cmd = m.timer.Update(msg)
+		return m, cmd
+
+	case stopwatch.TickMsg, stopwatch.StartStopMsg:
+		var cmd tea.Cmd
+		m.stopwatch, cmd = m.stopwatch.Update(msg)
+		return m, cmd
+
+	case ghostTickMsg:
+		if msg.id != m.ghostTickID || m.ghostKeys == nil {
+			return m, nil
+		}
+		m = advanceGhost(m)
+		return m, ghostTickCmd(m)
+
+	case botTickMsg:
+		// Like the pace caret, the bot's position is worked out on render
+		if msg.id != m.botTickID {
+			return m, nil
+		}
+		return m, botTickCmd(m)
+
+	case spectateTickMsg:
+		if msg.id != m.spectateTickID {
+			return m, nil
+		}
+		m.spectator.send(spectateState(m))
+		return m, spectateTickCmd(m)
+
+	case paceTickMsg:
+		// Nothing to update: the tick just re-renders the pace caret
+		if msg.id != m.paceTickID {
+			return m, nil
+		}
+		return m, paceTickCmd(m)
+
+	case floorTickMsg:
+		if msg.id != m.floorTickID {
+			return m, nil
+		}
+		next, cmd := checkFloors(m)
+		if next.state != stateTyping {
+			return next, cmd
+		}
+		return next, floorTickCmd(next)
+
 	case timer.TimeoutMsg:
-		// Time's up! Calculate results and switch screens.
-		m = calculateResults(m)
-		m.state = stateResults
-		return m, nil
+		// Time's up! Calculate results and switch screens — unless the
+		// timeout is from a timer a restart has since replaced.
+		if msg.ID != m.timer.ID() {
+			return m, nil
+		}
+		return finishTyping(m)
+
+	case countdownTickMsg:
+		if msg.id != m.countdownID || m.countdownLeft == 0 {
+			return m, nil
+		}
+		m.countdownLeft--
+		if m.countdownLeft > 0 {
+			return m, countdownTickCmd(m)
+		}
+		return startTypingClock(m)
 
 	case tea.KeyMsg:
-		// Start the timer on the very first keypress.
-		// timer.Init() returns a Cmd that kicks off the first tick.
+		if m.countdownLeft > 0 {
+			switch {
+			case m.keys.is(msg, actionRestart) && m.race == nil:
+				m = startCountdown(m)
+				return m, countdownTickCmd(m)
+			case m.keys.is(msg, actionPause):
+				m.countdownLeft = 0
+				m.state = stateMenu
+			}
+			return m, nil
+		}
+
+		if m.confirmingQuit {
+			return answerQuit(m, msg)
+		}
+		if m.paused {
+			switch {
+			case m.keys.is(msg, actionPause):
+				return resumeTyping(m)
+			case m.keys.is(msg, actionFinish) || msg.Type == tea.KeyCtrlJ:
+				if zenTest(m) {
+					return finishZen(m)
+				}
+			case m.keys.is(msg, actionQuit):
+				if quitNeedsConfirm(m) {
+					m.confirmingQuit = true
+					return m, nil
+				}
+				return quitToMenu(m)
+			}
+			return m, nil
+		}
+
+		if m.ignorePaste && pastedKey(msg) {
+			return m, nil // not even the first key of a test
+		}
+		msg = emulateLayout(m, msg)
+
+		// Start the timer on the very first keypress
 		if !m.timerStarted {
-			m.timerStarted = true
-			m.startTime = time.Now()
-			cmd := m.timer.Init()
+			var cmd tea.Cmd
+			m, cmd = startTypingClock(m)
 			// Process this keypress AND start the timer simultaneously
-			m, _ = processKeypress(m, msg)
-			return m, cmd
+			var keyCmd tea.Cmd
+			m, keyCmd = processKeypress(m, msg)
+			return m, tea.Batch(cmd, keyCmd)
 		}
 
 		return processKeypress(m, msg)
@@ -63,98 +197,310 @@ func updateTyping(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// startTypingClock starts the test's clock: the timer or stopwatch, plus
+// the ghost, pace caret, bot, and spectator ticks. timer.Init() returns a
+// Cmd that kicks off the first tick.
+func startTypingClock(m model) (model, tea.Cmd) {
+	m.timerStarted = true
+	m.startTime = time.Now()
+	var cmd tea.Cmd
+	if usesStopwatch(m) {
+		cmd = m.stopwatch.Init()
+	} else if m.testMode == testModeTime {
+		cmd = m.timer.Init()
+	}
+	return m, tea.Batch(cmd, ghostTickCmd(m), paceTickCmd(m), botTickCmd(m), spectateTickCmd(m), floorTickCmd(m))
+}
+
+// countdownTickMsg steps the countdown before a test starts.
+type countdownTickMsg struct {
+	id int
+}
+
+// startCountdown (re)starts the 3-2-1 countdown.
+func startCountdown(m model) model {
+	m.countdownLeft = 3
+	m.countdownID++
+	return m
+}
+
+// countdownTickCmd schedules the next countdown step, if one is running.
+// Every fresh test returns it, so a test with the countdown turned on
+// starts counting down straight away.
+func countdownTickCmd(m model) tea.Cmd {
+	if m.countdownLeft == 0 {
+		return nil
+	}
+	id := m.countdownID
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return countdownTickMsg{id: id}
+	})
+}
+
 // processKeypress handles a single keypress during the typing test.
 // Separated from updateTyping so we can call it alongside timer.Init()
 // on the first keypress without duplicating logic.
 func processKeypress(m model, msg tea.KeyMsg) (model, tea.Cmd) {
-	switch msg.Type {
+	m.spaceRejected = false
 
-	case tea.KeyEsc:
+	switch {
+	case m.keys.is(msg, actionPause):
+		// The opponent won't wait, so a race can't be paused, only left
+		if m.timerStarted && !racing(m) {
+			return pauseTyping(m)
+		}
 		m.state = stateMenu
 		return m, nil
 
-	case tea.KeyTab:
+	case m.keys.is(msg, actionRestart):
+		if m.race != nil {
+			return m, nil // the race's words are the only ones agreed
+		}
 		m = initTypingState(m)
+		return m, countdownTickCmd(m)
+
+	case m.keys.is(msg, actionFinish) || msg.Type == tea.KeyCtrlJ:
+		if zenTest(m) {
+			return finishZen(m)
+		}
 		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlW, tea.KeyCtrlU:
+		return clearWord(m), nil
 
 	case tea.KeyBackspace:
+		if msg.Alt {
+			return clearWord(m), nil
+		}
 		if m.charIndex > 0 {
 			m.charIndex--
 			m.input[m.wordIndex] = m.input[m.wordIndex][:m.charIndex]
+			m = logKey(m, keyBackspace)
+		} else if m.freedom && m.wordIndex > 0 && string(m.input[m.wordIndex-1]) != m.words[m.wordIndex-1] {
+			// Step back into the previous (mistyped) word, cursor at its end
+			m.wordIndex--
+			m.charIndex = len(m.input[m.wordIndex])
+			m = logKey(m, keyBackspace)
 		}
 		return m, nil
 
 	case tea.KeySpace:
-		// Only advance if the user has typed something for this word.
-		// Prevents accidental double-space from skipping words.
-		if len(m.input[m.wordIndex]) > 0 && m.wordIndex < len(m.words)-1 {
-			m.wordIndex++
-			m.charIndex = 0
-		}
-		return m, nil
+		return typeSpace(m)
 
 	case tea.KeyRunes:
-		char := msg.Runes[0]
-		targetLen := len([]rune(m.words[m.wordIndex]))
-		if m.charIndex < targetLen+maxWordOverflow {
-			m.input[m.wordIndex] = append(m.input[m.wordIndex], char)
-			m.charIndex++
+		// A paste, or fast typing a slow terminal sent together, brings
+		// several characters at once (see paste.go). Each is typed in turn;
+		// only the last one's sound plays, rather than a burst of them.
+		var cmd tea.Cmd
+		for _, char := range msg.Runes {
+			if unicode.IsSpace(char) {
+				m, cmd = typeSpace(m)
+			} else {
+				m, cmd = typeChar(m, char)
+			}
+			if m.state != stateTyping {
+				break // the test ended part-way through
+			}
 		}
-		return m, nil
+		return m, cmd
 	}
 
 	return m, nil
 }
 
-func viewTyping(m model) string {
-	// Adapt to terminal width — cap at 70, shrink for narrow terminals
-	containerWidth := 70
-	if m.width > 0 && m.width-10 < containerWidth {
-		containerWidth = m.width - 10
-		if containerWidth < 30 {
-			containerWidth = 30
+// typeSpace moves on to the next word, or ends the test on the last one.
+func typeSpace(m model) (model, tea.Cmd) {
+	if len(m.input[m.wordIndex]) > 0 && string(m.input[m.wordIndex]) != m.words[m.wordIndex] {
+		switch m.difficulty {
+		case difficultyExpert:
+			m.spaceRejected = true
+			return m, nil
+		case difficultyMaster:
+			return failTyping(m)
 		}
 	}
 
-	lines := wrapWords(m.words, containerWidth)
+	// Only advance if the user has typed something for this word.
+	// Prevents accidental double-space from skipping words.
+	if len(m.input[m.wordIndex]) > 0 && m.wordIndex < len(m.words)-1 {
+		m.wordIndex++
+		m.charIndex = 0
+		m = recordKeystroke(m)
+		m = logKey(m, keySpace)
+		m = recordSprint(m)
+		m = extendZenWords(m)
+	} else if len(m.input[m.wordIndex]) > 0 {
+		// Space on the final word ends the test, even a timed one
+		return finishTyping(m)
+	}
+	return m, nil
+}
 
-	// Find which line the current word is on
-	currentLine := 0
-	for i, line := range lines {
-		for _, wIdx := range line {
-			if wIdx == m.wordIndex {
-				currentLine = i
-			}
+// typeChar types a character into the current word.
+func typeChar(m model, char rune) (model, tea.Cmd) {
+	targetLen := len([]rune(m.words[m.wordIndex]))
+	var soundCmd tea.Cmd
+	if m.charIndex < targetLen+maxWordOverflow {
+		m.input[m.wordIndex] = append(m.input[m.wordIndex], char)
+		m.charIndex++
+		m = recordKeystroke(m)
+		m = logKey(m, string(char))
+		correct := m.charIndex <= targetLen && []rune(m.words[m.wordIndex])[m.charIndex-1] == char
+		if !correct && m.charIndex <= targetLen {
+			m.keyMisses.add([]rune(m.words[m.wordIndex])[m.charIndex-1], char)
+			m = noteMissedKey(m, []rune(m.words[m.wordIndex])[m.charIndex-1])
+		}
+		if m.difficulty == difficultyMaster && !correct {
+			return failTyping(m)
 		}
+		soundCmd = keySoundCmd(m, correct)
+	}
+	if lastWordComplete(m) {
+		var finishCmd tea.Cmd
+		m, finishCmd = finishTyping(m)
+		return m, tea.Batch(soundCmd, finishCmd)
 	}
+	return m, soundCmd
+}
 
-	// Show 3 lines: one above current, current, one below
-	startLine := currentLine - 1
-	if startLine < 0 {
-		startLine = 0
+// clearWord deletes everything typed in the current word (Ctrl+W, Ctrl+U,
+// or Alt+Backspace). It never steps back into the previous word.
+func clearWord(m model) model {
+	if m.charIndex == 0 {
+		return m
 	}
-	endLine := startLine + 3
-	if endLine > len(lines) {
-		endLine = len(lines)
+	m.charIndex = 0
+	m.input[m.wordIndex] = m.input[m.wordIndex][:0]
+	return logKey(m, keyClearWord)
+}
+
+// keySoundCmd plays the keypress sound for a typed character, if keypress
+// sounds are turned on: a click when it's right, a low thud when it's wrong.
+func keySoundCmd(m model, correct bool) tea.Cmd {
+	if !m.keySounds {
+		return nil
+	}
+	if correct {
+		return playKeySound(soundClick, 1)
 	}
+	return playKeySound(soundHit, 0.6)
+}
 
-	var renderedLines []string
-	for _, line := range lines[startLine:endLine] {
-		var lineStr strings.Builder
-		for j, wIdx := range line {
-			if j > 0 {
-				lineStr.WriteString(styleUntyped.Render(" "))
-			}
-			lineStr.WriteString(renderWord(m, wIdx))
+// typingClockCmd returns the command that starts (run=true) or stops the
+// clock driving the current test, if it has one.
+func typingClockCmd(m *model, run bool) tea.Cmd {
+	switch {
+	case usesStopwatch(*m):
+		if run {
+			return m.stopwatch.Start()
 		}
-		renderedLines = append(renderedLines, lineStr.String())
+		return m.stopwatch.Stop()
+	case m.testMode == testModeTime:
+		if run {
+			return m.timer.Start()
+		}
+		return m.timer.Stop()
+	}
+	return nil
+}
+
+// usesStopwatch reports whether the current test's clock counts up: quote
+// and zen tests, which have no time limit to count down from.
+func usesStopwatch(m model) bool {
+	return m.contentMode == modeQuotes || zenTest(m)
+}
+
+// typingElapsed is the active typing time so far, excluding pauses.
+func typingElapsed(m model) time.Duration {
+	if m.paused {
+		return m.pausedAt.Sub(m.startTime)
+	}
+	return time.Since(m.startTime)
+}
+
+func pauseTyping(m model) (model, tea.Cmd) {
+	m.paused = true
+	m.pausedAt = time.Now()
+	return m, typingClockCmd(&m, false)
+}
+
+func resumeTyping(m model) (model, tea.Cmd) {
+	m.paused = false
+	m.blurPaused = false
+	m.confirmingQuit = false
+	m.startTime = m.startTime.Add(time.Since(m.pausedAt))
+	return m, typingClockCmd(&m, true)
+}
+
+// recordKeystroke counts a typed character in the bucket for the current
+// elapsed second.
+func recordKeystroke(m model) model {
+	sec := int(time.Since(m.startTime).Seconds())
+	for len(m.keystrokes) <= sec {
+		m.keystrokes = append(m.keystrokes, 0)
 	}
+	m.keystrokes[sec]++
+	return m
+}
 
-	textBlock := strings.Join(renderedLines, "\n")
+// lastWordComplete reports whether the final word has been typed exactly.
+func lastWordComplete(m model) bool {
+	last := len(m.words) - 1
+	return m.wordIndex == last && string(m.input[last]) == m.words[last]
+}
 
-	// Status bar: timer on the left, live WPM on the right
+// failTyping ends a test as failed: a mistake in master difficulty, or
+// dropping below a fail floor.
+func failTyping(m model) (model, tea.Cmd) {
+	m.testFailed = true
+	return finishTyping(m)
+}
+
+// finishTyping ends the current test, switches to the results screen, and
+// returns the command that records the result to history (and a lesson's
+// progress and any achievements, and sends it to the webhook).
+func finishTyping(m model) (model, tea.Cmd) {
+	m = calculateResults(m)
+	m = finishRace(m)
+	if botActive(m) {
+		m.botRace = botResult(m, m.finalTime)
+	}
+	m.state = stateResults
+	m.spectator.send(spectateResults(m))
+	e := classicHistoryEntry(m)
+	m, saveCmd := recordResult(m, e)
+	m, lessonCmd := checkLesson(m)
+	m, achievementsCmd := checkAchievements(m, e)
+	m, webhookCmd := postResult(m)
+	return m, tea.Batch(saveCmd, lessonCmd, achievementsCmd, webhookCmd)
+}
+
+func viewTyping(m model) string {
+	textBlock := renderTypingText(m)
+
+	if m.countdownLeft > 0 {
+		hint := m.keys[actionRestart] + " restart countdown  " + m.keys[actionPause] + " menu"
+		if m.race != nil {
+			hint = m.keys[actionPause] + " leave the race"
+		}
+		return lipgloss.JoinVertical(lipgloss.Left,
+			styleBigWPM.Render(fmt.Sprintf("%d", m.countdownLeft)),
+			"",
+			textBlock,
+			"",
+			styleHint.Render(hint),
+		)
+	}
+
+	// Status bar: timer (or word progress) on the left, live WPM on the right
 	var timerText string
-	if !m.timerStarted {
+	if usesStopwatch(m) {
+		timerText = styleTimer.Render(fmt.Sprintf("%ds", int(m.stopwatch.Elapsed().Seconds())))
+	} else if m.testMode == testModeWords {
+		timerText = styleTimer.Render(fmt.Sprintf("%d/%d", m.wordIndex, len(m.words)))
+	} else if !m.timerStarted {
 		timerText = styleTimer.Render(fmt.Sprintf("%d", int(m.duration.Seconds())))
 	} else {
 		remaining := m.timer.Timeout.Seconds()
@@ -168,29 +514,130 @@ func viewTyping(m model) string {
 	} else {
 		statusBar = timerText
 	}
+	if m.contentMode == modeText && m.textTruncated {
+		statusBar += "    " + styleHint.Render(fmt.Sprintf("text cut to %d words", maxTextWords))
+	}
+	if emulatingLayout(m) {
+		statusBar += "    " + styleHighlight.Render(keyboardLayouts[m.layout].name)
+	}
+	if m.muted {
+		statusBar += "    " + styleHint.Render("muted")
+	}
+	if m.sprints && m.timerStarted {
+		statusBar += "    " + renderSprintStatus(m)
+	}
+	if m.practice {
+		statusBar += "    " + renderPracticeNote(m)
+	}
+	if ghostActive(m) {
+		statusBar += "    " + styleHint.Render(fmt.Sprintf("ghost %.0f wpm", m.ghostWPM))
+	}
 
-	hint := styleHint.Render("tab restart  esc menu")
+	if m.paused {
+		// Hide the text while paused so the pause can't be used to read ahead
+		pauseHint := "paused " + glyphDash + " " + m.keys[actionPause] + " to resume, " + m.keys[actionQuit] + " to quit to menu"
+		if zenTest(m) {
+			pauseHint = "paused " + glyphDash + " " + m.keys[actionPause] + " to resume, " + m.keys[actionFinish] + " to finish, " + m.keys[actionQuit] + " to quit to menu"
+		}
+		if m.confirmingQuit {
+			pauseHint = confirmQuitPrompt
+		}
+		return lipgloss.JoinVertical(lipgloss.Left,
+			statusBar,
+			"",
+			styleHint.Render(pauseHint),
+			styleHint.Render(m.keys[actionHelp]+" keys"),
+		)
+	}
 
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		statusBar,
+	hint := styleHint.Render(m.keys[actionRestart] + " restart  " + m.keys[actionPause] + " pause")
+	if zenTest(m) {
+		hint = styleHint.Render(m.keys[actionFinish] + " finish  " + m.keys[actionRestart] + " restart  " + m.keys[actionPause] + " pause")
+	}
+	switch {
+	case racing(m):
+		hint = styleHint.Render(m.keys[actionPause] + " leave the race")
+	case m.race != nil:
+		hint = styleHint.Render(m.keys[actionPause] + " pause")
+	}
+
+	// A race or a bot puts the opponent's progress under the status bar
+	top := []string{statusBar}
+	if m.race != nil {
+		top = append(top, renderRaceBar(m))
+	}
+	if botActive(m) {
+		top = append(top, renderBotBar(m))
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left, append(top,
 		"",
 		textBlock,
 		"",
 		hint,
-	)
+	)...)
+	if m.keyboard && keyboardFits(m, content) {
+		content = lipgloss.JoinVertical(lipgloss.Center, content, "", renderKeyboard(m))
+	}
 
 	return content
 }
 
-// liveWPM calculates the current WPM based on correct characters typed so far.
+// renderTypingText renders the lines of words around the current one,
+// styled by what has been typed into m.input.
+func renderTypingText(m model) string {
+	lines := wrapWords(m.words, textWrapWidth(m))
+
+	// Find which line the current word is on
+	currentLine := 0
+	for i, line := range lines {
+		for _, wIdx := range line {
+			if wIdx == m.wordIndex {
+				currentLine = i
+			}
+		}
+	}
+
+	// Show 3 lines: one above current, current, one below
+	startLine := currentLine - 1
+	if startLine < 0 {
+		startLine = 0
+	}
+	endLine := startLine + 3
+	if endLine > len(lines) {
+		endLine = len(lines)
+	}
+
+	var renderedLines []string
+	for _, line := range lines[startLine:endLine] {
+		var lineStr strings.Builder
+		for j, wIdx := range line {
+			if j > 0 {
+				space := styleUntyped
+				if prev := line[j-1]; paceCaretAt(m, prev, len([]rune(m.words[prev]))) {
+					space = stylePace
+				}
+				lineStr.WriteString(space.Render(" "))
+			}
+			lineStr.WriteString(renderWord(m, wIdx))
+		}
+		renderedLines = append(renderedLines, lineStr.String())
+	}
+
+	return strings.Join(renderedLines, "\n")
+}
+
+// liveWPM calculates the current WPM based on correct characters typed so
+// far, including the word in progress so the number doesn't sag through a
+// long word and jump on space. The view re-renders on every keypress, so
+// it never lags behind the timer's once-a-second tick.
 func liveWPM(m model) float64 {
-	elapsed := time.Since(m.startTime).Seconds()
+	elapsed := typingElapsed(m).Seconds()
 	if elapsed < 1 {
 		return 0
 	}
 
 	correctChars := 0
-	for i := 0; i < m.wordIndex; i++ {
+	for i := 0; i <= m.wordIndex && i < len(m.words); i++ {
 		typed := m.input[i]
 		target := []rune(m.words[i])
 		for j := 0; j < len(target) && j < len(typed); j++ {
@@ -198,35 +645,79 @@ func liveWPM(m model) float64 {
 				correctChars++
 			}
 		}
-		correctChars++ // space between words
+		if i < m.wordIndex {
+			correctChars++ // space between words
+		}
 	}
 
 	minutes := elapsed / 60.0
 	return (float64(correctChars) / 5.0) / minutes
 }
 
+// textWrapWidth is the width the typing text wraps at.
+func textWrapWidth(m model) int {
+	// Adapt to terminal width — cap at 70, shrink for narrow terminals
+	containerWidth := 70
+	if m.width > 0 && m.width-10 < containerWidth {
+		containerWidth = m.width - 10
+		if containerWidth < 30 {
+			containerWidth = 30
+		}
+	}
+
+	// The bar caret takes up a column of its own, so leave room for it
+	if m.caret == caretBar {
+		return containerWidth - 1
+	}
+	return containerWidth
+}
+
 // renderWord renders a single word with character-by-character styling.
+// In blind mode, mistakes are drawn like correct characters until the
+// results screen.
 func renderWord(m model, wordIdx int) string {
 	target := []rune(m.words[wordIdx])
 	typed := m.input[wordIdx]
 	var result strings.Builder
 
+	wrong := styleMistake
+	if m.blind && m.state == stateTyping {
+		wrong = styleCorrect
+	}
+
+	if wordIdx == m.wordIndex && m.spaceRejected {
+		// Expert mode refused a space: flash the whole word as an error
+		result.WriteString(styleMistake.Render(string(target)))
+		if len(typed) > len(target) {
+			result.WriteString(styleMistake.Render(string(typed[len(target):])))
+		}
+		return result.String()
+	}
+
 	for i, targetChar := range target {
-		if wordIdx < m.wordIndex {
+		if paceCaretAt(m, wordIdx, i) {
+			result.WriteString(stylePace.Render(string(targetChar)))
+		} else if ghostCaretAt(m, wordIdx, i) {
+			result.WriteString(styleGhost.Render(string(targetChar)))
+		} else if raceCaretAt(m, wordIdx, i) {
+			result.WriteString(styleRival.Render(string(targetChar)))
+		} else if botCaretAt(m, wordIdx, i) {
+			result.WriteString(styleBot.Render(string(targetChar)))
+		} else if wordIdx < m.wordIndex {
 			if i < len(typed) && typed[i] == targetChar {
 				result.WriteString(styleCorrect.Render(string(targetChar)))
 			} else {
-				result.WriteString(styleIncorrect.Render(string(targetChar)))
+				result.WriteString(wrong.Render(string(targetChar)))
 			}
 		} else if wordIdx == m.wordIndex {
 			if i < len(typed) {
 				if typed[i] == targetChar {
 					result.WriteString(styleCorrect.Render(string(targetChar)))
 				} else {
-					result.WriteString(styleIncorrect.Render(string(targetChar)))
+					result.WriteString(wrong.Render(string(targetChar)))
 				}
 			} else if i == len(typed) {
-				result.WriteString(styleCursor.Render(string(targetChar)))
+				result.WriteString(renderCaret(m.caret, string(targetChar)))
 			} else {
 				result.WriteString(styleUntyped.Render(string(targetChar)))
 			}
@@ -238,13 +729,26 @@ func renderWord(m model, wordIdx int) string {
 	// Overflow characters (typed more than the word length)
 	if wordIdx <= m.wordIndex && len(typed) > len(target) {
 		for i := len(target); i < len(typed); i++ {
-			result.WriteString(styleIncorrect.Render(string(typed[i])))
+			result.WriteString(wrong.Render(string(typed[i])))
 		}
 	}
 
 	return result.String()
 }
 
+// renderCaret renders the next character to type with the chosen caret.
+func renderCaret(caret caretStyle, ch string) string {
+	switch caret {
+	case caretUnderline:
+		return styleCaretUnderline.Render(ch)
+	case caretBar:
+		return styleCaretBar.Render("|") + styleUntyped.Render(ch)
+	case caretOff:
+		return styleUntyped.Render(ch)
+	}
+	return styleCursor.Render(ch)
+}
+
 // wrapWords groups word indices into lines that fit within maxWidth.
 func wrapWords(words []string, maxWidth int) [][]int {
 	var lines [][]int
@@ -252,7 +756,7 @@ func wrapWords(words []string, maxWidth int) [][]int {
 	lineWidth := 0
 
 	for i, word := range words {
-		wordWidth := len([]rune(word))
+		wordWidth := lipgloss.Width(word)
 		spaceNeeded := wordWidth
 		if len(currentLine) > 0 {
 			spaceNeeded++
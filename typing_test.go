@@ -0,0 +1,230 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/timer"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newTestModel is a fresh model that leaves the user's files alone: its
+// config, history, and other data live in a temporary directory.
+func newTestModel(t testing.TB) model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_DATA_HOME", dir)
+	return initialModel()
+}
+
+// newTimeTest is a 15-second classic time test, not yet started, on the
+// given words or, without any, generated ones.
+func newTimeTest(t *testing.T, words ...string) model {
+	t.Helper()
+	m := newTestModel(t)
+	m.gameMode = gameModeClassic
+	m.contentMode = modeWords
+	m.testMode = testModeTime
+	m.duration = 15 * time.Second
+	if len(words) == 0 {
+		return initTypingState(m)
+	}
+	return startTypingTest(m, words)
+}
+
+// typeKey sends msg to a classic test.
+func typeKey(t *testing.T, m model, msg tea.Msg) model {
+	t.Helper()
+	next, _ := updateTyping(m, msg)
+	return next.(model)
+}
+
+func runesKey(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// typeText types s into a classic test a key at a time.
+func typeText(t *testing.T, m model, s string) model {
+	t.Helper()
+	for _, r := range s {
+		if r == ' ' {
+			m = typeKey(t, m, tea.KeyMsg{Type: tea.KeySpace})
+		} else {
+			m = typeKey(t, m, runesKey(string(r)))
+		}
+	}
+	return m
+}
+
+func TestStaleTimerMessagesAfterRestart(t *testing.T) {
+	m := newTimeTest(t)
+	var stale []int
+	for i := 0; i < 5; i++ {
+		m = typeKey(t, m, runesKey("x")) // starts the clock
+		stale = append(stale, m.timer.ID())
+		m = typeKey(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+	m = typeKey(t, m, runesKey("x"))
+	timeout := m.timer.Timeout
+
+	for _, id := range stale {
+		if id == m.timer.ID() {
+			t.Fatalf("restart kept timer ID %d", id)
+		}
+		m = typeKey(t, m, timer.TickMsg{ID: id})
+		if m.timer.Timeout != timeout {
+			t.Fatalf("stale tick from timer %d took the timeout from %v to %v", id, timeout, m.timer.Timeout)
+		}
+		m = typeKey(t, m, timer.TimeoutMsg{ID: id})
+		if m.state != stateTyping {
+			t.Fatalf("stale timeout from timer %d ended the test", id)
+		}
+	}
+
+	m = typeKey(t, m, timer.TimeoutMsg{ID: m.timer.ID()})
+	if m.state != stateResults {
+		t.Errorf("the current timer's timeout didn't end the test")
+	}
+}
+
+// newWordsTest is a classic words test on exactly the given words.
+func newWordsTest(t *testing.T, words ...string) model {
+	t.Helper()
+	m := newTestModel(t)
+	m.gameMode = gameModeClassic
+	m.testMode = testModeWords
+	return startTypingTest(m, words)
+}
+
+func TestMultiRuneKeyTypesEveryRune(t *testing.T) {
+	m := newWordsTest(t, "alpha", "beta", "gamma")
+	m = typeKey(t, m, runesKey("alp"))
+	if got := string(m.input[0]); got != "alp" || m.charIndex != 3 {
+		t.Fatalf("after \"alp\": input %q at char %d, want \"alp\" at 3", got, m.charIndex)
+	}
+	var logged string
+	for _, k := range m.keyLog {
+		logged += k.Key
+	}
+	if logged != "alp" {
+		t.Errorf("key log %q, want the runes in order", logged)
+	}
+
+	m = typeKey(t, m, runesKey("ha be"))
+	if m.wordIndex != 1 || string(m.input[0]) != "alpha" || string(m.input[1]) != "be" {
+		t.Errorf("after \"ha be\": word %d, inputs %q %q; want word 1, \"alpha\" \"be\"",
+			m.wordIndex, string(m.input[0]), string(m.input[1]))
+	}
+}
+
+func TestMultiRuneKeyFinishesTest(t *testing.T) {
+	m := newWordsTest(t, "alpha", "beta")
+	m = typeKey(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("alpha beta"), Paste: true})
+	if m.state != stateResults || m.correctWords != 2 {
+		t.Errorf("pasting the whole text: state %v with %d correct words, want results with 2", m.state, m.correctWords)
+	}
+}
+
+func TestIgnoredPaste(t *testing.T) {
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("alpha"), Paste: true}
+	batch := runesKey("alphabetagam") // more than pasteBatchRunes, unbracketed
+	if !pastedKey(paste) || !pastedKey(batch) || pastedKey(runesKey("alp")) {
+		t.Fatalf("pastedKey: paste %v, long batch %v, 3 runes %v; want true, true, false",
+			pastedKey(paste), pastedKey(batch), pastedKey(runesKey("alp")))
+	}
+
+	m := newWordsTest(t, "alphabetagam", "beta")
+	m.ignorePaste = true
+	for _, msg := range []tea.KeyMsg{paste, batch} {
+		m = typeKey(t, m, msg)
+		if len(m.input[0]) != 0 || m.timerStarted {
+			t.Fatalf("ignored paste %q was typed (%q) or started the clock", string(msg.Runes), string(m.input[0]))
+		}
+	}
+	m = typeKey(t, m, runesKey("alp"))
+	if got := string(m.input[0]); got != "alp" {
+		t.Errorf("a 3-rune batch with pastes ignored typed %q, want \"alp\"", got)
+	}
+
+	m = newWordsTest(t, "alpha", "beta")
+	m = typeKey(t, m, paste)
+	if got := string(m.input[0]); got != "alpha" {
+		t.Errorf("with pastes typed, a paste typed %q, want \"alpha\"", got)
+	}
+}
+
+var clearWordKeys = []tea.KeyMsg{
+	{Type: tea.KeyCtrlW},
+	{Type: tea.KeyCtrlU},
+	{Type: tea.KeyBackspace, Alt: true},
+}
+
+func TestClearEmptyWord(t *testing.T) {
+	for _, key := range clearWordKeys {
+		// A mistyped first word, so freedom would even allow stepping back
+		m := newWordsTest(t, "alpha", "beta")
+		m.freedom = true
+		m = typeText(t, m, "alpx ")
+		logged := len(m.keyLog)
+		m = typeKey(t, m, key)
+		if m.wordIndex != 1 || m.charIndex != 0 || len(m.input[1]) != 0 || string(m.input[0]) != "alpx" {
+			t.Errorf("%s on an empty word: word %d char %d, inputs %q %q; want nothing changed",
+				key, m.wordIndex, m.charIndex, string(m.input[0]), string(m.input[1]))
+		}
+		if len(m.keyLog) != logged {
+			t.Errorf("%s on an empty word was logged", key)
+		}
+	}
+}
+
+func TestClearWordWithOverflow(t *testing.T) {
+	for _, key := range clearWordKeys {
+		m := newWordsTest(t, "alpha", "beta")
+		m = typeText(t, m, "alphaxyz")
+		if m.charIndex != 8 {
+			t.Fatalf("overflow wasn't typed: char %d, want 8", m.charIndex)
+		}
+		m = typeKey(t, m, key)
+		if m.wordIndex != 0 || m.charIndex != 0 || len(m.input[0]) != 0 {
+			t.Errorf("%s: word %d char %d input %q; want the whole word cleared", key, m.wordIndex, m.charIndex, string(m.input[0]))
+		}
+		if last := m.keyLog[len(m.keyLog)-1].Key; last != keyClearWord {
+			t.Errorf("%s logged %q, want %q", key, last, keyClearWord)
+		}
+	}
+}
+
+func TestWrapWordsByDisplayWidth(t *testing.T) {
+	tests := []struct {
+		words []string
+		width int
+		want  [][]int
+	}{
+		// 6 + 1 + 6 columns don't fit in 12, though the runes would
+		{[]string{"日本語", "日本語"}, 12, [][]int{{0}, {1}}},
+		{[]string{"日本語", "日本語"}, 13, [][]int{{0, 1}}},
+		// Accented words are as wide as their runes, not their bytes
+		{[]string{"café", "naïve", "résumé"}, 10, [][]int{{0, 1}, {2}}},
+		{[]string{"café", "naïve", "résumé"}, 17, [][]int{{0, 1, 2}}},
+	}
+	for _, tt := range tests {
+		got := wrapWords(tt.words, tt.width)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapWords(%q, %d) = %v, want %v", tt.words, tt.width, got, tt.want)
+		}
+		for _, line := range got {
+			var words []string
+			for _, i := range line {
+				words = append(words, tt.words[i])
+			}
+			if w := lipgloss.Width(strings.Join(words, " ")); w > tt.width && len(line) > 1 {
+				t.Errorf("wrapWords(%q, %d): line %q is %d wide", tt.words, tt.width, words, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+// Word variety (settings screen): how a classic words test picks from its
+// word set (see wordsets.go), which is roughly in order of how common the
+// words are.
+//
+//   natural — common words come up more often, the way they do in real
+//             text ("the" over 20 times as often as the last of the 200)
+//   varied  — every word is as likely, and none comes back within
+//             varietyWindow words
+//
+// Either way a word never follows itself. Custom word lists aren't in any
+// particular order, so they're always picked from evenly.
+
+type wordVariety int
+
+const (
+	varietyNatural wordVariety = iota
+	varietyVaried
+)
+
+var wordVarietyNames = []string{"natural", "varied"}
+
+const (
+	varietyWindow = 5 // words before a word can come back, when varied
+
+	// Weights of a word set's words by position: varietyScale/(position+varietyRankOffset)
+	varietyScale      = 100000
+	varietyRankOffset = 10
+)
+
+// rankWeights weighs each of n words in a set by how far up the list it is.
+func rankWeights(n int) []int {
+	weights := make([]int, n)
+	for i := range weights {
+		weights[i] = varietyScale / (i + varietyRankOffset)
+	}
+	return weights
+}
+
+// varietyWords draws count words from pool the way the variety setting
+// says, for a words test.
+func varietyWords(m model, pool []string, count int) []string {
+	if m.wordVariety == varietyVaried {
+		return pickWords(m.rng, pool, nil, varietyWindow, count)
+	}
+	if m.contentMode == modeWords {
+		return pickWords(m.rng, pool, wordSets[m.wordSet].weights, 1, count)
+	}
+	return generateWords(m.rng, pool, count)
+}
@@ -0,0 +1,156 @@
+package main
+
+// Weak-words practice, started from the menu with "w": a weakTestWords-word
+// test of the words you've missed most in your last weakHistoryResults
+// classic results, mixed in with common words so it isn't the same few
+// words over and over.
+//
+// Results don't store their missed words, so they're worked out again from
+// the words reached and the keystroke log, by replaying the log's edits.
+// Every miss counts, so a word missed often comes up often. Numbers, code,
+// and lesson drills aren't words worth practicing and are left out.
+//
+// Until history has weakMinWords different missed words there's too little
+// to practice, and the test is plain common words, with a note saying so.
+// Like lessons, practice has a fixed setup that replaces the menu's choices
+// until it returns to the menu.
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	weakHistoryResults = 50 // most recent classic results to look through
+	weakMinWords       = 10 // different missed words needed to practice them
+	weakTestWords      = 50
+	weakPercent        = 60 // share of the test's words that are weak ones
+)
+
+// weakWord is a missed word and how many times it was missed.
+type weakWord struct {
+	word   string
+	misses int
+}
+
+// entryMissedWords rebuilds what was typed for each word of a saved result
+// and returns the words typed wrong, once per miss. As on the results
+// screen, a last word cut off part way isn't a miss.
+func entryMissedWords(e historyEntry) []string {
+	r := model{words: e.Words, replayInput: make([][]rune, len(e.Words))}
+	for _, k := range e.Keys {
+		r = applyReplayKey(r, k)
+	}
+	var missed []string
+	for i, word := range e.Words {
+		typed := r.replayInput[i]
+		if string(typed) == word {
+			continue
+		}
+		if i == len(e.Words)-1 && len(typed) < len([]rune(word)) {
+			continue
+		}
+		missed = append(missed, word)
+	}
+	return missed
+}
+
+// weakWords counts the misses in the most recent classic results, most
+// missed first.
+func weakWords(history []historyEntry) []weakWord {
+	counts := map[string]int{}
+	seen := 0
+	for i := len(history) - 1; i >= 0 && seen < weakHistoryResults; i-- {
+		e := history[i]
+		if e.Mode != "classic" {
+			continue
+		}
+		seen++
+		if e.Content == "numbers" || e.Content == "code" || e.Lesson != "" {
+			continue
+		}
+		for _, w := range entryMissedWords(e) {
+			counts[w]++
+		}
+	}
+
+	weak := make([]weakWord, 0, len(counts))
+	for w, n := range counts {
+		weak = append(weak, weakWord{word: w, misses: n})
+	}
+	sort.Slice(weak, func(i, j int) bool {
+		if weak[i].misses != weak[j].misses {
+			return weak[i].misses > weak[j].misses
+		}
+		return weak[i].word < weak[j].word
+	})
+	return weak
+}
+
+// startPractice sets up weak-words practice and starts its test.
+func startPractice(m model) model {
+	m = saveMenuChoices(m)
+	m.contentMode = modeWords
+	m.testMode = testModeWords
+	m.wordCount = weakTestWords
+	m.punctuation = false
+	m.difficulty = difficultyNormal
+	m.wordSet = 0
+	m.practice = true
+	return startPracticeTest(m)
+}
+
+// startPracticeTest starts a fresh practice test on the latest history,
+// so the test just finished counts too.
+func startPracticeTest(m model) model {
+	m = reseed(m)
+	weak := weakWords(m.history)
+	m.practiceFallback = len(weak) < weakMinWords
+
+	words := generateWords(m.rng, commonWords, weakTestWords)
+	if !m.practiceFallback {
+		pool := make([]string, len(weak))
+		weights := make([]int, len(weak))
+		for i, w := range weak {
+			pool[i], weights[i] = w.word, w.misses
+		}
+		practiced := generateWeightedWords(m.rng, pool, weights, weakTestWords)
+		for i := range words {
+			if m.rng.Intn(100) < weakPercent {
+				words[i] = practiced[i]
+			}
+		}
+	}
+	m = startTypingTest(m, words)
+	// The words depend on history as well as the seed
+	m.seedValid = false
+	return m
+}
+
+// endPractice leaves practice. The caller restores the menu choices.
+func endPractice(m model) model {
+	m.practice = false
+	m.practiceFallback = false
+	return m
+}
+
+// renderPracticeNote says what a practice test is made of, on the typing
+// and results screens.
+func renderPracticeNote(m model) string {
+	if m.practiceFallback {
+		return styleHint.Render("too few missed words yet " + glyphDash + " common words only")
+	}
+	return styleHighlight.Render("weak words practice")
+}
+
+// renderPracticeStatus is the menu line saying how many weak words there
+// are to practice.
+func renderPracticeStatus(m model) string {
+	label := styleStatLabel.Render("practice  ")
+	n := len(weakWords(m.history))
+	if n < weakMinWords {
+		return label + styleHint.Render(fmt.Sprintf("%d/%d missed words so far %s press w", n, weakMinWords, glyphDash))
+	}
+	return label + styleStatValue.Render(fmt.Sprintf("%d weak words", n)) +
+		styleHint.Render(" "+glyphDash+" press w")
+}
@@ -0,0 +1,130 @@
+package main
+
+// The results webhook. With results_webhook_url set in config.json (by
+// hand; there's no settings row for it), every finished test or falling
+// game is POSTed there as JSON, the same document "e" exports (see
+// export.go), minus the webhook URL itself, which may hold a token.
+//
+// The request runs in the background with a short timeout, and the hint
+// line on the results or game over screen says how it went: "syncing…",
+// then "synced ✓" or "sync failed". Nothing is retried or queued, and a
+// failure changes nothing else.
+//
+// With --webhook-dry-run nothing is sent: each payload is kept and printed
+// to stdout on exit, whether or not a URL is set.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const webhookTimeout = 5 * time.Second
+
+// webhookStatus is how the latest result's sync is going.
+type webhookStatus int
+
+const (
+	webhookNone webhookStatus = iota // no webhook, or nothing sent yet
+	webhookSending
+	webhookSynced
+	webhookFailed
+	webhookDryRun
+)
+
+// webhookSentMsg reports how posting the result recorded at at went.
+type webhookSentMsg struct {
+	at  time.Time
+	err error
+}
+
+// validWebhookURL reports whether raw is an absolute http or https URL.
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// postResult sends the just-recorded result to the webhook, or keeps it
+// for printing in a dry run.
+func postResult(m model) (model, tea.Cmd) {
+	m.webhookStatus = webhookNone
+	if m.lastResult == nil || (!m.webhookDryRun && !validWebhookURL(m.webhookURL)) {
+		return m, nil
+	}
+	payload, err := json.MarshalIndent(exportResult(m), "", "  ")
+	if err != nil {
+		return m, nil
+	}
+	if m.webhookDryRun {
+		m.webhookPayloads = append(m.webhookPayloads, payload)
+		m.webhookStatus = webhookDryRun
+		return m, nil
+	}
+	m.webhookStatus = webhookSending
+	return m, postResultCmd(m.webhookURL, payload, m.lastResult.Time)
+}
+
+func postResultCmd(target string, payload []byte, at time.Time) tea.Cmd {
+	return func() tea.Msg {
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+		if err != nil {
+			return webhookSentMsg{at: at, err: err}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		client := http.Client{Timeout: webhookTimeout}
+		resp, err := client.Do(req)
+		if err != nil {
+			return webhookSentMsg{at: at, err: err}
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			err = fmt.Errorf("webhook answered %s", resp.Status)
+		}
+		return webhookSentMsg{at: at, err: err}
+	}
+}
+
+// handleWebhookSent updates the sync indicator, unless a newer result has
+// been recorded since.
+func handleWebhookSent(m model, msg webhookSentMsg) model {
+	if m.lastResult == nil || !m.lastResult.Time.Equal(msg.at) || m.webhookStatus != webhookSending {
+		return m
+	}
+	m.webhookStatus = webhookSynced
+	if msg.err != nil {
+		m.webhookStatus = webhookFailed
+	}
+	return m
+}
+
+// renderWebhookStatus is the sync indicator for the hint line, "" with
+// nothing to show.
+func renderWebhookStatus(m model) string {
+	switch m.webhookStatus {
+	case webhookSending:
+		return styleHint.Render("syncing" + glyphEllipsis)
+	case webhookSynced:
+		return styleCorrect.Render("synced " + glyphCheck)
+	case webhookFailed:
+		return styleIncorrect.Render("sync failed")
+	case webhookDryRun:
+		return styleHint.Render("dry run")
+	}
+	return ""
+}
+
+// printWebhookPayloads writes a dry run's payloads to stdout, one JSON
+// document after another.
+func printWebhookPayloads(payloads [][]byte) {
+	for _, p := range payloads {
+		os.Stdout.Write(append(p, '\n'))
+	}
+}
@@ -2,10 +2,19 @@ package main
 
 // Word lists and quotes are embedded directly in the source code.
 // No external files needed — the binary is fully self-contained.
+//
+// Everything random here draws from the *rand.Rand passed in (the model's
+// per-test generator), so a test can be replayed from its seed.
 
 import (
+	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"slices"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // Common English words (similar to monkeytype's "english" word set).
@@ -73,23 +82,355 @@ var quotes = []string{
 	"Life is a succession of lessons which must be lived to be understood",
 }
 
-// generateWords returns a slice of random words from the common word list.
+// generateWords returns a slice of random words drawn from pool, no word
+// straight after itself.
 // For a 60-second test we generate ~200 words (enough for even fast typists).
-func generateWords(count int) []string {
+func generateWords(rng *rand.Rand, pool []string, count int) []string {
+	return pickWords(rng, pool, nil, 1, count)
+}
+
+// generateWeightedWords returns count words drawn from words, each picked
+// in proportion to its weight, no word straight after itself. Every weight
+// must be more than 0.
+func generateWeightedWords(rng *rand.Rand, words []string, weights []int, count int) []string {
+	return pickWords(rng, words, weights, 1, count)
+}
+
+// pickWords returns count words drawn from words, each picked in
+// proportion to its weight (or evenly, with nil weights), and none that
+// was among the last `recent` picked. With too few different words for
+// that, it's as few back as there are words to go round.
+func pickWords(rng *rand.Rand, words []string, weights []int, recent, count int) []string {
+	distinct := map[string]bool{}
+	for _, w := range words {
+		distinct[w] = true
+	}
+	recent = max(min(recent, len(distinct)-1), 0)
+
+	total := len(words)
+	if weights != nil {
+		total = 0
+		for _, w := range weights {
+			total += w
+		}
+	}
+	pick := func() string {
+		if weights == nil {
+			return words[rng.Intn(total)]
+		}
+		n := rng.Intn(total)
+		for j, w := range weights {
+			if n < w {
+				return words[j]
+			}
+			n -= w
+		}
+		return words[len(words)-1]
+	}
+
+	picked := make([]string, count)
+	for i := range picked {
+		// There's always a word that wasn't picked recently, so this ends
+		word := pick()
+		for slices.Contains(picked[max(i-recent, 0):i], word) {
+			word = pick()
+		}
+		picked[i] = word
+	}
+	return picked
+}
+
+// Lengths of the made-up words in drills, in keys.
+const (
+	drillMinLen = 2
+	drillMaxLen = 5
+
+	drillMinRealWords = 5 // real words needed to mix them into a drill
+)
+
+// generateDrillWords returns count words typed with only the given keys,
+// for lessons. Common words made only of those keys make up half of them
+// when there are enough to choose from; the rest are made up.
+func generateDrillWords(rng *rand.Rand, keys string, count int) []string {
+	var real []string
+	for _, w := range commonWords {
+		if strings.IndexFunc(w, func(r rune) bool { return !strings.ContainsRune(keys, r) }) < 0 {
+			real = append(real, w)
+		}
+	}
+	allowed := []rune(keys)
 	words := make([]string, count)
 	for i := range words {
-		words[i] = commonWords[rand.Intn(len(commonWords))]
+		if len(real) >= drillMinRealWords && rng.Intn(2) == 0 {
+			words[i] = real[rng.Intn(len(real))]
+			continue
+		}
+		word := make([]rune, drillMinLen+rng.Intn(drillMaxLen-drillMinLen+1))
+		for j := range word {
+			word[j] = allowed[rng.Intn(len(allowed))]
+		}
+		words[i] = string(word)
 	}
 	return words
 }
 
-// getQuoteWords picks random quotes and splits them into words,
+// wordPool returns the list that random words are drawn from for the
+// current content mode.
+func wordPool(m model) []string {
+	if m.contentMode == modeCustom && len(m.customWords) > 0 {
+		return m.customWords
+	}
+	if m.contentMode == modeText && len(m.textWords) > 0 {
+		return m.textWords
+	}
+	if m.contentMode == modeWords {
+		return wordSets[m.wordSet].words
+	}
+	return commonWords
+}
+
+// loadWordsFile reads a user-supplied word list. Words may be separated by
+// newlines or any other whitespace. An empty file is treated as an error so
+// the caller can fall back to the built-in list.
+func loadWordsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(string(data))
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s contains no words", path)
+	}
+	return words, nil
+}
+
+// maxTextWords caps the text read by --stdin. Nobody types a whole book in
+// one test, and wrapping it on every render would get slow.
+const maxTextWords = 1000
+
+// readTextWords reads text to type in order (from --stdin), flattening all
+// whitespace to single spaces. truncated is set when it was cut down to
+// maxTextWords.
+func readTextWords(r io.Reader) (words []string, truncated bool, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, err
+	}
+	words = strings.Fields(string(data))
+	if len(words) == 0 {
+		return nil, false, fmt.Errorf("no text on standard input")
+	}
+	if len(words) > maxTextWords {
+		words = words[:maxTextWords]
+		truncated = true
+	}
+	return words, truncated, nil
+}
+
+// Quote length buckets, by word count.
+const (
+	shortQuoteMax  = 10
+	mediumQuoteMax = 16
+)
+
+// quoteFitsLength reports whether a quote belongs to the given length bucket.
+func quoteFitsLength(quote string, length quoteLength) bool {
+	n := len(strings.Fields(quote))
+	switch length {
+	case quoteShort:
+		return n <= shortQuoteMax
+	case quoteMedium:
+		return n > shortQuoteMax && n <= mediumQuoteMax
+	case quoteLong:
+		return n > mediumQuoteMax
+	}
+	return true
+}
+
+// pickQuote returns one random quote from pool in the length bucket.
+func pickQuote(rng *rand.Rand, pool []quote, length quoteLength) quote {
+	var matching []quote
+	for _, q := range pool {
+		if quoteFitsLength(q.text, length) {
+			matching = append(matching, q)
+		}
+	}
+	if len(matching) == 0 {
+		matching = pool
+	}
+	return matching[rng.Intn(len(matching))]
+}
+
+// getQuoteWords picks random quotes from pool and splits them into words,
 // concatenating until we have at least `minWords` words.
-func getQuoteWords(minWords int) []string {
+func getQuoteWords(rng *rand.Rand, pool []quote, minWords int) []string {
 	var words []string
 	for len(words) < minWords {
-		quote := quotes[rand.Intn(len(quotes))]
-		words = append(words, strings.Fields(quote)...)
+		q := pool[rng.Intn(len(pool))]
+		words = append(words, strings.Fields(q.text)...)
 	}
 	return words
 }
+
+// addPunctuation turns a list of plain words into sentence-like text:
+// sentences of 6–10 words that start with a capital letter and end in a
+// period (occasionally ? or !), with the odd comma, quoted word, or
+// parenthesised word in between.
+func addPunctuation(rng *rand.Rand, words []string) []string {
+	out := make([]string, len(words))
+	sentenceLeft := 0
+	for i, w := range words {
+		if sentenceLeft == 0 {
+			w = capitalize(w)
+			sentenceLeft = 6 + rng.Intn(5)
+		}
+		sentenceLeft--
+
+		switch {
+		case sentenceLeft == 0 || i == len(words)-1:
+			switch r := rng.Intn(10); {
+			case r == 0:
+				w += "?"
+			case r == 1:
+				w += "!"
+			default:
+				w += "."
+			}
+		case rng.Intn(8) == 0:
+			w += ","
+		case rng.Intn(25) == 0:
+			w = `"` + w + `"`
+		case rng.Intn(25) == 0:
+			w = "(" + w + ")"
+		}
+		out[i] = w
+	}
+	return out
+}
+
+func capitalize(w string) string {
+	r := []rune(w)
+	if len(r) == 0 {
+		return w
+	}
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
+
+// randomNumber returns a 1–5 digit number, occasionally with a decimal point
+// somewhere in the middle (e.g. "3.14").
+func randomNumber(rng *rand.Rand) string {
+	digits := 1 + rng.Intn(5)
+	var b strings.Builder
+	b.WriteByte(byte('1' + rng.Intn(9))) // no leading zero
+	for i := 1; i < digits; i++ {
+		b.WriteByte(byte('0' + rng.Intn(10)))
+	}
+	n := b.String()
+	if digits >= 2 && rng.Intn(6) == 0 {
+		split := 1 + rng.Intn(digits-1)
+		n = n[:split] + "." + n[split:]
+	}
+	return n
+}
+
+// generateNumbers returns count random numeric tokens for numbers mode.
+func generateNumbers(rng *rand.Rand, count int) []string {
+	nums := make([]string, count)
+	for i := range nums {
+		nums[i] = randomNumber(rng)
+	}
+	return nums
+}
+
+// Code snippets for code mode. Each is typed as written except for layout:
+// codeTokens flattens indentation and newlines into single spaces, so
+// tokens like `:=`, `fmt.Println(`, and `});` stay intact as "words".
+var codeSnippets = []string{
+	// Go
+	`func main() {
+	name := "world"
+	fmt.Println("hello,", name)
+}`,
+	`for i, v := range items {
+	if v == nil {
+		continue
+	}
+	total += v.Size()
+}`,
+	`if err != nil {
+	return fmt.Errorf("open %s: %w", path, err)
+}
+defer f.Close()`,
+	`type point struct {
+	x, y int
+}
+
+func (p point) add(q point) point {
+	return point{p.x + q.x, p.y + q.y}
+}`,
+	`ch := make(chan int, 10)
+go func() {
+	defer close(ch)
+	ch <- 42
+}()`,
+	// Python
+	`def greet(name):
+    return f"hello, {name}!"
+
+print(greet("world"))`,
+	`squares = [x * x for x in range(10) if x % 2 == 0]
+print(sum(squares))`,
+	`with open("data.txt") as f:
+    for line in f:
+        counts[line.strip()] += 1`,
+	`class Stack:
+    def __init__(self):
+        self.items = []
+
+    def push(self, item):
+        self.items.append(item)`,
+	// JSON
+	`{
+  "name": "cli_typer",
+  "version": "1.0.0",
+  "private": true
+}`,
+	`{
+  "users": [
+    { "id": 1, "admin": false },
+    { "id": 2, "admin": true }
+  ]
+}`,
+}
+
+// codeTokens splits a snippet into typeable tokens on any whitespace.
+func codeTokens(snippet string) []string {
+	return strings.Fields(snippet)
+}
+
+// generateCode returns count tokens from random snippets, each snippet
+// kept whole and in order so the text still reads as code.
+func generateCode(rng *rand.Rand, count int) []string {
+	var tokens []string
+	for len(tokens) < count {
+		snippet := codeSnippets[rng.Intn(len(codeSnippets))]
+		tokens = append(tokens, codeTokens(snippet)...)
+	}
+	return tokens[:count]
+}
+
+// codeTokenPool returns every distinct code token at most maxWidth columns
+// wide, for falling mode, where an alien has to fit on screen.
+func codeTokenPool(maxWidth int) []string {
+	seen := make(map[string]bool)
+	var pool []string
+	for _, snippet := range codeSnippets {
+		for _, t := range codeTokens(snippet) {
+			if !seen[t] && lipgloss.Width(t) <= maxWidth {
+				seen[t] = true
+				pool = append(pool, t)
+			}
+		}
+	}
+	return pool
+}
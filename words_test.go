@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+const wordSamples = 200000
+
+func TestPickWordsNeverRepeatsAdjacent(t *testing.T) {
+	set := wordSets[0]
+	tests := []struct {
+		name    string
+		words   []string
+		weights []int
+		recent  int
+	}{
+		{"even", set.words, nil, 1},
+		{"weighted", set.words, set.weights, 1},
+		{"varied", set.words, nil, varietyWindow},
+		{"two words", []string{"a", "b"}, nil, 1},
+		{"heavy favourite", []string{"a", "b", "c"}, []int{1000, 1, 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			words := pickWords(rng, tt.words, tt.weights, tt.recent, wordSamples)
+			if len(words) != wordSamples {
+				t.Fatalf("got %d words, want %d", len(words), wordSamples)
+			}
+			for i, w := range words {
+				if slices.Contains(words[max(i-tt.recent, 0):i], w) {
+					t.Fatalf("word %d, %q, came back within %d words", i, w, tt.recent)
+				}
+			}
+		})
+	}
+}
+
+func TestPickWordsFollowsWeights(t *testing.T) {
+	set := wordSets[0]
+	rng := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for _, w := range pickWords(rng, set.words, set.weights, 1, wordSamples) {
+		counts[w]++
+	}
+	first, last := set.words[0], set.words[len(set.words)-1]
+	for w, n := range counts {
+		if n > counts[first] {
+			t.Errorf("%q came up %d times, more than the most common word %q (%d)", w, n, first, counts[first])
+		}
+	}
+	// The weights make the first word about 21 times as likely as the last
+	want := float64(set.weights[0]) / float64(set.weights[len(set.weights)-1])
+	got := float64(counts[first]) / float64(counts[last])
+	if got < want*0.75 || got > want*1.25 {
+		t.Errorf("%q came up %.1f times as often as %q, want about %.1f", first, got, last, want)
+	}
+}
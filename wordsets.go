@@ -0,0 +1,82 @@
+package main
+
+// Word sets for tests of random words, picked on the menu's "set" row:
+//
+//   200 — commonWords, simple everyday words (see words.go)
+//   1k  — the thousand most common English words
+//   5k  — five thousand, on into longer and less familiar words
+//
+// The larger sets are embedded from wordsets/, one word per line, most
+// common first, so word variety can weigh them like commonWords (see
+// variety.go). A bigger set is a harder test, so results made with 1k or
+// 5k are tagged with it in history and only ranked against each other.
+// Falling mode uses the set too, leaving out words too wide for the
+// terminal.
+//
+// The daily challenge, lessons, and practice always use the 200 set, so
+// everyone's daily words are the same.
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed wordsets/english_1k.txt
+var english1k string
+
+//go:embed wordsets/english_5k.txt
+var english5k string
+
+// wordSet is a list of words to draw tests from. name is how history and
+// the config file know it; label is its menu choice.
+type wordSet struct {
+	name, label string
+	words       []string
+	weights     []int // by how common each word is, for natural variety
+}
+
+var wordSets = []wordSet{
+	newWordSet("english", "200", commonWords),
+	newWordSet("english_1k", "1k", strings.Fields(english1k)),
+	newWordSet("english_5k", "5k", strings.Fields(english5k)),
+}
+
+func newWordSet(name, label string, words []string) wordSet {
+	return wordSet{name: name, label: label, words: words, weights: rankWeights(len(words))}
+}
+
+// wordSetNames are the sets' names, by index.
+var wordSetNames = func() []string {
+	names := make([]string, len(wordSets))
+	for i, s := range wordSets {
+		names[i] = s.name
+	}
+	return names
+}()
+
+// wordSetTag is the set recorded in history for the current setup: the
+// set's name for words content, or "" for the 200 set, the only one older
+// results were typed with.
+func wordSetTag(m model) string {
+	if m.contentMode != modeWords || m.wordSet == 0 {
+		return ""
+	}
+	return wordSets[m.wordSet].name
+}
+
+// wordSetLabel names a history tag for the results screen, like "english 5k".
+func wordSetLabel(tag string) string {
+	return strings.ReplaceAll(tag, "_", " ")
+}
+
+// fittingWords returns the words in pool at most maxWidth columns wide,
+// for falling mode, where an alien has to fit on screen.
+func fittingWords(pool []string, maxWidth int) []string {
+	var fitting []string
+	for _, w := range pool {
+		if len(w) <= maxWidth {
+			fitting = append(fitting, w)
+		}
+	}
+	return fitting
+}
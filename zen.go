@@ -0,0 +1,47 @@
+package main
+
+// Zen tests: the third choice on the menu's test row. There's no timer and
+// no set length — the status bar counts up, more words are generated as
+// you near the end of the ones already there, and the test only ends when
+// you press enter (or esc, then enter on the pause screen). Results use
+// the real elapsed time, pauses excluded.
+//
+// Terminals can't tell ctrl+enter from enter, and some send ctrl+j for it,
+// so both finish the test.
+
+import tea "github.com/charmbracelet/bubbletea"
+
+const (
+	zenChunk     = 100 // words generated at a time
+	zenLookahead = 50  // more are generated once fewer than this are left
+)
+
+// zenTest reports whether the current classic test is a zen test. Quote
+// tests are always one quote, whatever the test row says.
+func zenTest(m model) bool {
+	return m.testMode == testModeZen && m.contentMode != modeQuotes
+}
+
+// extendZenWords generates the next chunk of words once the test gets
+// close to the end of the ones it has.
+func extendZenWords(m model) model {
+	if !zenTest(m) || len(m.words)-m.wordIndex >= zenLookahead {
+		return m
+	}
+	more := generateTestWords(m, zenChunk)
+	m.words = append(m.words, more...)
+	m.input = append(m.input, make([][]rune, len(more))...)
+	return m
+}
+
+// finishZen ends a zen test on request, once something has been typed.
+func finishZen(m model) (model, tea.Cmd) {
+	if len(m.keyLog) == 0 {
+		return m, nil
+	}
+	if m.paused {
+		// Shift startTime past the pause so the elapsed time leaves it out
+		m, _ = resumeTyping(m)
+	}
+	return finishTyping(m)
+}